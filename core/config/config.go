@@ -17,9 +17,16 @@ type ServerConfig struct {
 	Port       uint16 `json:"port"`
 }
 
+// The weather service is disabled if no API key is set
+type WeatherConfig struct {
+	Url    string `json:"url"` // Base url of an OpenWeatherMap-compatible API, uses OpenWeatherMap if empty
+	ApiKey string `json:"apiKey"`
+}
+
 type Config struct {
 	Server   ServerConfig            `json:"server"`
 	Database database.DatabaseConfig `json:"database"`
+	Weather  WeatherConfig           `json:"weather"`
 }
 
 var config Config
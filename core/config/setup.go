@@ -7,11 +7,13 @@ import (
 	"io/ioutil"
 
 	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/hardware"
 )
 
 type Setup struct {
-	HardwareNodes []database.HardwareNode `json:"hardwareNodes"`
-	Rooms         []database.Room         `json:"rooms"`
+	HardwareNodes  []database.HardwareNode  `json:"hardwareNodes"`
+	Rooms          []database.Room          `json:"rooms"`
+	NetworkDevices []database.NetworkDevice `json:"networkDevices"`
 }
 
 // Is again a variable for testing
@@ -59,6 +61,10 @@ func RunSetup() error {
 		log.Error("Aborting setup: could not create hardware node entries in database: ", err.Error())
 		return err
 	}
+	if err := createNetworkDevicesInDatabase(setup.NetworkDevices); err != nil {
+		log.Error("Aborting setup: could not create network device entries in database: ", err.Error())
+		return err
+	}
 	log.Info("Successfully ran setup")
 	return nil
 }
@@ -71,7 +77,16 @@ func createRoomsInDatabase(rooms []database.Room) error {
 			return err
 		}
 		for _, switchItem := range room.Switches {
-			if err := database.CreateSwitch(switchItem.Id, switchItem.Name, room.Data.Id, switchItem.Watts); err != nil {
+			// Override the (possible) empty room-id to match the current room
+			switchItem.RoomId = room.Data.Id
+			if switchItem.Category == "" {
+				switchItem.Category = database.SwitchCategoryOther
+			}
+			if !database.IsValidSwitchCategory(switchItem.Category) {
+				log.Error(fmt.Sprintf("Could not create switches from setup file: invalid category '%s' of switch '%s'", switchItem.Category, switchItem.Id))
+				return fmt.Errorf("invalid category '%s' of switch '%s'", switchItem.Category, switchItem.Id)
+			}
+			if err := database.CreateSwitch(switchItem); err != nil {
 				log.Error("Could not create switches from setup file: ", err.Error())
 				return err
 			}
@@ -104,3 +119,23 @@ func createHardwareNodesInDatabase(nodes []database.HardwareNode) error {
 	}
 	return nil
 }
+
+// Takes the specified `networkDevices` and creates according database entries
+// The referenced switches must be created beforehand
+func createNetworkDevicesInDatabase(devices []database.NetworkDevice) error {
+	for _, device := range devices {
+		if device.StatusMode == "" {
+			device.StatusMode = database.NetworkStatusNone
+		}
+		// The setup file must not bypass the checks which are enforced by the API
+		if err := hardware.ValidateNetworkDevice(device); err != nil {
+			log.Error(fmt.Sprintf("Could not create network device '%s' from setup file: %s", device.SwitchId, err.Error()))
+			return err
+		}
+		if err := database.SetNetworkDevice(device); err != nil {
+			log.Error("Could not create network devices from setup file: ", err.Error())
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,30 @@ const (
 	TimingSunset  TimingMode = "sunset"  // Same as above, just for sunset
 )
 
+// Determines which elevation of the sun is used by the timing modes `sunrise` and `sunset`
+// For sunrise, the twilight variants describe the beginning of dawn, for sunset the end of dusk
+type Twilight string
+
+const (
+	TwilightNone         Twilight = ""             // The upper edge of the sun touches the horizon
+	TwilightCivil        Twilight = "civil"        // The center of the sun is 6° below the horizon
+	TwilightNautical     Twilight = "nautical"     // The center of the sun is 12° below the horizon
+	TwilightAstronomical Twilight = "astronomical" // The center of the sun is 18° below the horizon
+)
+
+// Determines what causes an automation to run
+type TriggerType string
+
+const (
+	TriggerCron    TriggerType = "cron"    // Runs at the times described by the cron expression
+	TriggerWeather TriggerType = "weather" // Runs once the weather condition becomes true, the cron expression is not used
+	// The following triggers react to events of the event bus, the cron expression is not used
+	TriggerSwitch   TriggerType = "switch"   // Runs if the trigger switch changes its power state
+	TriggerNode     TriggerType = "node"     // Runs if the trigger hardware node goes online or offline
+	TriggerLogin    TriggerType = "login"    // Runs if the trigger user logs in
+	TriggerReminder TriggerType = "reminder" // Runs if a reminder of the owner becomes overdue
+)
+
 type Automation struct {
 	Id             uint       `json:"id"`
 	Name           string     `json:"name"`
@@ -21,6 +45,20 @@ type Automation struct {
 	Owner          string     `json:"owner"`
 	Enabled        bool       `json:"enabled"`
 	TimingMode     TimingMode `json:"timingMode"`
+	// If set, the automation runs this revision of its Homescript instead of the current code
+	HomescriptRevision *uint       `json:"homescriptRevision"`
+	Trigger            TriggerType `json:"trigger"`
+	// For weather triggers, the condition which causes the automation to run, for example `windSpeed > 12`
+	// For cron triggers, an optional weather condition which must be met, otherwise the run is skipped
+	Condition  string  `json:"condition"`
+	Hysteresis float64 `json:"hysteresis"` // Margin by which a met numeric condition must be undercut before it can trigger again
+	// For event triggers, the item which is observed, for example the id of a switch
+	// An empty subject matches every item, except for switch and node triggers which require a subject
+	TriggerSubject string `json:"triggerSubject"`
+	TriggerState   string `json:"triggerState"` // For switch and node triggers, the required new state, empty matches every state
+	// Are only used by the timing modes `sunrise` and `sunset`
+	Twilight  Twilight `json:"twilight"`
+	SunOffset int      `json:"sunOffset"` // In minutes, negative values run the automation before the sun event
 }
 
 type AutomationWithoutIdAndUsername struct {
@@ -30,6 +68,20 @@ type AutomationWithoutIdAndUsername struct {
 	HomescriptId   string     `json:"homescriptId"`
 	Enabled        bool       `json:"enabled"`
 	TimingMode     TimingMode `json:"timingMode"`
+	// If set, the automation runs this revision of its Homescript instead of the current code
+	HomescriptRevision *uint       `json:"homescriptRevision"`
+	Trigger            TriggerType `json:"trigger"`
+	// For weather triggers, the condition which causes the automation to run, for example `windSpeed > 12`
+	// For cron triggers, an optional weather condition which must be met, otherwise the run is skipped
+	Condition  string  `json:"condition"`
+	Hysteresis float64 `json:"hysteresis"` // Margin by which a met numeric condition must be undercut before it can trigger again
+	// For event triggers, the item which is observed, for example the id of a switch
+	// An empty subject matches every item, except for switch and node triggers which require a subject
+	TriggerSubject string `json:"triggerSubject"`
+	TriggerState   string `json:"triggerState"` // For switch and node triggers, the required new state, empty matches every state
+	// Are only used by the timing modes `sunrise` and `sunset`
+	Twilight  Twilight `json:"twilight"`
+	SunOffset int      `json:"sunOffset"` // In minutes, negative values run the automation before the sun event
 }
 
 // Creates a new table containing the automation jobs
@@ -46,6 +98,14 @@ func createAutomationTable() error {
 		Owner VARCHAR(20),
 		Enabled BOOL,
 		TimingMode ENUM('normal', 'sunrise', 'sunset'),
+		HomescriptRevision INT NULL,
+		TriggerType VARCHAR(20) DEFAULT 'cron',
+		TriggerCondition TEXT,
+		Hysteresis DOUBLE DEFAULT 0,
+		TriggerSubject VARCHAR(100) DEFAULT '',
+		TriggerState VARCHAR(20) DEFAULT '',
+		Twilight VARCHAR(20) DEFAULT '',
+		SunOffset INT DEFAULT 0,
 		PRIMARY KEY(Id),
 		FOREIGN KEY (HomescriptId)
 		REFERENCES homescript(Id),
@@ -57,6 +117,35 @@ func createAutomationTable() error {
 		log.Error("Failed to create automation table: executing query failed: ", err.Error())
 		return err
 	}
+	// Older databases were created without the `HomescriptRevision` column
+	if _, err := db.Exec(`
+	ALTER TABLE automation
+	ADD COLUMN IF NOT EXISTS HomescriptRevision INT NULL
+	`); err != nil {
+		log.Error("Failed to migrate automation table: adding column `HomescriptRevision` failed: ", err.Error())
+		return err
+	}
+	// Older databases were created without the trigger columns
+	if _, err := db.Exec(`
+	ALTER TABLE automation
+	ADD COLUMN IF NOT EXISTS TriggerType VARCHAR(20) DEFAULT 'cron',
+	ADD COLUMN IF NOT EXISTS TriggerCondition TEXT,
+	ADD COLUMN IF NOT EXISTS Hysteresis DOUBLE DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS TriggerSubject VARCHAR(100) DEFAULT '',
+	ADD COLUMN IF NOT EXISTS TriggerState VARCHAR(20) DEFAULT ''
+	`); err != nil {
+		log.Error("Failed to migrate automation table: adding trigger columns failed: ", err.Error())
+		return err
+	}
+	// Older databases were created without the sun timing columns
+	if _, err := db.Exec(`
+	ALTER TABLE automation
+	ADD COLUMN IF NOT EXISTS Twilight VARCHAR(20) DEFAULT '',
+	ADD COLUMN IF NOT EXISTS SunOffset INT DEFAULT 0
+	`); err != nil {
+		log.Error("Failed to migrate automation table: adding sun timing columns failed: ", err.Error())
+		return err
+	}
 	return nil
 }
 
@@ -65,9 +154,9 @@ func CreateNewAutomation(automation Automation) (uint, error) {
 	query, err := db.Prepare(`
 	INSERT INTO
 	automation(
-		Id, Name, Description, CronExpression, HomescriptId, Owner, Enabled, TimingMode
+		Id, Name, Description, CronExpression, HomescriptId, Owner, Enabled, TimingMode, HomescriptRevision, TriggerType, TriggerCondition, Hysteresis, TriggerSubject, TriggerState, Twilight, SunOffset
 	)	
-	VALUES(DEFAULT, ?, ?, ?, ?, ?, ?, ?)
+	VALUES(DEFAULT, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		log.Error("Failed to create new automation: preparing query failed: ", err.Error())
@@ -82,6 +171,14 @@ func CreateNewAutomation(automation Automation) (uint, error) {
 		automation.Owner,
 		automation.Enabled,
 		automation.TimingMode,
+		automation.HomescriptRevision,
+		automation.Trigger,
+		automation.Condition,
+		automation.Hysteresis,
+		automation.TriggerSubject,
+		automation.TriggerState,
+		automation.Twilight,
+		automation.SunOffset,
 	)
 	if err != nil {
 		log.Error("Failed to create new automation: executing query failed: ", err.Error())
@@ -100,7 +197,7 @@ func CreateNewAutomation(automation Automation) (uint, error) {
 func GetAutomationById(id uint) (Automation, bool, error) {
 	query, err := db.Prepare(`
 	SELECT
-	Id, Name, Description, CronExpression, HomescriptId, Owner, Enabled, TimingMode
+	Id, Name, Description, CronExpression, HomescriptId, Owner, Enabled, TimingMode, HomescriptRevision, TriggerType, TriggerCondition, Hysteresis, TriggerSubject, TriggerState, Twilight, SunOffset
 	FROM automation
 	WHERE Id=?
 	`)
@@ -119,6 +216,14 @@ func GetAutomationById(id uint) (Automation, bool, error) {
 		&automation.Owner,
 		&automation.Enabled,
 		&automation.TimingMode,
+		&automation.HomescriptRevision,
+		&automation.Trigger,
+		&automation.Condition,
+		&automation.Hysteresis,
+		&automation.TriggerSubject,
+		&automation.TriggerState,
+		&automation.Twilight,
+		&automation.SunOffset,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -134,7 +239,7 @@ func GetAutomationById(id uint) (Automation, bool, error) {
 func GetUserAutomations(username string) ([]Automation, error) {
 	query, err := db.Prepare(`
 	SELECT
-	Id, Name, Description, CronExpression, HomescriptId, Owner, Enabled, TimingMode
+	Id, Name, Description, CronExpression, HomescriptId, Owner, Enabled, TimingMode, HomescriptRevision, TriggerType, TriggerCondition, Hysteresis, TriggerSubject, TriggerState, Twilight, SunOffset
 	FROM automation
 	WHERE Owner=?
 	`)
@@ -161,6 +266,14 @@ func GetUserAutomations(username string) ([]Automation, error) {
 			&automation.Owner,
 			&automation.Enabled,
 			&automation.TimingMode,
+			&automation.HomescriptRevision,
+			&automation.Trigger,
+			&automation.Condition,
+			&automation.Hysteresis,
+			&automation.TriggerSubject,
+			&automation.TriggerState,
+			&automation.Twilight,
+			&automation.SunOffset,
 		); err != nil {
 			log.Error("Failed to list user automations: scanning for results failed: ", err.Error())
 			return nil, err
@@ -175,7 +288,7 @@ func GetUserAutomations(username string) ([]Automation, error) {
 func GetAutomations() ([]Automation, error) {
 	res, err := db.Query(`
 	SELECT
-	Id, Name, Description, CronExpression, HomescriptId, Owner, Enabled, TimingMode
+	Id, Name, Description, CronExpression, HomescriptId, Owner, Enabled, TimingMode, HomescriptRevision, TriggerType, TriggerCondition, Hysteresis, TriggerSubject, TriggerState, Twilight, SunOffset
 	FROM automation
 	`)
 	if err != nil {
@@ -195,6 +308,14 @@ func GetAutomations() ([]Automation, error) {
 			&automation.Owner,
 			&automation.Enabled,
 			&automation.TimingMode,
+			&automation.HomescriptRevision,
+			&automation.Trigger,
+			&automation.Condition,
+			&automation.Hysteresis,
+			&automation.TriggerSubject,
+			&automation.TriggerState,
+			&automation.Twilight,
+			&automation.SunOffset,
 		); err != nil {
 			log.Error("Failed to list all automations: scanning for results failed: ", err.Error())
 			return nil, err
@@ -215,7 +336,15 @@ func ModifyAutomation(id uint, newItem AutomationWithoutIdAndUsername) error {
 	CronExpression=?,
 	HomescriptId=?,
 	Enabled=?,
-	TimingMode=?
+	TimingMode=?,
+	HomescriptRevision=?,
+	TriggerType=?,
+	TriggerCondition=?,
+	Hysteresis=?,
+	TriggerSubject=?,
+	TriggerState=?,
+	Twilight=?,
+	SunOffset=?
 	WHERE Id=?
 	`)
 	if err != nil {
@@ -230,6 +359,14 @@ func ModifyAutomation(id uint, newItem AutomationWithoutIdAndUsername) error {
 		newItem.HomescriptId,
 		newItem.Enabled,
 		newItem.TimingMode,
+		newItem.HomescriptRevision,
+		newItem.Trigger,
+		newItem.Condition,
+		newItem.Hysteresis,
+		newItem.TriggerSubject,
+		newItem.TriggerState,
+		newItem.Twilight,
+		newItem.SunOffset,
 		id,
 	)
 	if err != nil {
@@ -243,6 +380,9 @@ func ModifyAutomation(id uint, newItem AutomationWithoutIdAndUsername) error {
 // Deletes an automation item given its Id
 // Does not validate the validity of the provided Id
 func DeleteAutomationById(id uint) error {
+	if err := DeleteAutomationRuns(id); err != nil {
+		return err
+	}
 	query, err := db.Prepare(`
 	DELETE FROM
 	automation
@@ -262,6 +402,9 @@ func DeleteAutomationById(id uint) error {
 
 // Deletes all automations from a given user
 func DeleteAllAutomationsFromUser(username string) error {
+	if err := DeleteAllAutomationRunsFromUser(username); err != nil {
+		return err
+	}
 	query, err := db.Prepare(`
 	DELETE FROM
 	automation
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// The result of a single execution of an automation
+type AutomationRun struct {
+	Id           uint      `json:"id"`
+	AutomationId uint      `json:"automationId"`
+	Manual       bool      `json:"manual"` // Is true if the run was started by a user instead of the automation's trigger
+	StartedAt    time.Time `json:"startedAt"`
+	FinishedAt   time.Time `json:"finishedAt"`
+	Status       string    `json:"status"` // Either `succeeded`, `failed` or `skipped`
+	Error        string    `json:"error"`  // Describes why the run failed or was skipped
+}
+
+// Creates the table which contains the run history of automations
+// If the database fails, this function returns an error
+func createAutomationRunTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	automation_run(
+		Id INT AUTO_INCREMENT PRIMARY KEY,
+		AutomationId INT,
+		Manual BOOLEAN DEFAULT FALSE,
+		StartedAt DATETIME(3),
+		FinishedAt DATETIME(3),
+		Status VARCHAR(20),
+		Error TEXT,
+		INDEX (AutomationId)
+	)
+	`); err != nil {
+		log.Error("Failed to create automation run table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Adds a finished run to the history and returns its id
+func AddAutomationRun(run AutomationRun) (uint, error) {
+	query, err := db.Prepare(`
+	INSERT INTO
+	automation_run(
+		AutomationId,
+		Manual,
+		StartedAt,
+		FinishedAt,
+		Status,
+		Error
+	)
+	VALUES(?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		log.Error("Failed to add automation run: preparing query failed: ", err.Error())
+		return 0, err
+	}
+	defer query.Close()
+	res, err := query.Exec(
+		run.AutomationId,
+		run.Manual,
+		run.StartedAt,
+		run.FinishedAt,
+		run.Status,
+		run.Error,
+	)
+	if err != nil {
+		log.Error("Failed to add automation run: executing query failed: ", err.Error())
+		return 0, err
+	}
+	newId, err := res.LastInsertId()
+	if err != nil {
+		log.Error("Failed to add automation run: retrieving last insert id failed: ", err.Error())
+		return 0, err
+	}
+	return uint(newId), nil
+}
+
+// Returns the newest runs of an automation, newest first
+func ListAutomationRuns(automationId uint, limit uint) ([]AutomationRun, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, AutomationId, Manual, StartedAt, FinishedAt, Status, Error
+	FROM automation_run
+	WHERE AutomationId=?
+	ORDER BY Id DESC
+	LIMIT ?
+	`)
+	if err != nil {
+		log.Error("Failed to list automation runs: preparing query failed: ", err.Error())
+		return nil, err
+	}
+	defer query.Close()
+	res, err := query.Query(automationId, limit)
+	if err != nil {
+		log.Error("Failed to list automation runs: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	runs := make([]AutomationRun, 0)
+	for res.Next() {
+		var run AutomationRun
+		if err := res.Scan(
+			&run.Id,
+			&run.AutomationId,
+			&run.Manual,
+			&run.StartedAt,
+			&run.FinishedAt,
+			&run.Status,
+			&run.Error,
+		); err != nil {
+			log.Error("Failed to list automation runs: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// Returns the newest run of an automation and whether the automation has run before
+func GetLastAutomationRun(automationId uint) (AutomationRun, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, AutomationId, Manual, StartedAt, FinishedAt, Status, Error
+	FROM automation_run
+	WHERE AutomationId=?
+	ORDER BY Id DESC
+	LIMIT 1
+	`)
+	if err != nil {
+		log.Error("Failed to get last automation run: preparing query failed: ", err.Error())
+		return AutomationRun{}, false, err
+	}
+	defer query.Close()
+	var run AutomationRun
+	if err := query.QueryRow(automationId).Scan(
+		&run.Id,
+		&run.AutomationId,
+		&run.Manual,
+		&run.StartedAt,
+		&run.FinishedAt,
+		&run.Status,
+		&run.Error,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return AutomationRun{}, false, nil
+		}
+		log.Error("Failed to get last automation run: executing query failed: ", err.Error())
+		return AutomationRun{}, false, err
+	}
+	return run, true, nil
+}
+
+// Only keeps the newest `keep` runs of an automation
+func PruneAutomationRuns(automationId uint, keep uint) error {
+	query, err := db.Prepare(`
+	DELETE FROM automation_run
+	WHERE AutomationId=?
+	AND Id <= (
+		SELECT Id FROM (
+			SELECT Id FROM automation_run
+			WHERE AutomationId=?
+			ORDER BY Id DESC
+			LIMIT 1 OFFSET ?
+		) AS oldestDiscarded
+	)
+	`)
+	if err != nil {
+		log.Error("Failed to prune automation runs: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(automationId, automationId, keep); err != nil {
+		log.Error("Failed to prune automation runs: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes the run history of an automation
+func DeleteAutomationRuns(automationId uint) error {
+	query, err := db.Prepare(`
+	DELETE FROM automation_run
+	WHERE AutomationId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete automation runs: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(automationId); err != nil {
+		log.Error("Failed to delete automation runs: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes the run history of every automation of a given user
+func DeleteAllAutomationRunsFromUser(username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM automation_run
+	WHERE AutomationId IN (
+		SELECT Id FROM automation
+		WHERE Owner=?
+	)
+	`)
+	if err != nil {
+		log.Error("Failed to delete automation runs of user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username); err != nil {
+		log.Error("Failed to delete automation runs of user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
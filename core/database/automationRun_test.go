@@ -0,0 +1,74 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreateAutomationRunTable(t *testing.T) {
+	if err := createAutomationRunTable(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
+
+// Tests the creation, listing and retention of automation runs
+func TestAutomationRuns(t *testing.T) {
+	automationId := uint(4242)
+	if _, found, err := GetLastAutomationRun(automationId); err != nil || found {
+		t.Errorf("Automation without runs returned a last run: found: %t err: %v", found, err)
+		return
+	}
+	var lastId uint
+	for index := 0; index < 5; index++ {
+		id, err := AddAutomationRun(AutomationRun{
+			AutomationId: automationId,
+			Manual:       index == 4,
+			StartedAt:    time.Now(),
+			FinishedAt:   time.Now(),
+			Status:       "failed",
+			Error:        "test",
+		})
+		if err != nil {
+			t.Error(err.Error())
+			return
+		}
+		lastId = id
+	}
+	run, found, err := GetLastAutomationRun(automationId)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || run.Id != lastId || !run.Manual || run.Status != "failed" || run.Error != "test" {
+		t.Errorf("Last run does not match its input: got: %v", run)
+		return
+	}
+	// Only the newest runs are kept
+	if err := PruneAutomationRuns(automationId, 3); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	runs, err := ListAutomationRuns(automationId, 10)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(runs) != 3 || runs[0].Id != lastId {
+		t.Errorf("Unexpected runs after pruning: want: 3 runs starting with %d got: %v", lastId, runs)
+		return
+	}
+	if err := DeleteAutomationRuns(automationId); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	runs, err = ListAutomationRuns(automationId, 10)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(runs) != 0 {
+		t.Errorf("Runs still exist after deletion: got: %v", runs)
+		return
+	}
+}
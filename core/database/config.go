@@ -9,6 +9,7 @@ type ServerConfig struct {
 	LockDownMode      bool    `json:"lockDownMode"`      // If enabled, the server is unable to change power states and will not allow power actions
 	Latitude          float32 `json:"latitude"`          // Used for calculating the sunset / sunrise and for openweathermap
 	Longitude         float32 `json:"longitude"`
+	HomescriptTimeout uint    `json:"homescriptTimeout"` // Maximum runtime of a Homescript in seconds, 0 disables the limit
 }
 
 // Creates the table that contains the server configuration
@@ -21,12 +22,21 @@ func createConfigTable() error {
 		AutomationEnabled BOOLEAN DEFAULT TRUE,
 		LockDownMode BOOLEAN DEFAULT FALSE,
 		Latitude FLOAT(32) DEFAULT 0.0,
-		Longitude FLOAT(32) DeFAULT 0.0
+		Longitude FLOAT(32) DeFAULT 0.0,
+		HomescriptTimeout INT UNSIGNED DEFAULT 300
 	)`)
 	if err != nil {
 		log.Error("Failed to create server configuration table: executing query failed: ", err.Error())
 		return err
 	}
+	// Older databases were created without the `HomescriptTimeout` column
+	if _, err := db.Exec(`
+	ALTER TABLE configuration
+	ADD COLUMN IF NOT EXISTS HomescriptTimeout INT UNSIGNED DEFAULT 300
+	`); err != nil {
+		log.Error("Failed to migrate server configuration table: adding column `HomescriptTimeout` failed: ", err.Error())
+		return err
+	}
 	_, found, err := GetServerConfiguration()
 	if err != nil {
 		log.Error("Failed to create server configuration table: probing for present configuration failed: ", err.Error())
@@ -51,7 +61,7 @@ func GetServerConfiguration() (ServerConfig, bool, error) {
 	var config ServerConfig
 	err := db.QueryRow(`
 	SELECT
-	AutomationEnabled, LockDownMode, Latitude, Longitude
+	AutomationEnabled, LockDownMode, Latitude, Longitude, HomescriptTimeout
 	FROM configuration
 	WHERE Id=0
 	`).Scan(
@@ -59,6 +69,7 @@ func GetServerConfiguration() (ServerConfig, bool, error) {
 		&config.LockDownMode,
 		&config.Latitude,
 		&config.Longitude,
+		&config.HomescriptTimeout,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -79,7 +90,8 @@ func SetServerConfiguration(config ServerConfig) error {
 	AutomationEnabled=?,
 	LockDownMode=?,
 	Latitude=?,
-	Longitude=?
+	Longitude=?,
+	HomescriptTimeout=?
 	WHERE Id=0
 	`)
 	if err != nil {
@@ -91,6 +103,7 @@ func SetServerConfiguration(config ServerConfig) error {
 		config.LockDownMode,
 		config.Latitude,
 		config.Longitude,
+		config.HomescriptTimeout,
 	); err != nil {
 		log.Error("Failed to update the servers configuration: executing query failed: ", err.Error())
 		return err
@@ -136,3 +149,22 @@ func UpdateLocation(lat float32, lon float32) error {
 	}
 	return nil
 }
+
+// Changes the maximum runtime of Homescripts, 0 disables the limit
+func UpdateHomescriptTimeout(seconds uint) error {
+	query, err := db.Prepare(`
+	UPDATE configuration
+	SET
+	HomescriptTimeout=?
+	WHERE Id=0
+	`)
+	if err != nil {
+		log.Error("Failed to update the Homescript timeout: preparing query failed: ", err.Error())
+		return err
+	}
+	if _, err := query.Exec(seconds); err != nil {
+		log.Error("Failed to update the Homescript timeout: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
@@ -22,7 +22,7 @@ func TestCreateGetConfig(t *testing.T) {
 		t.Error("Configuration does not exists after creation")
 		return
 	}
-	if !config.AutomationEnabled || config.LockDownMode || config.Latitude != 0.0 || config.Longitude != 0.0 {
+	if !config.AutomationEnabled || config.LockDownMode || config.Latitude != 0.0 || config.Longitude != 0.0 || config.HomescriptTimeout != 300 {
 		t.Errorf("Invalid configuration after creation: got: %v", config)
 		return
 	}
@@ -34,6 +34,7 @@ func TestSetConfig(t *testing.T) {
 		LockDownMode:      true,
 		Latitude:          42.42,
 		Longitude:         42.42,
+		HomescriptTimeout: 60,
 	}
 	if err := SetServerConfiguration(configNew); err != nil {
 		t.Error(err.Error())
@@ -52,7 +53,8 @@ func TestSetConfig(t *testing.T) {
 	if config.AutomationEnabled != configNew.AutomationEnabled ||
 		config.LockDownMode != configNew.LockDownMode ||
 		config.Latitude != configNew.Latitude ||
-		config.Longitude != configNew.Longitude {
+		config.Longitude != configNew.Longitude ||
+		config.HomescriptTimeout != configNew.HomescriptTimeout {
 		t.Errorf("Configuration was not modified: want: %v got: %v", configNew, config)
 		return
 	}
@@ -111,3 +113,23 @@ func TestUpdateLocation(t *testing.T) {
 		return
 	}
 }
+
+func TestUpdateHomescriptTimeout(t *testing.T) {
+	if err := UpdateHomescriptTimeout(42); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	configAfter, exists, err := GetServerConfiguration()
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !exists {
+		t.Error("Configuration does not exists after modification")
+		return
+	}
+	if configAfter.HomescriptTimeout != 42 {
+		t.Errorf("Homescript timeout did not change: want: 42 got: %d", configAfter.HomescriptTimeout)
+		return
+	}
+}
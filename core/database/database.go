@@ -48,9 +48,24 @@ func DeleteTables() error {
 		"DROP TABLE IF EXISTS camera",
 		"DROP TABLE IF EXISTS rooms",
 		"DROP TABLE IF EXISTS hasSwitchPermission",
+		"DROP TABLE IF EXISTS powerTimer",
+		"DROP TABLE IF EXISTS networkDevice",
+		"DROP TABLE IF EXISTS energyUsage",
+		"DROP TABLE IF EXISTS energyBudget",
 		"DROP TABLE IF EXISTS switch",
 		"DROP TABLE IF EXISTS schedule",
+		"DROP TABLE IF EXISTS automation_run",
 		"DROP TABLE IF EXISTS automation",
+		"DROP TABLE IF EXISTS homescript_run",
+		"DROP TABLE IF EXISTS webhook",
+		"DROP TABLE IF EXISTS homescriptRevision",
+		"DROP TABLE IF EXISTS homescriptShare",
+		"DROP TABLE IF EXISTS userGroupMember",
+		"DROP TABLE IF EXISTS userGroup",
+		"DROP TABLE IF EXISTS homescriptStorage",
+		"DROP TABLE IF EXISTS homescriptHttpHost",
+		"DROP TABLE IF EXISTS hasRadiGoPermission",
+		"DROP TABLE IF EXISTS radiGoServer",
 		"DROP TABLE IF EXISTS homescript",
 		"DROP TABLE IF EXISTS notifications",
 		"DROP TABLE IF EXISTS hasPermission",
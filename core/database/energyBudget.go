@@ -0,0 +1,270 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Specifies whether a budget applies to a single switch or to all switches of a room
+type EnergyBudgetTarget string
+
+const (
+	EnergyBudgetSwitch EnergyBudgetTarget = "switch"
+	EnergyBudgetRoom   EnergyBudgetTarget = "room"
+)
+
+// Specifies the period after which the usage of a budget is reset
+type EnergyBudgetPeriod string
+
+const (
+	EnergyBudgetDaily   EnergyBudgetPeriod = "daily"
+	EnergyBudgetMonthly EnergyBudgetPeriod = "monthly"
+)
+
+// Limits the energy a switch or a room may use during a period
+type EnergyBudget struct {
+	Id             uint               `json:"id"`
+	Owner          string             `json:"owner"` // Receives the notifications of this budget
+	TargetType     EnergyBudgetTarget `json:"targetType"`
+	TargetId       string             `json:"targetId"`
+	Period         EnergyBudgetPeriod `json:"period"`
+	LimitWattHours uint               `json:"limitWattHours"`
+	CutPower       bool               `json:"cutPower"` // If set, the switches of the target are turned off once the budget is exceeded
+	// The highest alert threshold (in percent) the owner has been notified about in the current period
+	AlertLevel       uint      `json:"alertLevel"`
+	AlertPeriodStart time.Time `json:"alertPeriodStart"` // The start of the period to which the `AlertLevel` belongs
+}
+
+// Creates the table containing energy budgets
+// Budgets reference their target without a foreign key because the target can either be a switch or a room
+func createEnergyBudgetTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	energyBudget(
+		Id INT AUTO_INCREMENT,
+		Owner VARCHAR(20),
+		TargetType VARCHAR(6),
+		TargetId VARCHAR(30),
+		Period VARCHAR(7),
+		LimitWattHours INT UNSIGNED,
+		CutPower BOOLEAN DEFAULT FALSE,
+		AlertLevel INT UNSIGNED DEFAULT 0,
+		AlertPeriodStart DATETIME,
+		PRIMARY KEY (Id),
+		FOREIGN KEY (Owner)
+		REFERENCES user(Username)
+	)
+	`); err != nil {
+		log.Error("Failed to create energy budget table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Creates a new energy budget, does not check the validity of the target
+func CreateEnergyBudget(budget EnergyBudget) (uint, error) {
+	query, err := db.Prepare(`
+	INSERT INTO
+	energyBudget(
+		Id, Owner, TargetType, TargetId, Period, LimitWattHours, CutPower, AlertLevel, AlertPeriodStart
+	)
+	VALUES(DEFAULT, ?, ?, ?, ?, ?, ?, 0, ?)
+	`)
+	if err != nil {
+		log.Error("Failed to create energy budget: preparing query failed: ", err.Error())
+		return 0, err
+	}
+	defer query.Close()
+	res, err := query.Exec(
+		budget.Owner,
+		budget.TargetType,
+		budget.TargetId,
+		budget.Period,
+		budget.LimitWattHours,
+		budget.CutPower,
+		budget.AlertPeriodStart,
+	)
+	if err != nil {
+		log.Error("Failed to create energy budget: executing query failed: ", err.Error())
+		return 0, err
+	}
+	newId, err := res.LastInsertId()
+	if err != nil {
+		log.Error("Failed to create energy budget: retrieving last inserted id failed: ", err.Error())
+		return 0, err
+	}
+	return uint(newId), nil
+}
+
+// Changes the limit, period and power cut behaviour of a budget
+// The alert level is reset so that the owner is notified according to the new limit
+func ModifyEnergyBudget(id uint, period EnergyBudgetPeriod, limitWattHours uint, cutPower bool) error {
+	query, err := db.Prepare(`
+	UPDATE energyBudget
+	SET
+	Period=?,
+	LimitWattHours=?,
+	CutPower=?,
+	AlertLevel=0
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to modify energy budget: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(period, limitWattHours, cutPower, id); err != nil {
+		log.Error("Failed to modify energy budget: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Saves the alert level which the owner of a budget has been notified about
+func SetEnergyBudgetAlert(id uint, alertLevel uint, periodStart time.Time) error {
+	query, err := db.Prepare(`
+	UPDATE energyBudget
+	SET
+	AlertLevel=?,
+	AlertPeriodStart=?
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to set energy budget alert: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(alertLevel, periodStart, id); err != nil {
+		log.Error("Failed to set energy budget alert: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns a list of all energy budgets
+func GetEnergyBudgets() ([]EnergyBudget, error) {
+	res, err := db.Query(`
+	SELECT
+	Id, Owner, TargetType, TargetId, Period, LimitWattHours, CutPower, AlertLevel, AlertPeriodStart
+	FROM energyBudget
+	`)
+	if err != nil {
+		log.Error("Failed to list energy budgets: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	budgets := make([]EnergyBudget, 0)
+	for res.Next() {
+		var budget EnergyBudget
+		if err := res.Scan(
+			&budget.Id,
+			&budget.Owner,
+			&budget.TargetType,
+			&budget.TargetId,
+			&budget.Period,
+			&budget.LimitWattHours,
+			&budget.CutPower,
+			&budget.AlertLevel,
+			&budget.AlertPeriodStart,
+		); err != nil {
+			log.Error("Failed to list energy budgets: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		budgets = append(budgets, budget)
+	}
+	return budgets, nil
+}
+
+// Returns an energy budget given its id
+// If the id does not match a budget, a `false` is returned
+func GetEnergyBudgetById(id uint) (EnergyBudget, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, Owner, TargetType, TargetId, Period, LimitWattHours, CutPower, AlertLevel, AlertPeriodStart
+	FROM energyBudget
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to get energy budget by id: preparing query failed: ", err.Error())
+		return EnergyBudget{}, false, err
+	}
+	defer query.Close()
+	var budget EnergyBudget
+	if err := query.QueryRow(id).Scan(
+		&budget.Id,
+		&budget.Owner,
+		&budget.TargetType,
+		&budget.TargetId,
+		&budget.Period,
+		&budget.LimitWattHours,
+		&budget.CutPower,
+		&budget.AlertLevel,
+		&budget.AlertPeriodStart,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return EnergyBudget{}, false, nil
+		}
+		log.Error("Failed to get energy budget by id: executing query failed: ", err.Error())
+		return EnergyBudget{}, false, err
+	}
+	return budget, true, nil
+}
+
+// Deletes an energy budget given its id
+func DeleteEnergyBudgetById(id uint) error {
+	query, err := db.Prepare(`
+	DELETE FROM
+	energyBudget
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete energy budget: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(id); err != nil {
+		log.Error("Failed to delete energy budget: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all budgets of a given switch or room, used when deleting the target
+func DeleteEnergyBudgetsOfTarget(targetType EnergyBudgetTarget, targetId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM
+	energyBudget
+	WHERE TargetType=?
+	AND TargetId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete energy budgets of target: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(targetType, targetId); err != nil {
+		log.Error("Failed to delete energy budgets of target: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all budgets owned by a given user, used when deleting a user
+func DeleteAllEnergyBudgetsFromUser(username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM
+	energyBudget
+	WHERE Owner=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete energy budgets of user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username); err != nil {
+		log.Error("Failed to delete energy budgets of user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+// Creates the energy tables in order to check if the sql queries work
+func TestCreateEnergyTables(t *testing.T) {
+	if err := createEnergyUsageTable(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := createEnergyBudgetTable(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
+
+// Tests the accumulation of the energy usage of switches and rooms
+func TestEnergyUsage(t *testing.T) {
+	if err := CreateRoom(RoomData{Id: "energy_room", Name: "", Description: ""}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	for _, switchId := range []string{"energy_s1", "energy_s2"} {
+		if err := CreateSwitch(Switch{Id: switchId, RoomId: "energy_room", Watts: 100}); err != nil {
+			t.Error(err.Error())
+			return
+		}
+	}
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	table := []struct {
+		SwitchId  string
+		Day       time.Time
+		WattHours float64
+	}{
+		{SwitchId: "energy_s1", Day: now, WattHours: 10},
+		{SwitchId: "energy_s1", Day: now, WattHours: 5},
+		{SwitchId: "energy_s1", Day: yesterday, WattHours: 100},
+		{SwitchId: "energy_s2", Day: now, WattHours: 20},
+	}
+	for _, test := range table {
+		if err := AddSwitchEnergyUsage(test.SwitchId, test.Day, test.WattHours); err != nil {
+			t.Error(err.Error())
+			return
+		}
+	}
+	switchUsage, err := GetSwitchEnergyUsage("energy_s1", now)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if switchUsage != 15 {
+		t.Errorf("Unexpected switch usage. Want: 15 Got: %f", switchUsage)
+		return
+	}
+	roomUsage, err := GetRoomEnergyUsage("energy_room", yesterday)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if roomUsage != 135 {
+		t.Errorf("Unexpected room usage. Want: 135 Got: %f", roomUsage)
+		return
+	}
+}
+
+// Tests the creation, modification and deletion of energy budgets
+func TestEnergyBudgets(t *testing.T) {
+	if err := CreateRoom(RoomData{Id: "budget_room", Name: "", Description: ""}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := CreateSwitch(Switch{Id: "budget_heater", RoomId: "budget_room", Watts: 2000}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	id, err := CreateEnergyBudget(EnergyBudget{
+		Owner:            "admin",
+		TargetType:       EnergyBudgetSwitch,
+		TargetId:         "budget_heater",
+		Period:           EnergyBudgetDaily,
+		LimitWattHours:   4000,
+		CutPower:         true,
+		AlertPeriodStart: time.Now(),
+	})
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := ModifyEnergyBudget(id, EnergyBudgetMonthly, 50000, false); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := SetEnergyBudgetAlert(id, 80, time.Now()); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	budget, found, err := GetEnergyBudgetById(id)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found {
+		t.Errorf("Energy budget '%d' does not exist after creation", id)
+		return
+	}
+	if budget.Period != EnergyBudgetMonthly || budget.LimitWattHours != 50000 || budget.CutPower || budget.AlertLevel != 80 {
+		t.Errorf("Energy budget was not modified correctly: %v", budget)
+		return
+	}
+	// Deleting the switch must also delete its budgets
+	if err := DeleteSwitch("budget_heater"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if _, found, err := GetEnergyBudgetById(id); err != nil || found {
+		t.Errorf("Energy budget '%d' still exists after its switch was deleted", id)
+		return
+	}
+}
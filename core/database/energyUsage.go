@@ -0,0 +1,120 @@
+package database
+
+import (
+	"time"
+)
+
+// Creates the table containing the daily energy usage of each switch
+// The usage is estimated using the `Watts` of a switch and the time it was turned on
+func createEnergyUsageTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	energyUsage(
+		SwitchId VARCHAR(20),
+		Day DATE,
+		WattHours DOUBLE DEFAULT 0,
+		PRIMARY KEY (SwitchId, Day),
+		FOREIGN KEY (SwitchId)
+		REFERENCES switch(Id)
+	)
+	`); err != nil {
+		log.Error("Failed to create energy usage table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns the day of the given time in the server's timezone as `YYYY-MM-DD`
+// The day is determined here because the driver sends times in UTC, so `DATE()` would use the wrong day around midnight
+func formatDay(day time.Time) string {
+	return day.Local().Format("2006-01-02")
+}
+
+// Adds the given amount of watt hours to the usage of a switch on the given day
+func AddSwitchEnergyUsage(switchId string, day time.Time, wattHours float64) error {
+	query, err := db.Prepare(`
+	INSERT INTO
+	energyUsage(
+		SwitchId, Day, WattHours
+	)
+	VALUES(?, ?, ?)
+	ON DUPLICATE KEY
+		UPDATE
+		WattHours=WattHours+VALUES(WattHours)
+	`)
+	if err != nil {
+		log.Error("Failed to add energy usage: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(switchId, formatDay(day), wattHours); err != nil {
+		log.Error("Failed to add energy usage: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns the watt hours a switch has used since the given day (inclusive)
+func GetSwitchEnergyUsage(switchId string, since time.Time) (float64, error) {
+	query, err := db.Prepare(`
+	SELECT
+	COALESCE(SUM(WattHours), 0)
+	FROM energyUsage
+	WHERE SwitchId=?
+	AND Day >= ?
+	`)
+	if err != nil {
+		log.Error("Failed to get energy usage of switch: preparing query failed: ", err.Error())
+		return 0, err
+	}
+	defer query.Close()
+	var wattHours float64
+	if err := query.QueryRow(switchId, formatDay(since)).Scan(&wattHours); err != nil {
+		log.Error("Failed to get energy usage of switch: executing query failed: ", err.Error())
+		return 0, err
+	}
+	return wattHours, nil
+}
+
+// Returns the watt hours all switches of a room have used since the given day (inclusive)
+func GetRoomEnergyUsage(roomId string, since time.Time) (float64, error) {
+	query, err := db.Prepare(`
+	SELECT
+	COALESCE(SUM(energyUsage.WattHours), 0)
+	FROM energyUsage
+	JOIN switch ON energyUsage.SwitchId=switch.Id
+	WHERE switch.RoomId=?
+	AND energyUsage.Day >= ?
+	`)
+	if err != nil {
+		log.Error("Failed to get energy usage of room: preparing query failed: ", err.Error())
+		return 0, err
+	}
+	defer query.Close()
+	var wattHours float64
+	if err := query.QueryRow(roomId, formatDay(since)).Scan(&wattHours); err != nil {
+		log.Error("Failed to get energy usage of room: executing query failed: ", err.Error())
+		return 0, err
+	}
+	return wattHours, nil
+}
+
+// Deletes the recorded energy usage of a switch, used when deleting a switch
+func DeleteSwitchEnergyUsage(switchId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM
+	energyUsage
+	WHERE SwitchId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete energy usage of switch: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(switchId); err != nil {
+		log.Error("Failed to delete energy usage of switch: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
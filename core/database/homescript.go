@@ -1,5 +1,7 @@
 package database
 
+import "database/sql"
+
 type Homescript struct {
 	Id                  string `json:"id"`
 	Owner               string `json:"owner"`
@@ -8,6 +10,9 @@ type Homescript struct {
 	QuickActionsEnabled bool   `json:"quickActionsEnabled"`
 	SchedulerEnabled    bool   `json:"schedulerEnabled"`
 	Code                string `json:"code"`
+	RunRetention        uint   `json:"runRetention"`     // How many past runs of this script are kept in the run history, 0 uses the default
+	QuickActionIcon     string `json:"quickActionIcon"`  // Optional icon which is shown on the quick actions dashboard
+	QuickActionColor    string `json:"quickActionColor"` // Optional hex color which is shown on the quick actions dashboard
 }
 
 type HomescriptFrontend struct {
@@ -16,6 +21,9 @@ type HomescriptFrontend struct {
 	QuickActionsEnabled bool   `json:"quickActionsEnabled"`
 	SchedulerEnabled    bool   `json:"schedulerEnabled"`
 	Code                string `json:"code"`
+	RunRetention        uint   `json:"runRetention"`     // How many past runs of this script are kept in the run history, 0 uses the default
+	QuickActionIcon     string `json:"quickActionIcon"`  // Optional icon which is shown on the quick actions dashboard
+	QuickActionColor    string `json:"quickActionColor"` // Optional hex color which is shown on the quick actions dashboard
 }
 
 // Creates the table containing Homescript code and metadata
@@ -32,6 +40,9 @@ func createHomescriptTable() error {
 		QuickActionsEnabled BOOLEAN,
 		SchedulerEnabled BOOLEAN,
 		Code TEXT,
+		RunRetention INT UNSIGNED DEFAULT 50,
+		QuickActionIcon VARCHAR(50) DEFAULT '',
+		QuickActionColor VARCHAR(20) DEFAULT '',
 		CONSTRAINT HomescriptOwner
 		FOREIGN KEY (Owner)
 		REFERENCES user(Username)
@@ -42,6 +53,16 @@ func createHomescriptTable() error {
 		log.Error("Failed to create Homescript Table: Executing query failed: ", err.Error())
 		return err
 	}
+	// Older databases were created without the `RunRetention` and quick action columns
+	if _, err := db.Exec(`
+	ALTER TABLE homescript
+	ADD COLUMN IF NOT EXISTS RunRetention INT UNSIGNED DEFAULT 50,
+	ADD COLUMN IF NOT EXISTS QuickActionIcon VARCHAR(50) DEFAULT '',
+	ADD COLUMN IF NOT EXISTS QuickActionColor VARCHAR(20) DEFAULT ''
+	`); err != nil {
+		log.Error("Failed to migrate Homescript Table: adding columns failed: ", err.Error())
+		return err
+	}
 	return nil
 }
 
@@ -56,9 +77,12 @@ func CreateNewHomescript(homescript Homescript) error {
 		Description,
 		QuickActionsEnabled,
 		SchedulerEnabled,
-		Code
+		Code,
+		RunRetention,
+		QuickActionIcon,
+		QuickActionColor
 	)
-	VALUES(?, ?, ?, ?, ?, ?, ?)
+	VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		log.Error("Failed to create new homescript entry: preparing query failed: ", err.Error())
@@ -73,6 +97,9 @@ func CreateNewHomescript(homescript Homescript) error {
 		homescript.QuickActionsEnabled,
 		homescript.SchedulerEnabled,
 		homescript.Code,
+		homescript.RunRetention,
+		homescript.QuickActionIcon,
+		homescript.QuickActionColor,
 	); err != nil {
 		log.Error("Failed to create new homescript entry: executing query failed: ", err.Error())
 		return err
@@ -90,7 +117,10 @@ func ModifyHomescriptById(id string, homescript HomescriptFrontend) error {
 	Description=?,
 	QuickActionsEnabled=?,
 	SchedulerEnabled=?,
-	Code=?
+	Code=?,
+	RunRetention=?,
+	QuickActionIcon=?,
+	QuickActionColor=?
 	WHERE Id=?
 	`)
 	if err != nil {
@@ -104,6 +134,9 @@ func ModifyHomescriptById(id string, homescript HomescriptFrontend) error {
 		homescript.QuickActionsEnabled,
 		homescript.SchedulerEnabled,
 		homescript.Code,
+		homescript.RunRetention,
+		homescript.QuickActionIcon,
+		homescript.QuickActionColor,
 		id,
 	)
 	if err != nil {
@@ -117,7 +150,7 @@ func ModifyHomescriptById(id string, homescript HomescriptFrontend) error {
 func ListHomescriptOfUser(username string) ([]Homescript, error) {
 	query, err := db.Prepare(`
 	SELECT
-	Id, Owner, Name, Description, QuickActionsEnabled, SchedulerEnabled, Code
+	Id, Owner, Name, Description, QuickActionsEnabled, SchedulerEnabled, Code, RunRetention, QuickActionIcon, QuickActionColor
 	FROM homescript
 	WHERE Owner=?
 	`)
@@ -143,6 +176,9 @@ func ListHomescriptOfUser(username string) ([]Homescript, error) {
 			&homescript.QuickActionsEnabled,
 			&homescript.SchedulerEnabled,
 			&homescript.Code,
+			&homescript.RunRetention,
+			&homescript.QuickActionIcon,
+			&homescript.QuickActionColor,
 		)
 		if err != nil {
 			log.Error("Failed to list homescript of user: scanning results failed: ", err.Error())
@@ -157,7 +193,7 @@ func ListHomescriptOfUser(username string) ([]Homescript, error) {
 func ListHomescriptFiles() ([]Homescript, error) {
 	query, err := db.Prepare(`
 	SELECT
-	Id, Owner, Name, Description, QuickActionsEnabled, SchedulerEnabled, Code
+	Id, Owner, Name, Description, QuickActionsEnabled, SchedulerEnabled, Code, RunRetention, QuickActionIcon, QuickActionColor
 	FROM homescript
 	`)
 	if err != nil {
@@ -182,6 +218,9 @@ func ListHomescriptFiles() ([]Homescript, error) {
 			&homescript.QuickActionsEnabled,
 			&homescript.SchedulerEnabled,
 			&homescript.Code,
+			&homescript.RunRetention,
+			&homescript.QuickActionIcon,
+			&homescript.QuickActionColor,
 		)
 		if err != nil {
 			log.Error("Failed to list homescript files: scanning results failed: ", err.Error())
@@ -192,6 +231,42 @@ func ListHomescriptFiles() ([]Homescript, error) {
 	return homescriptList, nil
 }
 
+// Returns a Homescript given its id, does not check if a user has access to the Homescript
+// Returns Homescript, has been found, error
+func GetHomescriptById(homescriptId string) (Homescript, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, Owner, Name, Description, QuickActionsEnabled, SchedulerEnabled, Code, RunRetention, QuickActionIcon, QuickActionColor
+	FROM homescript
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to get Homescript by id: preparing query failed: ", err.Error())
+		return Homescript{}, false, err
+	}
+	defer query.Close()
+	var homescript Homescript
+	if err := query.QueryRow(homescriptId).Scan(
+		&homescript.Id,
+		&homescript.Owner,
+		&homescript.Name,
+		&homescript.Description,
+		&homescript.QuickActionsEnabled,
+		&homescript.SchedulerEnabled,
+		&homescript.Code,
+		&homescript.RunRetention,
+		&homescript.QuickActionIcon,
+		&homescript.QuickActionColor,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return Homescript{}, false, nil
+		}
+		log.Error("Failed to get Homescript by id: executing query failed: ", err.Error())
+		return Homescript{}, false, err
+	}
+	return homescript, true, nil
+}
+
 // Returns a Homescript given its id
 // Returns Homescript, has been found, error
 // TODO: replace with query row
@@ -227,6 +302,18 @@ func DoesHomescriptExist(homescriptId string) (bool, error) {
 
 // Deletes a homescript by its Id, does not check if the user has access to the homescript
 func DeleteHomescriptById(homescriptId string) error {
+	if err := DeleteHomescriptRuns(homescriptId); err != nil {
+		return err
+	}
+	if err := DeleteHomescriptWebhooks(homescriptId); err != nil {
+		return err
+	}
+	if err := DeleteHomescriptRevisions(homescriptId); err != nil {
+		return err
+	}
+	if err := DeleteHomescriptShares(homescriptId); err != nil {
+		return err
+	}
 	query, err := db.Prepare(`
 	DELETE FROM
 	homescript
@@ -246,6 +333,15 @@ func DeleteHomescriptById(homescriptId string) error {
 
 // Deletes all Homescripts of a given user
 func DeleteAllHomescriptsOfUser(username string) error {
+	if err := DeleteAllWebhooksFromUser(username); err != nil {
+		return err
+	}
+	if err := DeleteAllHomescriptRevisionsOfUser(username); err != nil {
+		return err
+	}
+	if err := DeleteAllHomescriptSharesOfUser(username); err != nil {
+		return err
+	}
 	query, err := db.Prepare(`
 	DELETE FROM
 	homescript
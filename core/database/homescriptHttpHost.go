@@ -0,0 +1,120 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// A host which Homescripts are allowed to send HTTP requests to
+type HomescriptHttpHost struct {
+	Host        string `json:"host"` // Hostname or IP address without scheme and port, for example `nas.local`
+	Description string `json:"description"`
+}
+
+// Creates the table containing the hosts which Homescripts may send HTTP requests to
+// If the database fails, this function returns an error
+func createHomescriptHttpHostTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	homescriptHttpHost(
+		Host VARCHAR(255) PRIMARY KEY,
+		Description TEXT
+	)
+	`); err != nil {
+		log.Error("Failed to create Homescript HTTP host table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns all allowed hosts sorted by their name
+func ListHomescriptHttpHosts() ([]HomescriptHttpHost, error) {
+	res, err := db.Query(`
+	SELECT
+	Host, Description
+	FROM homescriptHttpHost
+	ORDER BY Host
+	`)
+	if err != nil {
+		log.Error("Failed to list Homescript HTTP hosts: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	hosts := make([]HomescriptHttpHost, 0)
+	for res.Next() {
+		var host HomescriptHttpHost
+		if err := res.Scan(&host.Host, &host.Description); err != nil {
+			log.Error("Failed to list Homescript HTTP hosts: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// Returns whether Homescripts are allowed to send HTTP requests to the given host
+// Hosts are compared case-insensitively
+func IsHomescriptHttpHostAllowed(host string) (bool, error) {
+	query, err := db.Prepare(`
+	SELECT Host
+	FROM homescriptHttpHost
+	WHERE Host=?
+	`)
+	if err != nil {
+		log.Error("Failed to check Homescript HTTP host: preparing query failed: ", err.Error())
+		return false, err
+	}
+	defer query.Close()
+	var found string
+	if err := query.QueryRow(strings.ToLower(host)).Scan(&found); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		log.Error("Failed to check Homescript HTTP host: executing query failed: ", err.Error())
+		return false, err
+	}
+	return true, nil
+}
+
+// Adds a host to the allowlist or updates its description if it already exists
+func AddHomescriptHttpHost(host HomescriptHttpHost) error {
+	query, err := db.Prepare(`
+	INSERT INTO
+	homescriptHttpHost(
+		Host,
+		Description
+	)
+	VALUES(?, ?)
+	ON DUPLICATE KEY UPDATE
+	Description=VALUES(Description)
+	`)
+	if err != nil {
+		log.Error("Failed to add Homescript HTTP host: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(strings.ToLower(host.Host), host.Description); err != nil {
+		log.Error("Failed to add Homescript HTTP host: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Removes a host from the allowlist, does nothing if the host is not allowed
+func DeleteHomescriptHttpHost(host string) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescriptHttpHost
+	WHERE Host=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete Homescript HTTP host: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(strings.ToLower(host)); err != nil {
+		log.Error("Failed to delete Homescript HTTP host: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
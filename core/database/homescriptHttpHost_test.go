@@ -0,0 +1,48 @@
+package database
+
+import "testing"
+
+func TestHomescriptHttpHosts(t *testing.T) {
+	if err := AddHomescriptHttpHost(HomescriptHttpHost{Host: "NAS.local", Description: "test"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	// Hosts are compared case-insensitively
+	allowed, err := IsHomescriptHttpHostAllowed("nas.LOCAL")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !allowed {
+		t.Error("Host is not allowed after it has been added")
+		return
+	}
+	hosts, err := ListHomescriptHttpHosts()
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	valid := false
+	for _, host := range hosts {
+		if host.Host == "nas.local" && host.Description == "test" {
+			valid = true
+		}
+	}
+	if !valid {
+		t.Errorf("Added host is not listed: %v", hosts)
+		return
+	}
+	if err := DeleteHomescriptHttpHost("nas.local"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	allowed, err = IsHomescriptHttpHostAllowed("nas.local")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if allowed {
+		t.Error("Host is still allowed after it has been deleted")
+		return
+	}
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// A saved version of the code of a Homescript
+// A new revision is stored each time the code of a Homescript is saved
+type HomescriptRevision struct {
+	Id           uint      `json:"id"`
+	HomescriptId string    `json:"homescriptId"`
+	Author       string    `json:"author"`
+	CreatedAt    time.Time `json:"createdAt"`
+	Code         string    `json:"code"`
+}
+
+// Creates the table which contains the revision history of Homescripts
+// The author is not a foreign key so that the history is kept after an author has been deleted
+// If the database fails, this function returns an error
+func createHomescriptRevisionTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	homescriptRevision(
+		Id INT AUTO_INCREMENT PRIMARY KEY,
+		HomescriptId VARCHAR(30),
+		Author VARCHAR(20),
+		CreatedAt DATETIME(3),
+		Code TEXT,
+		CONSTRAINT HomescriptRevisionHomescript
+		FOREIGN KEY (HomescriptId)
+		REFERENCES homescript(Id)
+	)
+	`); err != nil {
+		log.Error("Failed to create Homescript revision table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Adds a new revision and returns its id
+func AddHomescriptRevision(revision HomescriptRevision) (uint, error) {
+	query, err := db.Prepare(`
+	INSERT INTO
+	homescriptRevision(
+		HomescriptId,
+		Author,
+		CreatedAt,
+		Code
+	)
+	VALUES(?, ?, ?, ?)
+	`)
+	if err != nil {
+		log.Error("Failed to add Homescript revision: preparing query failed: ", err.Error())
+		return 0, err
+	}
+	defer query.Close()
+	res, err := query.Exec(
+		revision.HomescriptId,
+		revision.Author,
+		revision.CreatedAt,
+		revision.Code,
+	)
+	if err != nil {
+		log.Error("Failed to add Homescript revision: executing query failed: ", err.Error())
+		return 0, err
+	}
+	newId, err := res.LastInsertId()
+	if err != nil {
+		log.Error("Failed to add Homescript revision: retrieving last insert id failed: ", err.Error())
+		return 0, err
+	}
+	return uint(newId), nil
+}
+
+// Returns a revision of a given Homescript
+// If the revision belongs to another Homescript, it is treated as not found
+func GetHomescriptRevision(homescriptId string, revisionId uint) (HomescriptRevision, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, HomescriptId, Author, CreatedAt, Code
+	FROM homescriptRevision
+	WHERE Id=?
+	AND HomescriptId=?
+	`)
+	if err != nil {
+		log.Error("Failed to get Homescript revision: preparing query failed: ", err.Error())
+		return HomescriptRevision{}, false, err
+	}
+	defer query.Close()
+	var revision HomescriptRevision
+	if err := query.QueryRow(revisionId, homescriptId).Scan(
+		&revision.Id,
+		&revision.HomescriptId,
+		&revision.Author,
+		&revision.CreatedAt,
+		&revision.Code,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return HomescriptRevision{}, false, nil
+		}
+		log.Error("Failed to get Homescript revision: executing query failed: ", err.Error())
+		return HomescriptRevision{}, false, err
+	}
+	return revision, true, nil
+}
+
+// Returns the newest revision of a given Homescript which has been written by a given author
+func GetNewestHomescriptRevisionOfAuthor(homescriptId string, author string) (HomescriptRevision, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, HomescriptId, Author, CreatedAt, Code
+	FROM homescriptRevision
+	WHERE HomescriptId=?
+	AND Author=?
+	ORDER BY Id DESC
+	LIMIT 1
+	`)
+	if err != nil {
+		log.Error("Failed to get newest Homescript revision of author: preparing query failed: ", err.Error())
+		return HomescriptRevision{}, false, err
+	}
+	defer query.Close()
+	var revision HomescriptRevision
+	if err := query.QueryRow(homescriptId, author).Scan(
+		&revision.Id,
+		&revision.HomescriptId,
+		&revision.Author,
+		&revision.CreatedAt,
+		&revision.Code,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return HomescriptRevision{}, false, nil
+		}
+		log.Error("Failed to get newest Homescript revision of author: executing query failed: ", err.Error())
+		return HomescriptRevision{}, false, err
+	}
+	return revision, true, nil
+}
+
+// Returns all revisions of a given Homescript, newest first
+func ListHomescriptRevisions(homescriptId string) ([]HomescriptRevision, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, HomescriptId, Author, CreatedAt, Code
+	FROM homescriptRevision
+	WHERE HomescriptId=?
+	ORDER BY Id DESC
+	`)
+	if err != nil {
+		log.Error("Failed to list Homescript revisions: preparing query failed: ", err.Error())
+		return nil, err
+	}
+	defer query.Close()
+	res, err := query.Query(homescriptId)
+	if err != nil {
+		log.Error("Failed to list Homescript revisions: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	revisions := make([]HomescriptRevision, 0)
+	for res.Next() {
+		var revision HomescriptRevision
+		if err := res.Scan(
+			&revision.Id,
+			&revision.HomescriptId,
+			&revision.Author,
+			&revision.CreatedAt,
+			&revision.Code,
+		); err != nil {
+			log.Error("Failed to list Homescript revisions: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+// Deletes the revision history of a given Homescript
+func DeleteHomescriptRevisions(homescriptId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescriptRevision
+	WHERE HomescriptId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete Homescript revisions: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(homescriptId); err != nil {
+		log.Error("Failed to delete Homescript revisions: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes the revision history of all Homescripts which are owned by a given user
+func DeleteAllHomescriptRevisionsOfUser(username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescriptRevision
+	WHERE HomescriptId IN (
+		SELECT Id FROM homescript
+		WHERE Owner=?
+	)
+	`)
+	if err != nil {
+		log.Error("Failed to delete Homescript revisions of user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username); err != nil {
+		log.Error("Failed to delete Homescript revisions of user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
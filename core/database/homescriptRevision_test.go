@@ -0,0 +1,115 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCreateHomescriptRevisionTable(t *testing.T) {
+	if err := createHomescriptRevisionTable(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
+
+// Tests the creation, listing and deletion of Homescript revisions
+func TestHomescriptRevisions(t *testing.T) {
+	for _, id := range []string{"revision_test", "revision_test_other"} {
+		if err := CreateNewHomescript(Homescript{
+			Id:    id,
+			Owner: "admin",
+		}); err != nil {
+			t.Error(err.Error())
+			return
+		}
+	}
+	firstId, err := AddHomescriptRevision(HomescriptRevision{
+		HomescriptId: "revision_test",
+		Author:       "admin",
+		CreatedAt:    time.Now(),
+		Code:         "print('a')",
+	})
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	secondId, err := AddHomescriptRevision(HomescriptRevision{
+		HomescriptId: "revision_test",
+		Author:       "admin",
+		CreatedAt:    time.Now(),
+		Code:         "print('b')",
+	})
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	revisions, err := ListHomescriptRevisions("revision_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(revisions) != 2 || revisions[0].Id != secondId || revisions[1].Id != firstId {
+		t.Errorf("Revisions are not listed newest first: want: [%d %d] got: %v", secondId, firstId, revisions)
+		return
+	}
+	revision, found, err := GetHomescriptRevision("revision_test", firstId)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || revision.Code != "print('a')" || revision.Author != "admin" {
+		t.Errorf("Revision does not match its input: got: %v", revision)
+		return
+	}
+	// Revisions of other authors are skipped
+	if _, err := AddHomescriptRevision(HomescriptRevision{
+		HomescriptId: "revision_test",
+		Author:       "editor",
+		CreatedAt:    time.Now(),
+		Code:         "print('c')",
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	revision, found, err = GetNewestHomescriptRevisionOfAuthor("revision_test", "admin")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || revision.Id != secondId {
+		t.Errorf("Unexpected newest revision of author: want: %d got: %v", secondId, revision)
+		return
+	}
+	if _, found, err = GetNewestHomescriptRevisionOfAuthor("revision_test_other", "admin"); err != nil || found {
+		t.Errorf("Homescript without revisions returned a revision: found: %t err: %v", found, err)
+		return
+	}
+	// A revision can only be retrieved using the Homescript it belongs to
+	_, found, err = GetHomescriptRevision("revision_test_other", firstId)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if found {
+		t.Errorf("Revision %d was found using a different Homescript", firstId)
+		return
+	}
+	// Deleting the Homescript also deletes its revisions
+	if err := DeleteHomescriptById("revision_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	revisions, err = ListHomescriptRevisions("revision_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(revisions) != 0 {
+		t.Errorf("Revisions still exist after their Homescript was deleted: got: %v", revisions)
+		return
+	}
+	if err := DeleteHomescriptById("revision_test_other"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
@@ -0,0 +1,341 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Is used for runs which do not belong to a saved Homescript, for example live runs or schedules
+// Also applies to saved Homescripts which do not specify their own retention
+const DefaultRunRetention = 50
+
+// A past or currently running execution of a Homescript
+type HomescriptRun struct {
+	Id           uint            `json:"id"`
+	HomescriptId *string         `json:"homescriptId"` // Is nil if the code was not loaded from a saved Homescript
+	ParentRunId  *uint           `json:"parentRunId"`  // Is set if the run was started by another script using `exec`
+	ScriptLabel  string          `json:"scriptLabel"`
+	Username     string          `json:"username"`
+	Trigger      string          `json:"trigger"`
+	StartedAt    time.Time       `json:"startedAt"`
+	FinishedAt   *time.Time      `json:"finishedAt"` // Is nil while the run is still in progress
+	ExitCode     int             `json:"exitCode"`
+	Output       string          `json:"output"`
+	Errors       json.RawMessage `json:"errors"` // JSON-encoded list of the errors which terminated the run
+}
+
+// Creates the table which contains the run history of Homescripts
+// If the database fails, this function returns an error
+func createHomescriptRunTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	homescript_run(
+		Id INT AUTO_INCREMENT PRIMARY KEY,
+		HomescriptId VARCHAR(30) NULL,
+		ParentRunId INT NULL,
+		ScriptLabel VARCHAR(100),
+		Username VARCHAR(20),
+		TriggerSource VARCHAR(20),
+		StartedAt DATETIME(3),
+		FinishedAt DATETIME(3) NULL,
+		ExitCode INT,
+		Output MEDIUMTEXT,
+		Errors MEDIUMTEXT,
+		INDEX (HomescriptId),
+		INDEX (Username),
+		CONSTRAINT HomescriptRunUsername
+		FOREIGN KEY (Username)
+		REFERENCES user(Username)
+	)
+	`); err != nil {
+		log.Error("Failed to create Homescript run table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Adds a run to the history and returns its id
+// Runs which are still in progress are added without `FinishedAt` and are completed using `FinishHomescriptRun`
+func AddHomescriptRun(run HomescriptRun) (uint, error) {
+	query, err := db.Prepare(`
+	INSERT INTO
+	homescript_run(
+		HomescriptId,
+		ParentRunId,
+		ScriptLabel,
+		Username,
+		TriggerSource,
+		StartedAt,
+		FinishedAt,
+		ExitCode,
+		Output,
+		Errors
+	)
+	VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		log.Error("Failed to add Homescript run: preparing query failed: ", err.Error())
+		return 0, err
+	}
+	defer query.Close()
+	errors := run.Errors
+	if errors == nil {
+		errors = json.RawMessage("[]")
+	}
+	res, err := query.Exec(
+		run.HomescriptId,
+		run.ParentRunId,
+		run.ScriptLabel,
+		run.Username,
+		run.Trigger,
+		run.StartedAt,
+		run.FinishedAt,
+		run.ExitCode,
+		run.Output,
+		string(errors),
+	)
+	if err != nil {
+		log.Error("Failed to add Homescript run: executing query failed: ", err.Error())
+		return 0, err
+	}
+	newId, err := res.LastInsertId()
+	if err != nil {
+		log.Error("Failed to add Homescript run: retrieving last insert id failed: ", err.Error())
+		return 0, err
+	}
+	return uint(newId), nil
+}
+
+// Stores the result of a run which has been added while it was still in progress
+func FinishHomescriptRun(id uint, finishedAt time.Time, exitCode int, output string, errors json.RawMessage) error {
+	query, err := db.Prepare(`
+	UPDATE homescript_run
+	SET
+	FinishedAt=?,
+	ExitCode=?,
+	Output=?,
+	Errors=?
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to finish Homescript run: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if errors == nil {
+		errors = json.RawMessage("[]")
+	}
+	if _, err := query.Exec(finishedAt, exitCode, output, string(errors), id); err != nil {
+		log.Error("Failed to finish Homescript run: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns a run given its id
+func GetHomescriptRunById(id uint) (HomescriptRun, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, HomescriptId, ParentRunId, ScriptLabel, Username, TriggerSource, StartedAt, FinishedAt, ExitCode, Output, Errors
+	FROM homescript_run
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to get Homescript run by id: preparing query failed: ", err.Error())
+		return HomescriptRun{}, false, err
+	}
+	defer query.Close()
+	var run HomescriptRun
+	var errors string
+	if err := query.QueryRow(id).Scan(
+		&run.Id,
+		&run.HomescriptId,
+		&run.ParentRunId,
+		&run.ScriptLabel,
+		&run.Username,
+		&run.Trigger,
+		&run.StartedAt,
+		&run.FinishedAt,
+		&run.ExitCode,
+		&run.Output,
+		&errors,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return HomescriptRun{}, false, nil
+		}
+		log.Error("Failed to get Homescript run by id: executing query failed: ", err.Error())
+		return HomescriptRun{}, false, err
+	}
+	run.Errors = json.RawMessage(errors)
+	return run, true, nil
+}
+
+// Returns the newest runs of a user, newest first
+// If `homescriptId` is not empty, only runs of the given Homescript are returned
+func ListUserHomescriptRuns(username string, homescriptId string, limit uint) ([]HomescriptRun, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, HomescriptId, ParentRunId, ScriptLabel, Username, TriggerSource, StartedAt, FinishedAt, ExitCode, Output, Errors
+	FROM homescript_run
+	WHERE Username=?
+	AND (?='' OR HomescriptId=?)
+	ORDER BY Id DESC
+	LIMIT ?
+	`)
+	if err != nil {
+		log.Error("Failed to list Homescript runs of user: preparing query failed: ", err.Error())
+		return nil, err
+	}
+	defer query.Close()
+	res, err := query.Query(username, homescriptId, homescriptId, limit)
+	if err != nil {
+		log.Error("Failed to list Homescript runs of user: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	return scanHomescriptRuns(res)
+}
+
+// Returns the newest runs of all users, newest first
+func ListAllHomescriptRuns(limit uint) ([]HomescriptRun, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, HomescriptId, ParentRunId, ScriptLabel, Username, TriggerSource, StartedAt, FinishedAt, ExitCode, Output, Errors
+	FROM homescript_run
+	ORDER BY Id DESC
+	LIMIT ?
+	`)
+	if err != nil {
+		log.Error("Failed to list all Homescript runs: preparing query failed: ", err.Error())
+		return nil, err
+	}
+	defer query.Close()
+	res, err := query.Query(limit)
+	if err != nil {
+		log.Error("Failed to list all Homescript runs: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	return scanHomescriptRuns(res)
+}
+
+func scanHomescriptRuns(res *sql.Rows) ([]HomescriptRun, error) {
+	runs := make([]HomescriptRun, 0)
+	for res.Next() {
+		var run HomescriptRun
+		var errors string
+		if err := res.Scan(
+			&run.Id,
+			&run.HomescriptId,
+			&run.ParentRunId,
+			&run.ScriptLabel,
+			&run.Username,
+			&run.Trigger,
+			&run.StartedAt,
+			&run.FinishedAt,
+			&run.ExitCode,
+			&run.Output,
+			&errors,
+		); err != nil {
+			log.Error("Failed to scan Homescript runs: ", err.Error())
+			return nil, err
+		}
+		run.Errors = json.RawMessage(errors)
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// Only keeps the newest `keep` runs of a saved Homescript
+func PruneHomescriptRuns(homescriptId string, keep uint) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescript_run
+	WHERE HomescriptId=?
+	AND Id <= (
+		SELECT Id FROM (
+			SELECT Id FROM homescript_run
+			WHERE HomescriptId=?
+			ORDER BY Id DESC
+			LIMIT 1 OFFSET ?
+		) AS oldestDiscarded
+	)
+	`)
+	if err != nil {
+		log.Error("Failed to prune Homescript runs: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(homescriptId, homescriptId, keep); err != nil {
+		log.Error("Failed to prune Homescript runs: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Only keeps the newest `keep` runs of a user which do not belong to a saved Homescript and share the same label
+func PruneInlineHomescriptRuns(username string, scriptLabel string, keep uint) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescript_run
+	WHERE HomescriptId IS NULL
+	AND Username=?
+	AND ScriptLabel=?
+	AND Id <= (
+		SELECT Id FROM (
+			SELECT Id FROM homescript_run
+			WHERE HomescriptId IS NULL
+			AND Username=?
+			AND ScriptLabel=?
+			ORDER BY Id DESC
+			LIMIT 1 OFFSET ?
+		) AS oldestDiscarded
+	)
+	`)
+	if err != nil {
+		log.Error("Failed to prune inline Homescript runs: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username, scriptLabel, username, scriptLabel, keep); err != nil {
+		log.Error("Failed to prune inline Homescript runs: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes the run history of a saved Homescript
+func DeleteHomescriptRuns(homescriptId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescript_run
+	WHERE HomescriptId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete Homescript runs: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(homescriptId); err != nil {
+		log.Error("Failed to delete Homescript runs: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes the run history of a given user
+func DeleteAllHomescriptRunsFromUser(username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescript_run
+	WHERE Username=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete Homescript runs of user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username); err != nil {
+		log.Error("Failed to delete Homescript runs of user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCreateHomescriptRunTable(t *testing.T) {
+	if err := createHomescriptRunTable(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
+
+// Tests the creation, listing and retention of Homescript runs
+func TestHomescriptRuns(t *testing.T) {
+	if err := CreateNewHomescript(Homescript{
+		Id:           "run_history",
+		Owner:        "admin",
+		RunRetention: 3,
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	homescriptId := "run_history"
+	var lastId uint
+	finishedAt := time.Now()
+	for index := 0; index < 5; index++ {
+		id, err := AddHomescriptRun(HomescriptRun{
+			HomescriptId: &homescriptId,
+			ScriptLabel:  homescriptId,
+			Username:     "admin",
+			Trigger:      "live",
+			StartedAt:    time.Now(),
+			FinishedAt:   &finishedAt,
+			ExitCode:     index,
+			Output:       "output",
+			Errors:       json.RawMessage(`[{"message":"test"}]`),
+		})
+		if err != nil {
+			t.Error(err.Error())
+			return
+		}
+		lastId = id
+	}
+	run, found, err := GetHomescriptRunById(lastId)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found {
+		t.Errorf("Run %d does not exist after creation", lastId)
+		return
+	}
+	if run.ExitCode != 4 || run.Output != "output" || string(run.Errors) != `[{"message":"test"}]` || run.HomescriptId == nil {
+		t.Errorf("Run %d does not match its input: got: %v", lastId, run)
+		return
+	}
+	// Runs which are in progress are completed later
+	parentId := lastId
+	runningId, err := AddHomescriptRun(HomescriptRun{
+		HomescriptId: &homescriptId,
+		ParentRunId:  &parentId,
+		ScriptLabel:  homescriptId,
+		Username:     "admin",
+		Trigger:      "exec",
+		StartedAt:    time.Now(),
+	})
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := FinishHomescriptRun(runningId, time.Now(), 1, "finished", nil); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	run, found, err = GetHomescriptRunById(runningId)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || run.FinishedAt == nil || run.ExitCode != 1 || run.Output != "finished" || run.ParentRunId == nil || *run.ParentRunId != parentId {
+		t.Errorf("Run %d was not finished correctly: got: %v", runningId, run)
+		return
+	}
+	lastId = runningId
+	if err := PruneHomescriptRuns(homescriptId, 3); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	runs, err := ListUserHomescriptRuns("admin", homescriptId, 100)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(runs) != 3 {
+		t.Errorf("Unexpected amount of runs after pruning: want: 3 got: %d", len(runs))
+		return
+	}
+	if runs[0].Id != lastId {
+		t.Errorf("Runs are not ordered newest first: want: %d got: %d", lastId, runs[0].Id)
+		return
+	}
+	// Deleting the Homescript also deletes its runs
+	if err := DeleteHomescriptById(homescriptId); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	runs, err = ListUserHomescriptRuns("admin", homescriptId, 100)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(runs) != 0 {
+		t.Errorf("Runs of deleted Homescript still exist: got: %d", len(runs))
+		return
+	}
+}
@@ -0,0 +1,361 @@
+package database
+
+import "database/sql"
+
+// Describes what a user who is not the owner may do with a shared Homescript
+// Each access level includes the levels before it
+type HomescriptAccess string
+
+const (
+	HomescriptAccessNone HomescriptAccess = ""
+	HomescriptAccessView HomescriptAccess = "view" // View the code and its revisions
+	HomescriptAccessRun  HomescriptAccess = "run"  // Run the Homescript using the permissions of the caller
+	HomescriptAccessEdit HomescriptAccess = "edit" // Modify the code of the Homescript, automations and webhooks of the owner keep running the owner's code
+)
+
+// Returns the rank of an access level, higher ranks include lower ones
+func (self HomescriptAccess) rank() int {
+	switch self {
+	case HomescriptAccessView:
+		return 1
+	case HomescriptAccessRun:
+		return 2
+	case HomescriptAccessEdit:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// Returns whether this access level includes the given one
+func (self HomescriptAccess) Includes(access HomescriptAccess) bool {
+	return self.rank() >= access.rank()
+}
+
+func IsValidHomescriptAccess(access HomescriptAccess) bool {
+	return access.rank() > 0
+}
+
+// Grants access to a Homescript to either a single user or to every member of a group
+// Exactly one of `Username` and `GroupId` is set
+type HomescriptShare struct {
+	Id           uint             `json:"id"`
+	HomescriptId string           `json:"homescriptId"`
+	Username     *string          `json:"username"`
+	GroupId      *string          `json:"groupId"`
+	Access       HomescriptAccess `json:"access"`
+}
+
+// A Homescript which has been shared with a user alongside the user's access level
+type SharedHomescript struct {
+	Homescript
+	Access HomescriptAccess `json:"access"`
+}
+
+// Creates the table containing the grants of shared Homescripts
+// If the database fails, this function returns an error
+func createHomescriptShareTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	homescriptShare(
+		Id INT AUTO_INCREMENT PRIMARY KEY,
+		HomescriptId VARCHAR(30),
+		Username VARCHAR(20) NULL,
+		GroupId VARCHAR(30) NULL,
+		Access ENUM('view', 'run', 'edit'),
+		CONSTRAINT HomescriptShareHomescript
+		FOREIGN KEY (HomescriptId)
+		REFERENCES homescript(Id),
+		CONSTRAINT HomescriptShareUsername
+		FOREIGN KEY (Username)
+		REFERENCES user(Username),
+		CONSTRAINT HomescriptShareGroup
+		FOREIGN KEY (GroupId)
+		REFERENCES userGroup(Id)
+	)
+	`); err != nil {
+		log.Error("Failed to create Homescript share table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Adds a new grant and returns its id, does not validate the user, the group or the Homescript
+func AddHomescriptShare(share HomescriptShare) (uint, error) {
+	query, err := db.Prepare(`
+	INSERT INTO
+	homescriptShare(
+		HomescriptId,
+		Username,
+		GroupId,
+		Access
+	)
+	VALUES(?, ?, ?, ?)
+	`)
+	if err != nil {
+		log.Error("Failed to add Homescript share: preparing query failed: ", err.Error())
+		return 0, err
+	}
+	defer query.Close()
+	res, err := query.Exec(
+		share.HomescriptId,
+		share.Username,
+		share.GroupId,
+		share.Access,
+	)
+	if err != nil {
+		log.Error("Failed to add Homescript share: executing query failed: ", err.Error())
+		return 0, err
+	}
+	newId, err := res.LastInsertId()
+	if err != nil {
+		log.Error("Failed to add Homescript share: retrieving last insert id failed: ", err.Error())
+		return 0, err
+	}
+	return uint(newId), nil
+}
+
+// Returns a grant given its id
+func GetHomescriptShareById(id uint) (HomescriptShare, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, HomescriptId, Username, GroupId, Access
+	FROM homescriptShare
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to get Homescript share by id: preparing query failed: ", err.Error())
+		return HomescriptShare{}, false, err
+	}
+	defer query.Close()
+	var share HomescriptShare
+	if err := query.QueryRow(id).Scan(
+		&share.Id,
+		&share.HomescriptId,
+		&share.Username,
+		&share.GroupId,
+		&share.Access,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return HomescriptShare{}, false, nil
+		}
+		log.Error("Failed to get Homescript share by id: executing query failed: ", err.Error())
+		return HomescriptShare{}, false, err
+	}
+	return share, true, nil
+}
+
+// Returns the grants of a given Homescript
+func ListHomescriptShares(homescriptId string) ([]HomescriptShare, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, HomescriptId, Username, GroupId, Access
+	FROM homescriptShare
+	WHERE HomescriptId=?
+	`)
+	if err != nil {
+		log.Error("Failed to list Homescript shares: preparing query failed: ", err.Error())
+		return nil, err
+	}
+	defer query.Close()
+	res, err := query.Query(homescriptId)
+	if err != nil {
+		log.Error("Failed to list Homescript shares: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	shares := make([]HomescriptShare, 0)
+	for res.Next() {
+		var share HomescriptShare
+		if err := res.Scan(
+			&share.Id,
+			&share.HomescriptId,
+			&share.Username,
+			&share.GroupId,
+			&share.Access,
+		); err != nil {
+			log.Error("Failed to list Homescript shares: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	return shares, nil
+}
+
+// Returns the Homescripts which have been shared with a user, either directly or using one of the user's groups
+// If multiple grants apply to a Homescript, the highest access level is used
+// Homescripts which are owned by the user are not included
+func ListSharedHomescripts(username string) ([]SharedHomescript, error) {
+	query, err := db.Prepare(`
+	SELECT
+	homescript.Id, homescript.Owner, homescript.Name, homescript.Description,
+	homescript.QuickActionsEnabled, homescript.SchedulerEnabled, homescript.Code,
+	homescript.RunRetention, homescript.QuickActionIcon, homescript.QuickActionColor,
+	homescriptShare.Access
+	FROM homescriptShare
+	JOIN homescript
+	ON homescriptShare.HomescriptId=homescript.Id
+	WHERE homescript.Owner<>?
+	AND (
+		homescriptShare.Username=?
+		OR homescriptShare.GroupId IN (
+			SELECT GroupId FROM userGroupMember
+			WHERE Username=?
+		)
+	)
+	`)
+	if err != nil {
+		log.Error("Failed to list shared Homescripts: preparing query failed: ", err.Error())
+		return nil, err
+	}
+	defer query.Close()
+	res, err := query.Query(username, username, username)
+	if err != nil {
+		log.Error("Failed to list shared Homescripts: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	// A Homescript can be returned multiple times if several grants apply
+	shared := make([]SharedHomescript, 0)
+	indices := make(map[string]int)
+	for res.Next() {
+		var item SharedHomescript
+		if err := res.Scan(
+			&item.Id,
+			&item.Owner,
+			&item.Name,
+			&item.Description,
+			&item.QuickActionsEnabled,
+			&item.SchedulerEnabled,
+			&item.Code,
+			&item.RunRetention,
+			&item.QuickActionIcon,
+			&item.QuickActionColor,
+			&item.Access,
+		); err != nil {
+			log.Error("Failed to list shared Homescripts: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		if index, exists := indices[item.Id]; exists {
+			if !shared[index].Access.Includes(item.Access) {
+				shared[index].Access = item.Access
+			}
+			continue
+		}
+		indices[item.Id] = len(shared)
+		shared = append(shared, item)
+	}
+	return shared, nil
+}
+
+// Returns the access level of a user to a Homescript
+// The owner always has edit access, users without any grant have no access
+func GetHomescriptAccess(homescriptItem Homescript, username string) (HomescriptAccess, error) {
+	if homescriptItem.Owner == username {
+		return HomescriptAccessEdit, nil
+	}
+	shared, err := ListSharedHomescripts(username)
+	if err != nil {
+		return HomescriptAccessNone, err
+	}
+	for _, item := range shared {
+		if item.Id == homescriptItem.Id {
+			return item.Access, nil
+		}
+	}
+	return HomescriptAccessNone, nil
+}
+
+// Returns a Homescript if the user owns it or if it has been shared with the user using at least the given access level
+// Returns Homescript, has been found, error
+func GetAccessibleHomescriptById(homescriptId string, username string, access HomescriptAccess) (Homescript, bool, error) {
+	homescriptItem, found, err := GetHomescriptById(homescriptId)
+	if err != nil || !found {
+		return Homescript{}, false, err
+	}
+	userAccess, err := GetHomescriptAccess(homescriptItem, username)
+	if err != nil {
+		return Homescript{}, false, err
+	}
+	if userAccess == HomescriptAccessNone || !userAccess.Includes(access) {
+		return Homescript{}, false, nil
+	}
+	return homescriptItem, true, nil
+}
+
+// Deletes a grant given its id
+func DeleteHomescriptShareById(id uint) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescriptShare
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete Homescript share: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(id); err != nil {
+		log.Error("Failed to delete Homescript share: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all grants of a given Homescript
+func DeleteHomescriptShares(homescriptId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescriptShare
+	WHERE HomescriptId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete Homescript shares: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(homescriptId); err != nil {
+		log.Error("Failed to delete Homescript shares: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all grants which have been given to a user and all grants of the Homescripts owned by the user
+func DeleteAllHomescriptSharesOfUser(username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescriptShare
+	WHERE Username=?
+	OR HomescriptId IN (
+		SELECT Id FROM homescript
+		WHERE Owner=?
+	)
+	`)
+	if err != nil {
+		log.Error("Failed to delete Homescript shares of user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username, username); err != nil {
+		log.Error("Failed to delete Homescript shares of user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all grants which have been given to a group
+func DeleteAllHomescriptSharesOfGroup(groupId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescriptShare
+	WHERE GroupId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete Homescript shares of group: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(groupId); err != nil {
+		log.Error("Failed to delete Homescript shares of group: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
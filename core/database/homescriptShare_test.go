@@ -0,0 +1,129 @@
+package database
+
+import "testing"
+
+func TestHomescriptAccessIncludes(t *testing.T) {
+	table := []struct {
+		Access   HomescriptAccess
+		Required HomescriptAccess
+		Want     bool
+	}{
+		{Access: HomescriptAccessEdit, Required: HomescriptAccessView, Want: true},
+		{Access: HomescriptAccessEdit, Required: HomescriptAccessEdit, Want: true},
+		{Access: HomescriptAccessRun, Required: HomescriptAccessView, Want: true},
+		{Access: HomescriptAccessRun, Required: HomescriptAccessEdit, Want: false},
+		{Access: HomescriptAccessView, Required: HomescriptAccessRun, Want: false},
+		{Access: HomescriptAccessNone, Required: HomescriptAccessView, Want: false},
+	}
+	for _, test := range table {
+		if got := test.Access.Includes(test.Required); got != test.Want {
+			t.Errorf("Access '%s' including '%s': want: %t got: %t", test.Access, test.Required, test.Want, got)
+			return
+		}
+	}
+}
+
+// Tests sharing Homescripts with users and groups
+func TestHomescriptShares(t *testing.T) {
+	if err := AddUser(FullUser{
+		Username: "share_test",
+		Password: "test",
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := CreateNewHomescript(Homescript{
+		Id:    "share_test",
+		Owner: "admin",
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	// Without any grant, the Homescript is not accessible
+	_, found, err := GetAccessibleHomescriptById("share_test", "share_test", HomescriptAccessView)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if found {
+		t.Error("Homescript is accessible without any grant")
+		return
+	}
+	username := "share_test"
+	if _, err := AddHomescriptShare(HomescriptShare{
+		HomescriptId: "share_test",
+		Username:     &username,
+		Access:       HomescriptAccessView,
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := CreateUserGroup(UserGroup{Id: "share_test"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := AddUserGroupMember("share_test", "share_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	groupId := "share_test"
+	if _, err := AddHomescriptShare(HomescriptShare{
+		HomescriptId: "share_test",
+		GroupId:      &groupId,
+		Access:       HomescriptAccessRun,
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	// The highest access level of all grants is used
+	shared, err := ListSharedHomescripts("share_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(shared) != 1 || shared[0].Id != "share_test" || shared[0].Access != HomescriptAccessRun {
+		t.Errorf("Unexpected shared Homescripts: want one with run access, got: %v", shared)
+		return
+	}
+	_, found, err = GetAccessibleHomescriptById("share_test", "share_test", HomescriptAccessEdit)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if found {
+		t.Error("Homescript is editable using a run grant")
+		return
+	}
+	// The owner always has access
+	_, found, err = GetAccessibleHomescriptById("share_test", "admin", HomescriptAccessEdit)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found {
+		t.Error("Homescript is not accessible by its owner")
+		return
+	}
+	// Deleting the group revokes its grants
+	if err := DeleteUserGroup("share_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	access, err := GetHomescriptAccess(Homescript{Id: "share_test", Owner: "admin"}, "share_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if access != HomescriptAccessView {
+		t.Errorf("Group grant was not revoked: want: view got: %s", access)
+		return
+	}
+	if err := DeleteUser("share_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := DeleteHomescriptById("share_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Is used as the owner of entries which are shared by all users
+const GlobalStorageOwner = ""
+
+// A persistent key-value pair which can be read and written by Homescripts
+type StorageEntry struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	UpdatedBy string    `json:"updatedBy"`
+}
+
+// Creates the table containing the persistent key-value storage of Homescripts
+// The owner is not a foreign key because global entries use an empty owner
+// If the database fails, this function returns an error
+func createHomescriptStorageTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	homescriptStorage(
+		Owner VARCHAR(20),
+		StorageKey VARCHAR(50),
+		Value TEXT,
+		UpdatedAt DATETIME(3),
+		UpdatedBy VARCHAR(20),
+		PRIMARY KEY (Owner, StorageKey)
+	)
+	`); err != nil {
+		log.Error("Failed to create Homescript storage table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns a single entry of an owner
+func GetStorageEntry(owner string, key string) (StorageEntry, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	StorageKey, Value, UpdatedAt, UpdatedBy
+	FROM homescriptStorage
+	WHERE Owner=?
+	AND StorageKey=?
+	`)
+	if err != nil {
+		log.Error("Failed to get storage entry: preparing query failed: ", err.Error())
+		return StorageEntry{}, false, err
+	}
+	defer query.Close()
+	var entry StorageEntry
+	if err := query.QueryRow(owner, key).Scan(
+		&entry.Key,
+		&entry.Value,
+		&entry.UpdatedAt,
+		&entry.UpdatedBy,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return StorageEntry{}, false, nil
+		}
+		log.Error("Failed to get storage entry: executing query failed: ", err.Error())
+		return StorageEntry{}, false, err
+	}
+	return entry, true, nil
+}
+
+// Returns all entries of an owner sorted by their key
+func ListStorageEntries(owner string) ([]StorageEntry, error) {
+	query, err := db.Prepare(`
+	SELECT
+	StorageKey, Value, UpdatedAt, UpdatedBy
+	FROM homescriptStorage
+	WHERE Owner=?
+	ORDER BY StorageKey
+	`)
+	if err != nil {
+		log.Error("Failed to list storage entries: preparing query failed: ", err.Error())
+		return nil, err
+	}
+	defer query.Close()
+	res, err := query.Query(owner)
+	if err != nil {
+		log.Error("Failed to list storage entries: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	entries := make([]StorageEntry, 0)
+	for res.Next() {
+		var entry StorageEntry
+		if err := res.Scan(
+			&entry.Key,
+			&entry.Value,
+			&entry.UpdatedAt,
+			&entry.UpdatedBy,
+		); err != nil {
+			log.Error("Failed to list storage entries: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Returns the amount of entries of an owner
+func CountStorageEntries(owner string) (uint, error) {
+	query, err := db.Prepare(`
+	SELECT COUNT(*)
+	FROM homescriptStorage
+	WHERE Owner=?
+	`)
+	if err != nil {
+		log.Error("Failed to count storage entries: preparing query failed: ", err.Error())
+		return 0, err
+	}
+	defer query.Close()
+	var count uint
+	if err := query.QueryRow(owner).Scan(&count); err != nil {
+		log.Error("Failed to count storage entries: executing query failed: ", err.Error())
+		return 0, err
+	}
+	return count, nil
+}
+
+// Creates or overwrites an entry of an owner
+func SetStorageEntry(owner string, key string, value string, updatedBy string) error {
+	query, err := db.Prepare(`
+	INSERT INTO
+	homescriptStorage(
+		Owner,
+		StorageKey,
+		Value,
+		UpdatedAt,
+		UpdatedBy
+	)
+	VALUES(?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE
+	Value=VALUES(Value),
+	UpdatedAt=VALUES(UpdatedAt),
+	UpdatedBy=VALUES(UpdatedBy)
+	`)
+	if err != nil {
+		log.Error("Failed to set storage entry: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(owner, key, value, time.Now(), updatedBy); err != nil {
+		log.Error("Failed to set storage entry: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes an entry of an owner, does nothing if the entry does not exist
+func DeleteStorageEntry(owner string, key string) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescriptStorage
+	WHERE Owner=?
+	AND StorageKey=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete storage entry: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(owner, key); err != nil {
+		log.Error("Failed to delete storage entry: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all personal entries of a given user
+func DeleteAllStorageEntriesOfUser(username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM homescriptStorage
+	WHERE Owner=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete storage entries of user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username); err != nil {
+		log.Error("Failed to delete storage entries of user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package database
+
+import "testing"
+
+// Tests that personal and global entries are separated
+func TestHomescriptStorage(t *testing.T) {
+	if err := SetStorageEntry("admin", "storage_test", "personal", "admin"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := SetStorageEntry(GlobalStorageOwner, "storage_test", "global", "admin"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	// Overwriting an entry does not create a new one
+	if err := SetStorageEntry("admin", "storage_test", "overwritten", "admin"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	entry, found, err := GetStorageEntry("admin", "storage_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || entry.Value != "overwritten" {
+		t.Errorf("Unexpected personal entry: want: overwritten got: %v (found: %t)", entry, found)
+		return
+	}
+	entry, found, err = GetStorageEntry(GlobalStorageOwner, "storage_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || entry.Value != "global" {
+		t.Errorf("Unexpected global entry: want: global got: %v (found: %t)", entry, found)
+		return
+	}
+	count, err := CountStorageEntries("admin")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	entries, err := ListStorageEntries("admin")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if uint(len(entries)) != count {
+		t.Errorf("Count does not match list: want: %d got: %d", len(entries), count)
+		return
+	}
+	if err := DeleteStorageEntry("admin", "storage_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := DeleteStorageEntry(GlobalStorageOwner, "storage_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	_, found, err = GetStorageEntry("admin", "storage_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if found {
+		t.Error("Entry was not deleted")
+		return
+	}
+}
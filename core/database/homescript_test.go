@@ -274,3 +274,44 @@ func TestGetUserHomescriptById(t *testing.T) {
 		return
 	}
 }
+
+func TestHomescriptQuickActionMetadata(t *testing.T) {
+	if err := CreateNewHomescript(Homescript{
+		Id:                  "quick_action",
+		Owner:               "admin",
+		Name:                "quick action",
+		QuickActionsEnabled: true,
+		QuickActionIcon:     "lamp",
+		QuickActionColor:    "#88FF70",
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	homescriptItem, found, err := GetUserHomescriptById("quick_action", "admin")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || homescriptItem.QuickActionIcon != "lamp" || homescriptItem.QuickActionColor != "#88FF70" {
+		t.Errorf("Quick action metadata does not match its input: found: %t got: %v", found, homescriptItem)
+		return
+	}
+	if err := ModifyHomescriptById("quick_action", HomescriptFrontend{
+		Name:                "quick action",
+		QuickActionsEnabled: true,
+		QuickActionIcon:     "fan",
+		QuickActionColor:    "#000000",
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	homescriptItem, _, err = GetUserHomescriptById("quick_action", "admin")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if homescriptItem.QuickActionIcon != "fan" || homescriptItem.QuickActionColor != "#000000" {
+		t.Errorf("Modified quick action metadata does not match its input: got: %v", homescriptItem)
+		return
+	}
+}
@@ -64,6 +64,9 @@ func Init(databaseConfig DatabaseConfig, adminPassword string) error {
 	if err := createAutomationTable(); err != nil {
 		return err
 	}
+	if err := createAutomationRunTable(); err != nil {
+		return err
+	}
 	if err := createScheduleTable(); err != nil {
 		return err
 	}
@@ -76,6 +79,45 @@ func Init(databaseConfig DatabaseConfig, adminPassword string) error {
 	if err := createHasCameraPermissionsTable(); err != nil {
 		return err
 	}
+	if err := createPowerTimerTable(); err != nil {
+		return err
+	}
+	if err := createNetworkDeviceTable(); err != nil {
+		return err
+	}
+	if err := createEnergyUsageTable(); err != nil {
+		return err
+	}
+	if err := createEnergyBudgetTable(); err != nil {
+		return err
+	}
+	if err := createHomescriptRunTable(); err != nil {
+		return err
+	}
+	if err := createWebhookTable(); err != nil {
+		return err
+	}
+	if err := createHomescriptRevisionTable(); err != nil {
+		return err
+	}
+	if err := createUserGroupTables(); err != nil {
+		return err
+	}
+	if err := createHomescriptShareTable(); err != nil {
+		return err
+	}
+	if err := createHomescriptStorageTable(); err != nil {
+		return err
+	}
+	if err := createHomescriptHttpHostTable(); err != nil {
+		return err
+	}
+	if err := createRadiGoServerTable(); err != nil {
+		return err
+	}
+	if err := createHasRadiGoPermissionTable(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"database/sql"
+)
+
+// Specifies how the power state of a network device is determined
+type NetworkStatusMode string
+
+const (
+	NetworkStatusNone NetworkStatusMode = "none" // The power state is only changed by power actions
+	NetworkStatusPing NetworkStatusMode = "ping" // The device is on if its host responds to a ping
+	NetworkStatusHttp NetworkStatusMode = "http" // The device is on if its status url responds with a 2xx status code
+)
+
+// Turns a switch into a network device, for example a PC which can be woken up using Wake-on-LAN
+// Switches which have a network device are not addressed through the hardware nodes
+type NetworkDevice struct {
+	SwitchId         string            `json:"switchId"`
+	MacAddress       string            `json:"macAddress"`       // Target of the Wake-on-LAN magic packet
+	BroadcastAddress string            `json:"broadcastAddress"` // Address to which the magic packet is sent, for example `192.168.0.255:9`
+	Host             string            `json:"host"`             // Used for checking reachability using ping
+	StatusMode       NetworkStatusMode `json:"statusMode"`
+	StatusUrl        string            `json:"statusUrl"`   // Used for checking reachability using HTTP
+	ShutdownUrl      string            `json:"shutdownUrl"` // If set, a POST request to this url is made in order to turn off the device
+	GracePeriod      uint              `json:"gracePeriod"` // Seconds after a power action during which the reachability is not checked, 0 uses the default
+}
+
+// Creates the table containing the network device configuration of switches
+func createNetworkDeviceTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	networkDevice(
+		SwitchId VARCHAR(20),
+		MacAddress VARCHAR(17),
+		BroadcastAddress VARCHAR(50),
+		Host VARCHAR(100),
+		StatusMode VARCHAR(10),
+		StatusUrl TEXT,
+		ShutdownUrl TEXT,
+		GracePeriod INT DEFAULT 0,
+		PRIMARY KEY (SwitchId),
+		FOREIGN KEY (SwitchId)
+		REFERENCES switch(Id)
+	)
+	`); err != nil {
+		log.Error("Failed to create network device table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Creates or updates the network device configuration of a switch
+// Does not check the validity of the switch
+func SetNetworkDevice(device NetworkDevice) error {
+	query, err := db.Prepare(`
+	INSERT INTO
+	networkDevice(
+		SwitchId, MacAddress, BroadcastAddress, Host, StatusMode, StatusUrl, ShutdownUrl, GracePeriod
+	)
+	VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+	ON DUPLICATE KEY
+		UPDATE
+		MacAddress=VALUES(MacAddress),
+		BroadcastAddress=VALUES(BroadcastAddress),
+		Host=VALUES(Host),
+		StatusMode=VALUES(StatusMode),
+		StatusUrl=VALUES(StatusUrl),
+		ShutdownUrl=VALUES(ShutdownUrl),
+		GracePeriod=VALUES(GracePeriod)
+	`)
+	if err != nil {
+		log.Error("Failed to set network device: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(
+		device.SwitchId,
+		device.MacAddress,
+		device.BroadcastAddress,
+		device.Host,
+		device.StatusMode,
+		device.StatusUrl,
+		device.ShutdownUrl,
+		device.GracePeriod,
+	); err != nil {
+		log.Error("Failed to set network device: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns the network device configuration of a switch
+// If the switch is not a network device, a `false` is returned
+func GetNetworkDeviceBySwitchId(switchId string) (NetworkDevice, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	SwitchId, MacAddress, BroadcastAddress, Host, StatusMode, StatusUrl, ShutdownUrl, GracePeriod
+	FROM networkDevice
+	WHERE SwitchId=?
+	`)
+	if err != nil {
+		log.Error("Failed to get network device: preparing query failed: ", err.Error())
+		return NetworkDevice{}, false, err
+	}
+	defer query.Close()
+	var device NetworkDevice
+	if err := query.QueryRow(switchId).Scan(
+		&device.SwitchId,
+		&device.MacAddress,
+		&device.BroadcastAddress,
+		&device.Host,
+		&device.StatusMode,
+		&device.StatusUrl,
+		&device.ShutdownUrl,
+		&device.GracePeriod,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return NetworkDevice{}, false, nil
+		}
+		log.Error("Failed to get network device: executing query failed: ", err.Error())
+		return NetworkDevice{}, false, err
+	}
+	return device, true, nil
+}
+
+// Returns a list of all network devices
+func ListNetworkDevices() ([]NetworkDevice, error) {
+	res, err := db.Query(`
+	SELECT
+	SwitchId, MacAddress, BroadcastAddress, Host, StatusMode, StatusUrl, ShutdownUrl, GracePeriod
+	FROM networkDevice
+	`)
+	if err != nil {
+		log.Error("Failed to list network devices: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	devices := make([]NetworkDevice, 0)
+	for res.Next() {
+		var device NetworkDevice
+		if err := res.Scan(
+			&device.SwitchId,
+			&device.MacAddress,
+			&device.BroadcastAddress,
+			&device.Host,
+			&device.StatusMode,
+			&device.StatusUrl,
+			&device.ShutdownUrl,
+			&device.GracePeriod,
+		); err != nil {
+			log.Error("Failed to list network devices: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// Removes the network device configuration of a switch, turning it back into a regular switch
+func DeleteNetworkDevice(switchId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM
+	networkDevice
+	WHERE SwitchId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete network device: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(switchId); err != nil {
+		log.Error("Failed to delete network device: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
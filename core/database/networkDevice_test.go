@@ -0,0 +1,77 @@
+package database
+
+import "testing"
+
+// Creates the network device table in order to check if the sql query works
+func TestCreateNetworkDeviceTable(t *testing.T) {
+	if err := createNetworkDeviceTable(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
+
+// Tests the creation, modification and deletion of network devices
+func TestNetworkDevices(t *testing.T) {
+	if err := CreateRoom(RoomData{Id: "network_room", Name: "", Description: ""}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := CreateSwitch(Switch{Id: "network_pc", RoomId: "network_room"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	table := []NetworkDevice{
+		{
+			SwitchId:   "network_pc",
+			MacAddress: "00:11:22:33:44:55",
+			Host:       "192.168.0.10",
+			StatusMode: NetworkStatusPing,
+		},
+		{
+			SwitchId:         "network_pc",
+			MacAddress:       "00:11:22:33:44:66",
+			BroadcastAddress: "192.168.0.255:9",
+			StatusMode:       NetworkStatusHttp,
+			StatusUrl:        "http://192.168.0.10/status",
+			ShutdownUrl:      "http://192.168.0.10/shutdown",
+			GracePeriod:      300,
+		},
+	}
+	for _, test := range table {
+		if err := SetNetworkDevice(test); err != nil {
+			t.Error(err.Error())
+			return
+		}
+		device, found, err := GetNetworkDeviceBySwitchId(test.SwitchId)
+		if err != nil {
+			t.Error(err.Error())
+			return
+		}
+		if !found {
+			t.Errorf("Network device '%s' does not exist after creation", test.SwitchId)
+			return
+		}
+		if device != test {
+			t.Errorf("Network device does not match its input. Want: %v Got: %v", test, device)
+			return
+		}
+	}
+	devices, err := ListNetworkDevices()
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(devices) != 1 {
+		t.Errorf("Unexpected number of network devices. Want: 1 Got: %d", len(devices))
+		return
+	}
+	// Deleting the switch must also delete its network device configuration
+	if err := DeleteSwitch("network_pc"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if _, found, err := GetNetworkDeviceBySwitchId("network_pc"); err != nil || found {
+		t.Errorf("Network device still exists after its switch was deleted")
+		return
+	}
+}
@@ -22,6 +22,9 @@ const (
 	PermissionReminder           PermissionType = "reminder"
 	PermissionModifyServerConfig PermissionType = "modifyServerConfig"
 	PermissionModifyRooms        PermissionType = "modifyRooms"
+	PermissionManageEnergy       PermissionType = "manageEnergy"
+	PermissionManageHomescripts  PermissionType = "manageHomescripts"
+	PermissionHomescriptHttp     PermissionType = "homescriptHttp"
 
 	// Dangerous
 	PermissionWildCard PermissionType = "*"
@@ -59,6 +62,18 @@ var (
 			Name:        "Use Homescript",
 			Description: "List, add, delete, run, and modify Homescripts",
 		},
+		{
+			// (Admin) is allowed to view and terminate the running Homescripts of every user
+			Permission:  PermissionManageHomescripts,
+			Name:        "Manage Running Homescripts",
+			Description: "View and terminate running Homescripts of all users",
+		},
+		{
+			// User is allowed to send HTTP requests from Homescript, the hosts are still restricted by the allowlist
+			Permission:  PermissionHomescriptHttp,
+			Name:        "Send HTTP Requests from Homescript",
+			Description: "Send HTTP requests to the hosts which are allowed by the administrator",
+		},
 		{
 			// User is allowed to set up, modify, delete, and view personal automations
 			Permission:  PermissionAutomation,
@@ -83,6 +98,12 @@ var (
 			Name:        "Manage Rooms",
 			Description: "View, add, modify and delete rooms and room like switches and cameras. If enabled, the user also has access to every switch of the system.",
 		},
+		{
+			// (Admin) is allowed to set up, modify and delete energy budgets of switches and rooms
+			Permission:  PermissionManageEnergy,
+			Name:        "Manage Energy Budgets",
+			Description: "Add, modify and delete energy budgets which limit the power consumption of switches and rooms",
+		},
 		{
 			// User is allowed to view the video feed of cameras to which he has access
 			Permission:  PermissionViewCameras,
@@ -0,0 +1,221 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// A pending power action which is executed at a given time
+// Is used for delayed switching and for turning a switch off again after a given duration
+type PowerTimer struct {
+	Id        uint      `json:"id"`
+	Owner     string    `json:"owner"`
+	SwitchId  string    `json:"switchId"`
+	PowerOn   bool      `json:"powerOn"`
+	ExecuteAt time.Time `json:"executeAt"`
+}
+
+// Creates the table containing pending power timers
+func createPowerTimerTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	powerTimer(
+		Id INT AUTO_INCREMENT,
+		Owner VARCHAR(20),
+		SwitchId VARCHAR(20),
+		PowerOn BOOLEAN,
+		ExecuteAt DATETIME,
+		PRIMARY KEY (Id),
+		FOREIGN KEY (Owner)
+		REFERENCES user(Username),
+		FOREIGN KEY (SwitchId)
+		REFERENCES switch(Id)
+	)
+	`); err != nil {
+		log.Error("Failed to create power timer table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Creates a new power timer, does not check the validity of the user or the switch
+func CreatePowerTimer(timer PowerTimer) (uint, error) {
+	query, err := db.Prepare(`
+	INSERT INTO
+	powerTimer(
+		Id, Owner, SwitchId, PowerOn, ExecuteAt
+	)
+	VALUES(DEFAULT, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		log.Error("Failed to create power timer: preparing query failed: ", err.Error())
+		return 0, err
+	}
+	defer query.Close()
+	res, err := query.Exec(
+		timer.Owner,
+		timer.SwitchId,
+		timer.PowerOn,
+		timer.ExecuteAt,
+	)
+	if err != nil {
+		log.Error("Failed to create power timer: executing query failed: ", err.Error())
+		return 0, err
+	}
+	newId, err := res.LastInsertId()
+	if err != nil {
+		log.Error("Failed to create power timer: retrieving last inserted id failed: ", err.Error())
+		return 0, err
+	}
+	return uint(newId), nil
+}
+
+// Returns a power timer given its id
+// If the id does not match a timer, a `false` is returned
+func GetPowerTimerById(id uint) (PowerTimer, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, Owner, SwitchId, PowerOn, ExecuteAt
+	FROM powerTimer
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to get power timer by id: preparing query failed: ", err.Error())
+		return PowerTimer{}, false, err
+	}
+	defer query.Close()
+	var timer PowerTimer
+	if err := query.QueryRow(id).Scan(
+		&timer.Id,
+		&timer.Owner,
+		&timer.SwitchId,
+		&timer.PowerOn,
+		&timer.ExecuteAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return PowerTimer{}, false, nil
+		}
+		log.Error("Failed to get power timer by id: executing query failed: ", err.Error())
+		return PowerTimer{}, false, err
+	}
+	return timer, true, nil
+}
+
+// Returns a list of all pending power timers, ordered by their execution time
+// Used for restoring the timers when the server starts
+func GetPowerTimers() ([]PowerTimer, error) {
+	res, err := db.Query(`
+	SELECT
+	Id, Owner, SwitchId, PowerOn, ExecuteAt
+	FROM powerTimer
+	ORDER BY ExecuteAt ASC
+	`)
+	if err != nil {
+		log.Error("Failed to list power timers: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	return scanPowerTimers(res)
+}
+
+// Returns a list of the pending power timers of a given user, ordered by their execution time
+func GetUserPowerTimers(username string) ([]PowerTimer, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, Owner, SwitchId, PowerOn, ExecuteAt
+	FROM powerTimer
+	WHERE Owner=?
+	ORDER BY ExecuteAt ASC
+	`)
+	if err != nil {
+		log.Error("Failed to list user power timers: preparing query failed: ", err.Error())
+		return nil, err
+	}
+	defer query.Close()
+	res, err := query.Query(username)
+	if err != nil {
+		log.Error("Failed to list user power timers: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	return scanPowerTimers(res)
+}
+
+// Scans the rows of a power timer query into a slice
+func scanPowerTimers(res *sql.Rows) ([]PowerTimer, error) {
+	timers := make([]PowerTimer, 0)
+	for res.Next() {
+		var timer PowerTimer
+		if err := res.Scan(
+			&timer.Id,
+			&timer.Owner,
+			&timer.SwitchId,
+			&timer.PowerOn,
+			&timer.ExecuteAt,
+		); err != nil {
+			log.Error("Failed to list power timers: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		timers = append(timers, timer)
+	}
+	return timers, nil
+}
+
+// Deletes a power timer given its id
+// Does not validate the validity of the provided id
+func DeletePowerTimerById(id uint) error {
+	query, err := db.Prepare(`
+	DELETE FROM
+	powerTimer
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete power timer: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(id); err != nil {
+		log.Error("Failed to delete power timer: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all power timers of a given user, used when deleting a user
+func DeleteAllPowerTimersFromUser(username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM
+	powerTimer
+	WHERE Owner=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete all power timers of user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username); err != nil {
+		log.Error("Failed to delete all power timers of user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all power timers which address a given switch, used when deleting a switch
+func DeleteSwitchPowerTimers(switchId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM
+	powerTimer
+	WHERE SwitchId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete power timers of switch: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(switchId); err != nil {
+		log.Error("Failed to delete power timers of switch: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+// Creates the power timer table in order to check if the sql query works
+func TestCreatePowerTimerTable(t *testing.T) {
+	if err := createPowerTimerTable(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
+
+// Tests the creation, retrieval and deletion of power timers
+func TestPowerTimers(t *testing.T) {
+	if err := CreateRoom(RoomData{Id: "timer_room", Name: "", Description: ""}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := CreateSwitch(Switch{Id: "timer_switch", RoomId: "timer_room"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	table := []PowerTimer{
+		{
+			Owner:     "admin",
+			SwitchId:  "timer_switch",
+			PowerOn:   true,
+			ExecuteAt: time.Now().Add(time.Minute),
+		},
+		{
+			Owner:     "admin",
+			SwitchId:  "timer_switch",
+			PowerOn:   false,
+			ExecuteAt: time.Now().Add(time.Hour),
+		},
+	}
+	for _, test := range table {
+		id, err := CreatePowerTimer(test)
+		if err != nil {
+			t.Error(err.Error())
+			return
+		}
+		timer, found, err := GetPowerTimerById(id)
+		if err != nil {
+			t.Error(err.Error())
+			return
+		}
+		if !found {
+			t.Errorf("Power timer '%d' does not exist after creation", id)
+			return
+		}
+		if timer.Owner != test.Owner || timer.SwitchId != test.SwitchId || timer.PowerOn != test.PowerOn {
+			t.Errorf("Power timer '%d' does not match its input. Want: %v Got: %v", id, test, timer)
+			return
+		}
+	}
+	timers, err := GetUserPowerTimers("admin")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(timers) != len(table) {
+		t.Errorf("Unexpected number of user power timers. Want: %d Got: %d", len(table), len(timers))
+		return
+	}
+	// The timers must be displayed on the switch in room listings
+	rooms, err := ListAllRoomsWithData()
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	for _, room := range rooms {
+		for _, switchItem := range room.Switches {
+			if switchItem.Id == "timer_switch" && len(switchItem.Timers) != len(table) {
+				t.Errorf("Unexpected number of timers on switch. Want: %d Got: %d", len(table), len(switchItem.Timers))
+				return
+			}
+		}
+	}
+	if err := DeletePowerTimerById(timers[0].Id); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if _, found, err := GetPowerTimerById(timers[0].Id); err != nil || found {
+		t.Errorf("Power timer '%d' still exists after deletion", timers[0].Id)
+		return
+	}
+	// Deleting the switch must also delete its remaining timers
+	if err := DeleteSwitch("timer_switch"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	timers, err = GetUserPowerTimers("admin")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(timers) != 0 {
+		t.Errorf("Power timers still exist after their switch was deleted")
+		return
+	}
+}
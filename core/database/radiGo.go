@@ -0,0 +1,302 @@
+package database
+
+import "database/sql"
+
+// A radiGo server which plays audio modes, for example internet radio stations
+type RadiGoServer struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Url   string `json:"url"` // Base url of the server, for example `http://radio.local:8080`
+	Token string `json:"-"`   // Is sent with every request, never returned by the API
+}
+
+// Creates the table which contains all radiGo servers
+func createRadiGoServerTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	radiGoServer(
+		Id VARCHAR(50) PRIMARY KEY,
+		Name VARCHAR(50),
+		Url TEXT,
+		Token TEXT
+	)
+	`); err != nil {
+		log.Error("Failed to create radiGo server table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Stores the n:m relation between users and the radiGo servers they may use
+func createHasRadiGoPermissionTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	hasRadiGoPermission(
+		Username VARCHAR(20),
+		Server VARCHAR(50),
+		PRIMARY KEY (Username, Server),
+		FOREIGN KEY (Username)
+		REFERENCES user(Username),
+		FOREIGN KEY (Server)
+		REFERENCES radiGoServer(Id)
+	)
+	`); err != nil {
+		log.Error("Failed to create hasRadiGoPermission table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Creates a new radiGo server or updates an existing one
+// The validation of the data should be completed beforehand
+func CreateRadiGoServer(server RadiGoServer) error {
+	query, err := db.Prepare(`
+	INSERT INTO
+	radiGoServer(
+		Id,
+		Name,
+		Url,
+		Token
+	)
+	VALUES(?, ?, ?, ?)
+	ON DUPLICATE KEY
+		UPDATE
+		Name=VALUES(Name),
+		Url=VALUES(Url),
+		Token=VALUES(Token)
+	`)
+	if err != nil {
+		log.Error("Failed to create radiGo server: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(
+		server.Id,
+		server.Name,
+		server.Url,
+		server.Token,
+	); err != nil {
+		log.Error("Failed to create radiGo server: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns a radiGo server given its id
+func GetRadiGoServerById(id string) (RadiGoServer, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+		Id,
+		Name,
+		Url,
+		Token
+	FROM radiGoServer
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to get radiGo server by id: preparing query failed: ", err.Error())
+		return RadiGoServer{}, false, err
+	}
+	defer query.Close()
+	var server RadiGoServer
+	if err := query.QueryRow(id).Scan(
+		&server.Id,
+		&server.Name,
+		&server.Url,
+		&server.Token,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return RadiGoServer{}, false, nil
+		}
+		log.Error("Failed to get radiGo server by id: executing query failed: ", err.Error())
+		return RadiGoServer{}, false, err
+	}
+	return server, true, nil
+}
+
+// Returns a list containing all radiGo servers
+func ListRadiGoServers() ([]RadiGoServer, error) {
+	res, err := db.Query(`
+	SELECT
+		Id,
+		Name,
+		Url,
+		Token
+	FROM radiGoServer
+	ORDER BY Name
+	`)
+	if err != nil {
+		log.Error("Failed to list radiGo servers: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	servers := make([]RadiGoServer, 0)
+	for res.Next() {
+		var server RadiGoServer
+		if err := res.Scan(
+			&server.Id,
+			&server.Name,
+			&server.Url,
+			&server.Token,
+		); err != nil {
+			log.Error("Failed to list radiGo servers: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+// Like `ListRadiGoServers()` but only returns the servers which the user is allowed to use
+func ListUserRadiGoServers(username string) ([]RadiGoServer, error) {
+	servers, err := ListRadiGoServers()
+	if err != nil {
+		return nil, err
+	}
+	userServers := make([]RadiGoServer, 0)
+	for _, server := range servers {
+		hasPermission, err := UserHasRadiGoPermission(username, server.Id)
+		if err != nil {
+			return nil, err
+		}
+		if hasPermission {
+			userServers = append(userServers, server)
+		}
+	}
+	return userServers, nil
+}
+
+// Deletes a radiGo server and all permissions which refer to it
+func DeleteRadiGoServer(id string) error {
+	if err := RemoveRadiGoServerFromPermissions(id); err != nil {
+		return err
+	}
+	query, err := db.Prepare(`
+	DELETE FROM radiGoServer
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete radiGo server: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(id); err != nil {
+		log.Error("Failed to delete radiGo server: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Allows a user to use a radiGo server, does nothing if the user already has the permission
+// The existence of the server and the user should be validated beforehand
+func AddUserRadiGoPermission(username string, serverId string) error {
+	query, err := db.Prepare(`
+	INSERT IGNORE INTO
+	hasRadiGoPermission(
+		Username,
+		Server
+	)
+	VALUES(?, ?)
+	`)
+	if err != nil {
+		log.Error("Failed to add radiGo permission to user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username, serverId); err != nil {
+		log.Error("Failed to add radiGo permission to user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Removes a radiGo permission of a user
+func RemoveUserRadiGoPermission(username string, serverId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM hasRadiGoPermission
+	WHERE Username=? AND Server=?
+	`)
+	if err != nil {
+		log.Error("Failed to remove radiGo permission of user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username, serverId); err != nil {
+		log.Error("Failed to remove radiGo permission of user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all permissions of a given server, used if the server is deleted
+func RemoveRadiGoServerFromPermissions(serverId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM hasRadiGoPermission
+	WHERE Server=?
+	`)
+	if err != nil {
+		log.Error("Failed to remove radiGo server from permissions: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(serverId); err != nil {
+		log.Error("Failed to remove radiGo server from permissions: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Removes all radiGo permissions of a given user, used when deleting a user
+func RemoveAllRadiGoPermissionsOfUser(username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM hasRadiGoPermission
+	WHERE Username=?
+	`)
+	if err != nil {
+		log.Error("Failed to remove all radiGo permissions of user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username); err != nil {
+		log.Error("Failed to remove all radiGo permissions of user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns whether a user has been granted the permission to use a specific radiGo server
+func UserHasRadiGoPermissionQuery(username string, serverId string) (bool, error) {
+	query, err := db.Prepare(`
+	SELECT Server
+	FROM hasRadiGoPermission
+	WHERE Username=? AND Server=?
+	`)
+	if err != nil {
+		log.Error("Failed to check user radiGo permission: preparing query failed: ", err.Error())
+		return false, err
+	}
+	defer query.Close()
+	if err := query.QueryRow(username, serverId).Scan(&serverId); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		log.Error("Failed to check user radiGo permission: executing query failed: ", err.Error())
+		return false, err
+	}
+	return true, nil
+}
+
+// Returns a boolean indicating whether a user may use a radiGo server
+func UserHasRadiGoPermission(username string, serverId string) (bool, error) {
+	hasPermission, err := UserHasRadiGoPermissionQuery(username, serverId)
+	if err != nil {
+		return false, err
+	}
+	if hasPermission {
+		return true, nil
+	}
+	// If there is no matching permission, check for the '* | modifyRooms' permissions
+	return UserHasPermission(username, PermissionModifyRooms)
+}
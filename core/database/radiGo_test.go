@@ -0,0 +1,71 @@
+package database
+
+import "testing"
+
+func TestRadiGoPermissions(t *testing.T) {
+	if err := AddUser(FullUser{
+		Username: "radigo_test",
+		Password: "test",
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := CreateRadiGoServer(RadiGoServer{
+		Id:    "radigo_test",
+		Name:  "Test",
+		Url:   "http://localhost:8080",
+		Token: "secret",
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	server, found, err := GetRadiGoServerById("radigo_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || server.Token != "secret" {
+		t.Errorf("Unexpected server: %v (found: %t)", server, found)
+		return
+	}
+	hasPermission, err := UserHasRadiGoPermission("radigo_test", "radigo_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if hasPermission {
+		t.Error("User has permission without a grant")
+		return
+	}
+	if err := AddUserRadiGoPermission("radigo_test", "radigo_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	servers, err := ListUserRadiGoServers("radigo_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(servers) != 1 || servers[0].Id != "radigo_test" {
+		t.Errorf("Unexpected personal servers: %v", servers)
+		return
+	}
+	// Deleting the server removes its permissions
+	if err := DeleteRadiGoServer("radigo_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	hasPermission, err = UserHasRadiGoPermissionQuery("radigo_test", "radigo_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if hasPermission {
+		t.Error("Permission was not removed with the server")
+		return
+	}
+	if err := DeleteUser("radigo_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
@@ -209,6 +209,11 @@ func ListPersonalRoomsWithData(username string) ([]Room, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Get the pending power timers in order to display them on the switches
+	timers, err := GetPowerTimers()
+	if err != nil {
+		return nil, err
+	}
 	// Get the user's cameras
 	cameras, err := ListUserCameras(username)
 	if err != nil {
@@ -222,6 +227,7 @@ func ListPersonalRoomsWithData(username string) ([]Room, error) {
 		// Add every switch which is in the current room
 		for _, switchItem := range switches {
 			if switchItem.RoomId == room.Id {
+				switchItem.Timers = filterSwitchPowerTimers(timers, switchItem.Id)
 				switchesTemp = append(switchesTemp, switchItem)
 			}
 		}
@@ -252,6 +258,11 @@ func ListAllRoomsWithData() ([]Room, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Get the pending power timers in order to display them on the switches
+	timers, err := GetPowerTimers()
+	if err != nil {
+		return nil, err
+	}
 	// Get all cameras
 	cameras, err := ListCameras()
 	if err != nil {
@@ -264,6 +275,7 @@ func ListAllRoomsWithData() ([]Room, error) {
 		// Add all switches of the current room
 		for _, switchItem := range switches {
 			if switchItem.RoomId == room.Id {
+				switchItem.Timers = filterSwitchPowerTimers(timers, switchItem.Id)
 				switchesTemp = append(switchesTemp, switchItem)
 			}
 		}
@@ -282,10 +294,24 @@ func ListAllRoomsWithData() ([]Room, error) {
 	return outputRooms, nil
 }
 
+// Returns the power timers which address the given switch
+func filterSwitchPowerTimers(timers []PowerTimer, switchId string) []PowerTimer {
+	switchTimers := make([]PowerTimer, 0)
+	for _, timer := range timers {
+		if timer.SwitchId == switchId {
+			switchTimers = append(switchTimers, timer)
+		}
+	}
+	return switchTimers
+}
+
 func DeleteRoom(id string) error {
 	if err := DeleteRoomSwitches(id); err != nil {
 		return err
 	}
+	if err := DeleteEnergyBudgetsOfTarget(EnergyBudgetRoom, id); err != nil {
+		return err
+	}
 	if err := DeleteRoomCameras(id); err != nil {
 		return err
 	}
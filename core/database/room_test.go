@@ -81,12 +81,7 @@ func TestListRooms(t *testing.T) {
 		},
 	}
 	for _, switchItem := range switches {
-		if err := CreateSwitch(
-			switchItem.Id,
-			switchItem.Name,
-			switchItem.RoomId,
-			switchItem.Watts,
-		); err != nil {
+		if err := CreateSwitch(switchItem); err != nil {
 			t.Error(err.Error())
 		}
 		if _, err := AddUserSwitchPermission("admin", switchItem.Id); err != nil {
@@ -12,6 +12,49 @@ type Switch struct {
 	RoomId  string `json:"roomId"`
 	PowerOn bool   `json:"powerOn"`
 	Watts   uint16 `json:"watts"`
+	KeepOn  bool   `json:"keepOn"` // If set, the switch is skipped by room-wide and global power-off actions
+	// Display metadata which is used by the frontend
+	Category  SwitchCategory `json:"category"`
+	Icon      string         `json:"icon"`      // Key of the icon which is displayed instead of the category's default icon
+	SortIndex int            `json:"sortIndex"` // Switches are listed in ascending order of their sort index
+	Hidden    bool           `json:"hidden"`    // If set, the switch is not displayed on the dashboard
+	// Pending timers which will change the power state of this switch, only populated in room listings
+	Timers []PowerTimer `json:"timers,omitempty"`
+}
+
+// Describes which kind of device is connected to a switch
+type SwitchCategory string
+
+const (
+	SwitchCategoryOther     SwitchCategory = "other"
+	SwitchCategoryLight     SwitchCategory = "light"
+	SwitchCategoryOutlet    SwitchCategory = "outlet"
+	SwitchCategoryFan       SwitchCategory = "fan"
+	SwitchCategoryHeater    SwitchCategory = "heater"
+	SwitchCategoryMedia     SwitchCategory = "media"
+	SwitchCategoryComputer  SwitchCategory = "computer"
+	SwitchCategoryAppliance SwitchCategory = "appliance"
+)
+
+var SwitchCategories = []SwitchCategory{
+	SwitchCategoryOther,
+	SwitchCategoryLight,
+	SwitchCategoryOutlet,
+	SwitchCategoryFan,
+	SwitchCategoryHeater,
+	SwitchCategoryMedia,
+	SwitchCategoryComputer,
+	SwitchCategoryAppliance,
+}
+
+// Returns whether the given category is a known switch category
+func IsValidSwitchCategory(category SwitchCategory) bool {
+	for _, item := range SwitchCategories {
+		if item == category {
+			return true
+		}
+	}
+	return false
 }
 
 //Contains the switch id and a matching boolean
@@ -33,6 +76,11 @@ func createSwitchTable() error {
 		Power BOOLEAN DEFAULT FALSE,
 		RoomId VARCHAR(30),
 		Watts INT,
+		KeepOn BOOLEAN DEFAULT FALSE,
+		Category VARCHAR(20) DEFAULT 'other',
+		Icon VARCHAR(50) DEFAULT '',
+		SortIndex INT DEFAULT 0,
+		Hidden BOOLEAN DEFAULT FALSE,
 		FOREIGN KEY (RoomId)
 		REFERENCES room(Id)
 	) 
@@ -42,30 +90,62 @@ func createSwitchTable() error {
 		log.Error("Failed to create switch Table: Executing query failed: ", err.Error())
 		return err
 	}
+	// Older databases were created without the `KeepOn` and display metadata columns
+	if _, err := db.Exec(`
+	ALTER TABLE switch
+	ADD COLUMN IF NOT EXISTS KeepOn BOOLEAN DEFAULT FALSE,
+	ADD COLUMN IF NOT EXISTS Category VARCHAR(20) DEFAULT 'other',
+	ADD COLUMN IF NOT EXISTS Icon VARCHAR(50) DEFAULT '',
+	ADD COLUMN IF NOT EXISTS SortIndex INT DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS Hidden BOOLEAN DEFAULT FALSE
+	`); err != nil {
+		log.Error("Failed to migrate switch table: adding missing columns failed: ", err.Error())
+		return err
+	}
 	return nil
 }
 
 // Creates a new switch
 // Will return an error if the database fails
-func CreateSwitch(id string, name string, roomId string, watts uint16) error {
+// The power state of the provided switch is ignored
+// A switch without a category is assigned the category `other`
+func CreateSwitch(switchItem Switch) error {
+	if switchItem.Category == "" {
+		switchItem.Category = SwitchCategoryOther
+	}
 	query, err := db.Prepare(`
 	INSERT INTO
 	switch(
-		Id, Name, Power, RoomId, Watts
+		Id, Name, Power, RoomId, Watts, KeepOn, Category, Icon, SortIndex, Hidden
 	)
-	VALUES(?, ?, DEFAULT, ?, ?)
+	VALUES(?, ?, DEFAULT, ?, ?, ?, ?, ?, ?, ?)
 	ON DUPLICATE KEY
 		UPDATE
 		Name=VALUES(Name),
 		RoomId=VALUES(RoomId),
-		Watts=VALUES(Watts)
+		Watts=VALUES(Watts),
+		KeepOn=VALUES(KeepOn),
+		Category=VALUES(Category),
+		Icon=VALUES(Icon),
+		SortIndex=VALUES(SortIndex),
+		Hidden=VALUES(Hidden)
 	`)
 	if err != nil {
 		log.Error("Failed to add switch: preparing query failed: ", err.Error())
 		return err
 	}
 	defer query.Close()
-	res, err := query.Exec(id, name, roomId, watts)
+	res, err := query.Exec(
+		switchItem.Id,
+		switchItem.Name,
+		switchItem.RoomId,
+		switchItem.Watts,
+		switchItem.KeepOn,
+		switchItem.Category,
+		switchItem.Icon,
+		switchItem.SortIndex,
+		switchItem.Hidden,
+	)
 	if err != nil {
 		log.Error("Failed to add switch: executing query failed: ", err.Error())
 		return err
@@ -76,25 +156,44 @@ func CreateSwitch(id string, name string, roomId string, watts uint16) error {
 		return err
 	}
 	if rowsAffected > 0 {
-		log.Debug(fmt.Sprintf("Added switch `%s` with name `%s`", id, name))
+		log.Debug(fmt.Sprintf("Added switch `%s` with name `%s`", switchItem.Id, switchItem.Name))
 	}
 	return nil
 }
 
 // Modifies the metadata of a given switch
-func ModifySwitch(id string, name string, watts uint16) error {
+// The id, room and power state of the provided switch are ignored
+// A switch without a category is assigned the category `other`
+func ModifySwitch(id string, newItem Switch) error {
+	if newItem.Category == "" {
+		newItem.Category = SwitchCategoryOther
+	}
 	query, err := db.Prepare(`
 	UPDATE switch
 	SET
 		Name=?,
-		Watts=?
+		Watts=?,
+		KeepOn=?,
+		Category=?,
+		Icon=?,
+		SortIndex=?,
+		Hidden=?
 	WHERE Id=?
 	`)
 	if err != nil {
 		log.Error("Failed to modify switch: preparing query failed: ", err.Error())
 		return err
 	}
-	if _, err := query.Exec(name, watts, id); err != nil {
+	if _, err := query.Exec(
+		newItem.Name,
+		newItem.Watts,
+		newItem.KeepOn,
+		newItem.Category,
+		newItem.Icon,
+		newItem.SortIndex,
+		newItem.Hidden,
+		id,
+	); err != nil {
 		log.Error("Failed to modify switch: executing query failed: ", err.Error())
 		return err
 	}
@@ -106,6 +205,18 @@ func DeleteSwitch(switchId string) error {
 	if err := RemoveSwitchFromPermissions(switchId); err != nil {
 		return err
 	}
+	if err := DeleteSwitchPowerTimers(switchId); err != nil {
+		return err
+	}
+	if err := DeleteNetworkDevice(switchId); err != nil {
+		return err
+	}
+	if err := DeleteSwitchEnergyUsage(switchId); err != nil {
+		return err
+	}
+	if err := DeleteEnergyBudgetsOfTarget(EnergyBudgetSwitch, switchId); err != nil {
+		return err
+	}
 	query, err := db.Prepare(`
 	DELETE FROM
 	switch
@@ -149,8 +260,14 @@ func ListSwitches() ([]Switch, error) {
 		Name,
 		Power,
 		RoomId,
-		Watts
+		Watts,
+		KeepOn,
+		Category,
+		Icon,
+		SortIndex,
+		Hidden
 	FROM switch
+	ORDER BY SortIndex, Name
 	`)
 	if err != nil {
 		log.Error("Could not list switches: failed to execute query: ", err.Error())
@@ -166,6 +283,11 @@ func ListSwitches() ([]Switch, error) {
 			&switchItem.PowerOn,
 			&switchItem.RoomId,
 			&switchItem.Watts,
+			&switchItem.KeepOn,
+			&switchItem.Category,
+			&switchItem.Icon,
+			&switchItem.SortIndex,
+			&switchItem.Hidden,
 		); err != nil {
 			log.Error("Could not list switches: Failed to scan results: ", err.Error())
 			return nil, err
@@ -184,11 +306,17 @@ func ListUserSwitchesQuery(username string) ([]Switch, error) {
 		Name,
 		RoomId,
 		Power,
-		Watts
+		Watts,
+		KeepOn,
+		Category,
+		Icon,
+		SortIndex,
+		Hidden
 	FROM switch
 	JOIN hasSwitchPermission
 	ON hasSwitchPermission.Switch=switch.Id
-	WHERE hasSwitchPermission.Username=?`,
+	WHERE hasSwitchPermission.Username=?
+	ORDER BY SortIndex, Name`,
 	)
 	if err != nil {
 		log.Error("Could not list user switches: preparing query failed: ", err.Error())
@@ -209,6 +337,11 @@ func ListUserSwitchesQuery(username string) ([]Switch, error) {
 			&switchItem.RoomId,
 			&switchItem.PowerOn,
 			&switchItem.Watts,
+			&switchItem.KeepOn,
+			&switchItem.Category,
+			&switchItem.Icon,
+			&switchItem.SortIndex,
+			&switchItem.Hidden,
 		); err != nil {
 			log.Error("Could not list user switches: Failed to scan results: ", err.Error())
 			return nil, err
@@ -237,7 +370,12 @@ func GetSwitchById(id string) (Switch, bool, error) {
 		Name,
 		RoomId,
 		Power,
-		Watts
+		Watts,
+		KeepOn,
+		Category,
+		Icon,
+		SortIndex,
+		Hidden
 	FROM switch
 	WHERE Id=?
 	`)
@@ -252,6 +390,11 @@ func GetSwitchById(id string) (Switch, bool, error) {
 		&switchItem.RoomId,
 		&switchItem.PowerOn,
 		&switchItem.Watts,
+		&switchItem.KeepOn,
+		&switchItem.Category,
+		&switchItem.Icon,
+		&switchItem.SortIndex,
+		&switchItem.Hidden,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return Switch{}, false, nil
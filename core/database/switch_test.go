@@ -68,12 +68,7 @@ func TestSwitches(t *testing.T) {
 	}
 	for _, test := range table {
 		t.Run(fmt.Sprintf("create switch/%s", test.Switch.Id), func(t *testing.T) {
-			if err := CreateSwitch(
-				test.Switch.Id,
-				test.Switch.Name,
-				test.Switch.RoomId,
-				test.Switch.Watts,
-			); err != nil {
+			if err := CreateSwitch(test.Switch); err != nil {
 				if !strings.Contains(err.Error(), test.Error) || test.Error == "" {
 					t.Errorf("Unexpected error: want: %s got: %s ", test.Error, err.Error())
 					return
@@ -192,12 +187,7 @@ func TestUserSwitches(t *testing.T) {
 	t.Run("create switches", func(t *testing.T) {
 		for _, switchItem := range switches {
 			t.Run(fmt.Sprintf("create switches/%s", switchItem.Id), func(t *testing.T) {
-				if err := CreateSwitch(
-					switchItem.Id,
-					switchItem.Name,
-					switchItem.RoomId,
-					switchItem.Watts,
-				); err != nil {
+				if err := CreateSwitch(switchItem); err != nil {
 					t.Error(err.Error())
 					return
 				}
@@ -306,12 +296,12 @@ func TestDoesSwitchExist(t *testing.T) {
 		t.Error(err.Error())
 		return
 	}
-	if err := CreateSwitch(
-		"test1",
-		"test1",
-		"test",
-		1,
-	); err != nil {
+	if err := CreateSwitch(Switch{
+		Id:     "test1",
+		Name:   "test1",
+		RoomId: "test",
+		Watts:  1,
+	}); err != nil {
 		t.Error(err.Error())
 		return
 	}
@@ -339,45 +329,48 @@ func TestModifySwitch(t *testing.T) {
 	}{
 		{
 			Origin: Switch{
-				Id:      "test_1",
-				Name:    "Test 1",
-				RoomId:  "test",
-				Watts:   0,
-				PowerOn: false, // Power is set to false because the power state is not modified
+				Id:       "test_1",
+				Name:     "Test 1",
+				RoomId:   "test",
+				Watts:    0,
+				PowerOn:  false, // Power is set to false because the power state is not modified
+				Category: SwitchCategoryOther,
 			},
 			Modified: Switch{
-				Id:      "test_1",
-				Name:    "Test 1-2",
-				RoomId:  "test",
-				Watts:   1,
-				PowerOn: false,
+				Id:        "test_1",
+				Name:      "Test 1-2",
+				RoomId:    "test",
+				Watts:     1,
+				PowerOn:   false,
+				KeepOn:    true,
+				Category:  SwitchCategoryLight,
+				Icon:      "lamp",
+				SortIndex: 2,
+				Hidden:    true,
 			},
 		},
 		{
 			Origin: Switch{
-				Id:      "test_2",
-				Name:    "Test 2",
-				RoomId:  "test",
-				Watts:   2,
-				PowerOn: false,
+				Id:       "test_2",
+				Name:     "Test 2",
+				RoomId:   "test",
+				Watts:    2,
+				PowerOn:  false,
+				Category: SwitchCategoryOther,
 			},
 			Modified: Switch{
-				Id:      "test_2",
-				Name:    "Test 2-2",
-				RoomId:  "test",
-				Watts:   3,
-				PowerOn: false,
+				Id:       "test_2",
+				Name:     "Test 2-2",
+				RoomId:   "test",
+				Watts:    3,
+				PowerOn:  false,
+				Category: SwitchCategoryOther,
 			},
 		},
 	}
 	for _, test := range table {
 		// Create Switch
-		if err := CreateSwitch(
-			test.Origin.Id,
-			test.Origin.Name,
-			test.Origin.RoomId,
-			test.Origin.Watts,
-		); err != nil {
+		if err := CreateSwitch(test.Origin); err != nil {
 			t.Error(err.Error())
 		}
 
@@ -390,11 +383,7 @@ func TestModifySwitch(t *testing.T) {
 		assert.Equal(t, test.Origin, switchDb, "Created switch does not match origin")
 
 		// Modify Switch
-		if err := ModifySwitch(
-			test.Origin.Id,
-			test.Modified.Name,
-			test.Modified.Watts,
-		); err != nil {
+		if err := ModifySwitch(test.Origin.Id, test.Modified); err != nil {
 			t.Error(err.Error())
 		}
 
@@ -415,3 +404,70 @@ func TestModifySwitch(t *testing.T) {
 }
 
 // TODO: add method which tests user switches with modifyRoom permission and powertStates
+
+func TestSwitchDefaultCategory(t *testing.T) {
+	if err := CreateRoom(RoomData{Id: "category_test"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := CreateSwitch(Switch{Id: "category_1", Name: "category_1", RoomId: "category_test"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	switchItem, found, err := GetSwitchById("category_1")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || switchItem.Category != SwitchCategoryOther {
+		t.Errorf("Switch without category was not assigned the default category: found: %t got: %s", found, switchItem.Category)
+		return
+	}
+	if err := ModifySwitch("category_1", Switch{Name: "category_1", Category: SwitchCategoryLight}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := ModifySwitch("category_1", Switch{Name: "category_1"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	switchItem, _, err = GetSwitchById("category_1")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if switchItem.Category != SwitchCategoryOther {
+		t.Errorf("Modified switch without category was not assigned the default category: got: %s", switchItem.Category)
+		return
+	}
+}
+
+func TestSwitchOrder(t *testing.T) {
+	if err := CreateRoom(RoomData{Id: "order_test"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	// Switches are sorted by their sort index first and by their name second
+	for _, switchItem := range []Switch{
+		{Id: "order_3", Name: "b", RoomId: "order_test", SortIndex: 1},
+		{Id: "order_1", Name: "c", RoomId: "order_test", SortIndex: 0},
+		{Id: "order_2", Name: "a", RoomId: "order_test", SortIndex: 1},
+	} {
+		if err := CreateSwitch(switchItem); err != nil {
+			t.Error(err.Error())
+			return
+		}
+	}
+	switches, err := ListSwitches()
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	order := make([]string, 0)
+	for _, switchItem := range switches {
+		if switchItem.RoomId == "order_test" {
+			order = append(order, switchItem.Id)
+		}
+	}
+	assert.Equal(t, []string{"order_1", "order_2", "order_3"}, order, "Switches are not sorted by sort index and name")
+}
@@ -175,18 +175,39 @@ func DeleteUser(username string) error {
 	if err := RemoveAllSwitchPermissionsOfUser(username); err != nil {
 		return err
 	}
+	if err := RemoveAllRadiGoPermissionsOfUser(username); err != nil {
+		return err
+	}
 	if err := DeleteAllNotificationsFromUser(username); err != nil {
 		return err
 	}
 	if err := DeleteAllAutomationsFromUser(username); err != nil {
 		return err
 	}
+	if err := DeleteAllHomescriptSharesOfUser(username); err != nil {
+		return err
+	}
+	if err := RemoveUserFromAllGroups(username); err != nil {
+		return err
+	}
 	if err := DeleteAllHomescriptsOfUser(username); err != nil {
 		return err
 	}
 	if err := DeleteAllSchedulesFromUser(username); err != nil {
 		return err
 	}
+	if err := DeleteAllPowerTimersFromUser(username); err != nil {
+		return err
+	}
+	if err := DeleteAllEnergyBudgetsFromUser(username); err != nil {
+		return err
+	}
+	if err := DeleteAllHomescriptRunsFromUser(username); err != nil {
+		return err
+	}
+	if err := DeleteAllStorageEntriesOfUser(username); err != nil {
+		return err
+	}
 	query, err := db.Prepare(`
 	DELETE FROM user WHERE Username=?
 	`)
@@ -0,0 +1,261 @@
+package database
+
+import "database/sql"
+
+// A named set of users, is used to share resources with multiple users at once
+type UserGroup struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Members     []string `json:"members"`
+}
+
+// Creates the tables containing user groups and their members
+// If the database fails, this function returns an error
+func createUserGroupTables() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	userGroup(
+		Id VARCHAR(30) PRIMARY KEY,
+		Name VARCHAR(50),
+		Description TEXT
+	)
+	`); err != nil {
+		log.Error("Failed to create user group table: executing query failed: ", err.Error())
+		return err
+	}
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	userGroupMember(
+		GroupId VARCHAR(30),
+		Username VARCHAR(20),
+		PRIMARY KEY (GroupId, Username),
+		CONSTRAINT UserGroupMemberGroup
+		FOREIGN KEY (GroupId)
+		REFERENCES userGroup(Id),
+		CONSTRAINT UserGroupMemberUsername
+		FOREIGN KEY (Username)
+		REFERENCES user(Username)
+	)
+	`); err != nil {
+		log.Error("Failed to create user group member table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Creates a new user group without members
+func CreateUserGroup(group UserGroup) error {
+	query, err := db.Prepare(`
+	INSERT INTO
+	userGroup(
+		Id,
+		Name,
+		Description
+	)
+	VALUES(?, ?, ?)
+	`)
+	if err != nil {
+		log.Error("Failed to create user group: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(group.Id, group.Name, group.Description); err != nil {
+		log.Error("Failed to create user group: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Returns a user group and its members given its id
+func GetUserGroupById(id string) (UserGroup, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, Name, Description
+	FROM userGroup
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to get user group by id: preparing query failed: ", err.Error())
+		return UserGroup{}, false, err
+	}
+	defer query.Close()
+	var group UserGroup
+	if err := query.QueryRow(id).Scan(
+		&group.Id,
+		&group.Name,
+		&group.Description,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return UserGroup{}, false, nil
+		}
+		log.Error("Failed to get user group by id: executing query failed: ", err.Error())
+		return UserGroup{}, false, err
+	}
+	members, err := ListUserGroupMembers(id)
+	if err != nil {
+		return UserGroup{}, false, err
+	}
+	group.Members = members
+	return group, true, nil
+}
+
+// Returns all user groups and their members
+func ListUserGroups() ([]UserGroup, error) {
+	res, err := db.Query(`
+	SELECT
+	Id, Name, Description
+	FROM userGroup
+	`)
+	if err != nil {
+		log.Error("Failed to list user groups: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	groups := make([]UserGroup, 0)
+	for res.Next() {
+		var group UserGroup
+		if err := res.Scan(
+			&group.Id,
+			&group.Name,
+			&group.Description,
+		); err != nil {
+			log.Error("Failed to list user groups: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	for index := range groups {
+		members, err := ListUserGroupMembers(groups[index].Id)
+		if err != nil {
+			return nil, err
+		}
+		groups[index].Members = members
+	}
+	return groups, nil
+}
+
+// Returns the usernames of the members of a given group
+func ListUserGroupMembers(groupId string) ([]string, error) {
+	query, err := db.Prepare(`
+	SELECT Username
+	FROM userGroupMember
+	WHERE GroupId=?
+	`)
+	if err != nil {
+		log.Error("Failed to list user group members: preparing query failed: ", err.Error())
+		return nil, err
+	}
+	defer query.Close()
+	res, err := query.Query(groupId)
+	if err != nil {
+		log.Error("Failed to list user group members: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	members := make([]string, 0)
+	for res.Next() {
+		var member string
+		if err := res.Scan(&member); err != nil {
+			log.Error("Failed to list user group members: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// Adds a user to a group, does nothing if the user is already a member
+func AddUserGroupMember(groupId string, username string) error {
+	query, err := db.Prepare(`
+	INSERT IGNORE INTO
+	userGroupMember(
+		GroupId,
+		Username
+	)
+	VALUES(?, ?)
+	`)
+	if err != nil {
+		log.Error("Failed to add user group member: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(groupId, username); err != nil {
+		log.Error("Failed to add user group member: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Removes a user from a group
+func RemoveUserGroupMember(groupId string, username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM userGroupMember
+	WHERE GroupId=?
+	AND Username=?
+	`)
+	if err != nil {
+		log.Error("Failed to remove user group member: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(groupId, username); err != nil {
+		log.Error("Failed to remove user group member: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Removes a user from all groups
+func RemoveUserFromAllGroups(username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM userGroupMember
+	WHERE Username=?
+	`)
+	if err != nil {
+		log.Error("Failed to remove user from all groups: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username); err != nil {
+		log.Error("Failed to remove user from all groups: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes a user group, its memberships and everything which has been shared with it
+func DeleteUserGroup(id string) error {
+	if err := DeleteAllHomescriptSharesOfGroup(id); err != nil {
+		return err
+	}
+	query, err := db.Prepare(`
+	DELETE FROM userGroupMember
+	WHERE GroupId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete user group: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(id); err != nil {
+		log.Error("Failed to delete user group: executing query failed: ", err.Error())
+		return err
+	}
+	query, err = db.Prepare(`
+	DELETE FROM userGroup
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete user group: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(id); err != nil {
+		log.Error("Failed to delete user group: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
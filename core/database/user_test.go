@@ -30,7 +30,7 @@ func createUserMockData() error {
 		return err
 	}
 	// Create a switch
-	if err := CreateSwitch("delete_me", "", "delete_me", 0); err != nil {
+	if err := CreateSwitch(Switch{Id: "delete_me", RoomId: "delete_me"}); err != nil {
 		return err
 	}
 	// Give the user switch permission
@@ -0,0 +1,261 @@
+package database
+
+import "database/sql"
+
+// An incoming webhook which runs a Homescript as its owner
+// The secret token is only stored as a SHA-256 hash
+type Webhook struct {
+	Id           uint   `json:"id"`
+	Name         string `json:"name"`
+	Owner        string `json:"owner"`
+	HomescriptId string `json:"homescriptId"`
+	TokenHash    string `json:"-"`
+	Enabled      bool   `json:"enabled"`
+	RateLimit    uint   `json:"rateLimit"` // Maximum amount of calls per minute
+}
+
+// Creates the table containing webhooks
+// If the database fails, this function returns an error
+func createWebhookTable() error {
+	if _, err := db.Exec(`
+	CREATE TABLE
+	IF NOT EXISTS
+	webhook(
+		Id INT AUTO_INCREMENT PRIMARY KEY,
+		Name VARCHAR(30),
+		Owner VARCHAR(20),
+		HomescriptId VARCHAR(30),
+		TokenHash CHAR(64) UNIQUE,
+		Enabled BOOLEAN DEFAULT TRUE,
+		RateLimit INT UNSIGNED DEFAULT 10,
+		CONSTRAINT WebhookOwner
+		FOREIGN KEY (Owner)
+		REFERENCES user(Username),
+		CONSTRAINT WebhookHomescript
+		FOREIGN KEY (HomescriptId)
+		REFERENCES homescript(Id)
+	)
+	`); err != nil {
+		log.Error("Failed to create webhook table: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Creates a new webhook and returns its id
+func CreateWebhook(webhook Webhook) (uint, error) {
+	query, err := db.Prepare(`
+	INSERT INTO
+	webhook(
+		Name,
+		Owner,
+		HomescriptId,
+		TokenHash,
+		Enabled,
+		RateLimit
+	)
+	VALUES(?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		log.Error("Failed to create webhook: preparing query failed: ", err.Error())
+		return 0, err
+	}
+	defer query.Close()
+	res, err := query.Exec(
+		webhook.Name,
+		webhook.Owner,
+		webhook.HomescriptId,
+		webhook.TokenHash,
+		webhook.Enabled,
+		webhook.RateLimit,
+	)
+	if err != nil {
+		log.Error("Failed to create webhook: executing query failed: ", err.Error())
+		return 0, err
+	}
+	newId, err := res.LastInsertId()
+	if err != nil {
+		log.Error("Failed to create webhook: retrieving last insert id failed: ", err.Error())
+		return 0, err
+	}
+	return uint(newId), nil
+}
+
+// Returns a webhook given its id
+func GetWebhookById(id uint) (Webhook, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, Name, Owner, HomescriptId, TokenHash, Enabled, RateLimit
+	FROM webhook
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to get webhook by id: preparing query failed: ", err.Error())
+		return Webhook{}, false, err
+	}
+	defer query.Close()
+	var webhook Webhook
+	if err := query.QueryRow(id).Scan(
+		&webhook.Id,
+		&webhook.Name,
+		&webhook.Owner,
+		&webhook.HomescriptId,
+		&webhook.TokenHash,
+		&webhook.Enabled,
+		&webhook.RateLimit,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return Webhook{}, false, nil
+		}
+		log.Error("Failed to get webhook by id: executing query failed: ", err.Error())
+		return Webhook{}, false, err
+	}
+	return webhook, true, nil
+}
+
+// Returns a webhook given the hash of its token
+func GetWebhookByTokenHash(tokenHash string) (Webhook, bool, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, Name, Owner, HomescriptId, TokenHash, Enabled, RateLimit
+	FROM webhook
+	WHERE TokenHash=?
+	`)
+	if err != nil {
+		log.Error("Failed to get webhook by token: preparing query failed: ", err.Error())
+		return Webhook{}, false, err
+	}
+	defer query.Close()
+	var webhook Webhook
+	if err := query.QueryRow(tokenHash).Scan(
+		&webhook.Id,
+		&webhook.Name,
+		&webhook.Owner,
+		&webhook.HomescriptId,
+		&webhook.TokenHash,
+		&webhook.Enabled,
+		&webhook.RateLimit,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return Webhook{}, false, nil
+		}
+		log.Error("Failed to get webhook by token: executing query failed: ", err.Error())
+		return Webhook{}, false, err
+	}
+	return webhook, true, nil
+}
+
+// Returns the webhooks of a given user
+func GetUserWebhooks(username string) ([]Webhook, error) {
+	query, err := db.Prepare(`
+	SELECT
+	Id, Name, Owner, HomescriptId, TokenHash, Enabled, RateLimit
+	FROM webhook
+	WHERE Owner=?
+	`)
+	if err != nil {
+		log.Error("Failed to list webhooks of user: preparing query failed: ", err.Error())
+		return nil, err
+	}
+	defer query.Close()
+	res, err := query.Query(username)
+	if err != nil {
+		log.Error("Failed to list webhooks of user: executing query failed: ", err.Error())
+		return nil, err
+	}
+	defer res.Close()
+	webhooks := make([]Webhook, 0)
+	for res.Next() {
+		var webhook Webhook
+		if err := res.Scan(
+			&webhook.Id,
+			&webhook.Name,
+			&webhook.Owner,
+			&webhook.HomescriptId,
+			&webhook.TokenHash,
+			&webhook.Enabled,
+			&webhook.RateLimit,
+		); err != nil {
+			log.Error("Failed to list webhooks of user: scanning results failed: ", err.Error())
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+// Changes the name, activation and rate limit of a webhook
+func ModifyWebhook(id uint, name string, enabled bool, rateLimit uint) error {
+	query, err := db.Prepare(`
+	UPDATE webhook
+	SET
+	Name=?,
+	Enabled=?,
+	RateLimit=?
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to modify webhook: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(name, enabled, rateLimit, id); err != nil {
+		log.Error("Failed to modify webhook: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes a webhook given its id
+func DeleteWebhookById(id uint) error {
+	query, err := db.Prepare(`
+	DELETE FROM webhook
+	WHERE Id=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete webhook: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(id); err != nil {
+		log.Error("Failed to delete webhook: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all webhooks which run a given Homescript
+func DeleteHomescriptWebhooks(homescriptId string) error {
+	query, err := db.Prepare(`
+	DELETE FROM webhook
+	WHERE HomescriptId=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete webhooks of Homescript: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(homescriptId); err != nil {
+		log.Error("Failed to delete webhooks of Homescript: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
+
+// Deletes all webhooks of a given user
+func DeleteAllWebhooksFromUser(username string) error {
+	query, err := db.Prepare(`
+	DELETE FROM webhook
+	WHERE Owner=?
+	`)
+	if err != nil {
+		log.Error("Failed to delete webhooks of user: preparing query failed: ", err.Error())
+		return err
+	}
+	defer query.Close()
+	if _, err := query.Exec(username); err != nil {
+		log.Error("Failed to delete webhooks of user: executing query failed: ", err.Error())
+		return err
+	}
+	return nil
+}
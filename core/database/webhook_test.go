@@ -0,0 +1,71 @@
+package database
+
+import "testing"
+
+func TestCreateWebhookTable(t *testing.T) {
+	if err := createWebhookTable(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
+
+// Tests the creation, modification and deletion of webhooks
+func TestWebhooks(t *testing.T) {
+	if err := CreateNewHomescript(Homescript{
+		Id:    "webhook_test",
+		Owner: "admin",
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	id, err := CreateWebhook(Webhook{
+		Name:         "doorbell",
+		Owner:        "admin",
+		HomescriptId: "webhook_test",
+		TokenHash:    "0000000000000000000000000000000000000000000000000000000000000000",
+		Enabled:      true,
+		RateLimit:    10,
+	})
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	webhook, found, err := GetWebhookByTokenHash("0000000000000000000000000000000000000000000000000000000000000000")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || webhook.Id != id || webhook.Name != "doorbell" || !webhook.Enabled || webhook.RateLimit != 10 {
+		t.Errorf("Webhook does not match its input after creation: got: %v", webhook)
+		return
+	}
+	if err := ModifyWebhook(id, "nas", false, 5); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	webhook, found, err = GetWebhookById(id)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found || webhook.Name != "nas" || webhook.Enabled || webhook.RateLimit != 5 {
+		t.Errorf("Webhook was not modified: got: %v", webhook)
+		return
+	}
+	// Deleting the Homescript also deletes its webhooks
+	if err := DeleteHomescriptById("webhook_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	webhooks, err := GetUserWebhooks("admin")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	for _, item := range webhooks {
+		if item.Id == id {
+			t.Errorf("Webhook %d still exists after its Homescript was deleted", id)
+			return
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Identifies the kind of an event which is published on the bus
+type Topic string
+
+const (
+	TopicSwitchChanged   Topic = "switchChanged"   // Payload: SwitchChanged
+	TopicNodeStatus      Topic = "nodeStatus"      // Payload: NodeStatus
+	TopicUserLogin       Topic = "userLogin"       // Payload: UserLogin
+	TopicReminderOverdue Topic = "reminderOverdue" // Payload: ReminderOverdue
+	TopicNotification    Topic = "notification"    // Payload: Notification
+	TopicAutomationRun   Topic = "automationRun"   // Payload: JobStatus
+	TopicScheduleRun     Topic = "scheduleRun"     // Payload: JobStatus
+	TopicHomescriptJob   Topic = "homescriptJob"   // Payload: HomescriptJob
+	TopicCameraChanged   Topic = "cameraChanged"   // Payload: CameraChanged
+)
+
+// Every payload which is published on the bus belongs to exactly one topic
+type Payload interface {
+	Topic() Topic
+}
+
+// The power state of a switch has changed
+type SwitchChanged struct {
+	SwitchId string `json:"switchId"`
+	PowerOn  bool   `json:"powerOn"`
+}
+
+// A hardware node went online or offline
+type NodeStatus struct {
+	Url    string `json:"url"`
+	Name   string `json:"name"`
+	Online bool   `json:"online"`
+}
+
+// A user has logged in successfully
+type UserLogin struct {
+	Username string `json:"username"`
+}
+
+// A reminder is overdue, is published at most once a day for each reminder
+type ReminderOverdue struct {
+	ReminderId uint   `json:"reminderId"`
+	Owner      string `json:"owner"`
+}
+
+// A notification has been sent to a user
+type Notification struct {
+	Username    string `json:"username"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Level       uint8  `json:"level"`
+}
+
+// Describes the progress of an automation or a schedule
+type JobStatus struct {
+	Kind   Topic     `json:"kind"` // Either TopicAutomationRun or TopicScheduleRun
+	Id     uint      `json:"id"`
+	Name   string    `json:"name"`
+	Owner  string    `json:"owner"`
+	Status RunStatus `json:"status"`
+	Error  string    `json:"error"`
+}
+
+// A Homescript job has been started or has finished
+type HomescriptJob struct {
+	Id          uint64 `json:"id"`
+	Owner       string `json:"owner"`
+	ScriptLabel string `json:"scriptLabel"`
+	Trigger     string `json:"trigger"`
+	Finished    bool   `json:"finished"`
+}
+
+// A camera has been created, modified or deleted
+type CameraChanged struct {
+	CameraId string `json:"cameraId"`
+	Deleted  bool   `json:"deleted"`
+}
+
+type RunStatus string
+
+const (
+	RunStarted   RunStatus = "started"
+	RunSucceeded RunStatus = "succeeded"
+	RunFailed    RunStatus = "failed"
+	RunSkipped   RunStatus = "skipped"
+)
+
+func (SwitchChanged) Topic() Topic   { return TopicSwitchChanged }
+func (NodeStatus) Topic() Topic      { return TopicNodeStatus }
+func (UserLogin) Topic() Topic       { return TopicUserLogin }
+func (ReminderOverdue) Topic() Topic { return TopicReminderOverdue }
+func (Notification) Topic() Topic    { return TopicNotification }
+func (self JobStatus) Topic() Topic  { return self.Kind }
+func (HomescriptJob) Topic() Topic   { return TopicHomescriptJob }
+func (CameraChanged) Topic() Topic   { return TopicCameraChanged }
+
+// A published payload together with its metadata
+type Message struct {
+	Topic   Topic
+	Payload Payload
+	Time    time.Time // The time at which the message was published
+}
+
+type Handler func(Message)
+
+// Determines what happens if the queue of an asynchronous subscriber is full
+type OverflowPolicy uint8
+
+const (
+	// The message is discarded immediately, the publisher is never slowed down
+	OverflowDrop OverflowPolicy = iota
+	// The publisher waits up to `blockTimeout` for free space before the message is discarded
+	OverflowBlock
+)
+
+// Maximum time a publisher is blocked by a single subscriber which uses `OverflowBlock`
+const blockTimeout = time.Second
+
+// A registered handler of a topic
+// Synchronous subscribers are called by the publisher, asynchronous subscribers process their queue in their own goroutine
+type Subscription struct {
+	topic   Topic
+	handler Handler
+	queue   chan Message // Is nil for synchronous subscribers
+	policy  OverflowPolicy
+	done    chan struct{}
+	once    sync.Once
+	dropped uint64
+}
+
+// Contains the subscriptions of every topic
+// Unlike log events, the messages on the bus are not persisted and only reach the current process
+var bus = struct {
+	m             sync.RWMutex
+	subscriptions map[Topic][]*Subscription
+}{
+	subscriptions: make(map[Topic][]*Subscription),
+}
+
+func addSubscription(subscription *Subscription) {
+	bus.m.Lock()
+	defer bus.m.Unlock()
+	bus.subscriptions[subscription.topic] = append(bus.subscriptions[subscription.topic], subscription)
+}
+
+// Registers a handler which is called by the publisher for every message of the given topic
+// Synchronous handlers delay the publisher and should therefore return quickly
+func Subscribe(topic Topic, handler Handler) *Subscription {
+	subscription := &Subscription{
+		topic:   topic,
+		handler: handler,
+		done:    make(chan struct{}),
+	}
+	addSubscription(subscription)
+	return subscription
+}
+
+// Registers a handler which processes the messages of the given topic one after another in its own goroutine
+// Up to `queueSize` messages are buffered, the policy decides what happens if the queue is full
+func SubscribeAsync(topic Topic, queueSize int, policy OverflowPolicy, handler Handler) *Subscription {
+	subscription := &Subscription{
+		topic:   topic,
+		handler: handler,
+		queue:   make(chan Message, queueSize),
+		policy:  policy,
+		done:    make(chan struct{}),
+	}
+	addSubscription(subscription)
+	go subscription.process()
+	return subscription
+}
+
+// Removes the subscription from the bus, messages which are still queued are discarded
+func (self *Subscription) Unsubscribe() {
+	self.once.Do(func() {
+		bus.m.Lock()
+		subscriptions := bus.subscriptions[self.topic]
+		for index, subscription := range subscriptions {
+			if subscription == self {
+				bus.subscriptions[self.topic] = append(subscriptions[:index:index], subscriptions[index+1:]...)
+				break
+			}
+		}
+		bus.m.Unlock()
+		close(self.done)
+	})
+}
+
+// Returns the amount of messages which were discarded because the queue was full
+func (self *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&self.dropped)
+}
+
+// Processes the queue of an asynchronous subscriber until it unsubscribes
+func (self *Subscription) process() {
+	for {
+		select {
+		case message := <-self.queue:
+			self.call(message)
+		case <-self.done:
+			return
+		}
+	}
+}
+
+// Calls the handler, a panicking handler must not affect the publisher or other subscribers
+func (self *Subscription) call(message Message) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Error(fmt.Sprintf("Subscriber of topic '%s' panicked: %v", self.topic, err))
+		}
+	}()
+	self.handler(message)
+}
+
+// Delivers a message to the subscriber, is called by the publisher
+func (self *Subscription) deliver(message Message) {
+	if self.queue == nil {
+		self.call(message)
+		return
+	}
+	select {
+	case self.queue <- message:
+		return
+	case <-self.done:
+		return
+	default:
+	}
+	if self.policy == OverflowBlock {
+		timer := time.NewTimer(blockTimeout)
+		defer timer.Stop()
+		select {
+		case self.queue <- message:
+			return
+		case <-self.done:
+			return
+		case <-timer.C:
+		}
+	}
+	if dropped := atomic.AddUint64(&self.dropped, 1); dropped == 1 || dropped%100 == 0 {
+		log.Warn(fmt.Sprintf("Subscriber of topic '%s' cannot keep up: %d message(s) have been dropped", self.topic, dropped))
+	}
+}
+
+// Publishes a payload to all subscribers of its topic
+// Synchronous subscribers are called before this function returns, asynchronous subscribers receive the message through their queue
+func Publish(payload Payload) {
+	message := Message{
+		Topic:   payload.Topic(),
+		Payload: payload,
+		Time:    time.Now(),
+	}
+	// The subscriptions are copied so that handlers can subscribe or unsubscribe without causing a deadlock
+	bus.m.RLock()
+	subscriptions := append([]*Subscription(nil), bus.subscriptions[message.Topic]...)
+	bus.m.RUnlock()
+	for _, subscription := range subscriptions {
+		subscription.deliver(message)
+	}
+}
@@ -0,0 +1,79 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishSync(t *testing.T) {
+	var received []Message
+	subscription := Subscribe(TopicUserLogin, func(message Message) {
+		received = append(received, message)
+	})
+	defer subscription.Unsubscribe()
+	Publish(UserLogin{Username: "admin"})
+	// Synchronous subscribers are called before `Publish` returns
+	if len(received) != 1 {
+		t.Errorf("Synchronous subscriber did not receive the message: want: 1 got: %d", len(received))
+		return
+	}
+	payload, ok := received[0].Payload.(UserLogin)
+	if received[0].Topic != TopicUserLogin || !ok || payload.Username != "admin" {
+		t.Errorf("Unexpected message: %v", received[0])
+		return
+	}
+	// Messages of other topics are not received
+	Publish(SwitchChanged{SwitchId: "s1", PowerOn: true})
+	if len(received) != 1 {
+		t.Errorf("Subscriber received a message of another topic")
+		return
+	}
+}
+
+func TestPublishAsync(t *testing.T) {
+	received := make(chan Message)
+	subscription := SubscribeAsync(TopicSwitchChanged, 1, OverflowBlock, func(message Message) {
+		received <- message
+	})
+	defer subscription.Unsubscribe()
+	Publish(SwitchChanged{SwitchId: "s1", PowerOn: true})
+	select {
+	case message := <-received:
+		if payload, ok := message.Payload.(SwitchChanged); !ok || payload.SwitchId != "s1" || !payload.PowerOn {
+			t.Errorf("Unexpected message: %v", message)
+		}
+	case <-time.After(time.Second):
+		t.Error("Asynchronous subscriber did not receive the published message")
+	}
+}
+
+func TestOverflowDrop(t *testing.T) {
+	release := make(chan struct{})
+	subscription := SubscribeAsync(TopicNodeStatus, 1, OverflowDrop, func(message Message) {
+		<-release
+	})
+	defer subscription.Unsubscribe()
+	// The first message is being processed, the second one is queued and the others are dropped
+	for i := 0; i < 5; i++ {
+		Publish(NodeStatus{Url: "http://localhost", Online: true})
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(release)
+	if dropped := subscription.Dropped(); dropped != 3 {
+		t.Errorf("Unexpected amount of dropped messages: want: 3 got: %d", dropped)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	calls := 0
+	subscription := Subscribe(TopicNotification, func(message Message) {
+		calls++
+	})
+	subscription.Unsubscribe()
+	// Unsubscribing twice has no effect
+	subscription.Unsubscribe()
+	Publish(Notification{Username: "admin"})
+	if calls != 0 {
+		t.Errorf("Handler was called after unsubscribing")
+	}
+}
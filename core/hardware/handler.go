@@ -49,23 +49,53 @@ var jobsWithErrorInHandlerCount atomic.Value
 // Time to be waited after each job (in milliseconds)
 const cooldown = 500
 
+// Is incremented for every job so that the ids of concurrently added jobs never collide
+var lastJobId int64
+
+func nextJobId() int64 {
+	return atomic.AddInt64(&lastJobId, 1)
+}
+
 // Main interface for interacting with the queuing system
 // Usage: SetPower("s1", true)
 // Waits until all jobs are completed, can return an error
 func SetPower(switchName string, powerOn bool) error {
-	uniqueId := time.Now().UnixNano()
+	uniqueId := nextJobId()
 	addJobToQueue(switchName, powerOn, uniqueId)
 	result := consumeResult(uniqueId)
 	return result.Error
 }
 
+// Like `SetPower` but adds all given jobs to the queue at once
+// This way, the daemon processes the jobs one after another without being restarted for each job
+// Waits until all jobs are completed, the results are returned in the order of the provided jobs
+func SetPowerMany(jobs []PowerRequest) []JobResult {
+	items := make([]PowerJob, 0)
+	for _, job := range jobs {
+		items = append(items, PowerJob{Switch: job.Switch, Power: job.Power, Id: nextJobId()})
+	}
+	addJobsToQueue(items)
+	results := make([]JobResult, 0)
+	for _, item := range items {
+		results = append(results, consumeResult(item.Id))
+	}
+	return results
+}
+
 // Used for adding a job to a queue, keeps track of daemons and spawns them if needed
 // Waits until the daemon quits, waiting for all (and the new) job(s) to be completed.
 func addJobToQueue(switchId string, turnOn bool, id int64) {
-	item := PowerJob{Switch: switchId, Power: turnOn, Id: id}
+	addJobsToQueue([]PowerJob{{Switch: switchId, Power: turnOn, Id: id}})
+}
 
+// Adds several jobs to the queue, keeps track of daemons and spawns them if needed
+// Waits until all of the new jobs have been completed
+func addJobsToQueue(items []PowerJob) {
+	if len(items) == 0 {
+		return
+	}
 	jobQueue.m.Lock()
-	jobQueue.JobQueue = append(jobQueue.JobQueue, item)
+	jobQueue.JobQueue = append(jobQueue.JobQueue, items...)
 	jobQueue.m.Unlock()
 
 	if !daemonRunning.Load().(bool) {
@@ -85,13 +115,13 @@ func addJobToQueue(switchId string, turnOn bool, id int64) {
 				return
 			default:
 				time.Sleep(time.Millisecond * 50)
-				if hasFinished(id) {
+				if haveFinished(items) {
 					return
 				}
 			}
 		}
 	} else {
-		for daemonRunning.Load().(bool) && !hasFinished(id) {
+		for daemonRunning.Load().(bool) && !haveFinished(items) {
 			time.Sleep(time.Millisecond * 50)
 		}
 	}
@@ -117,7 +147,7 @@ func jobDaemon(ch chan bool) {
 		jobQueue.m.RUnlock()
 
 		// Call the function which interacts with the hardware
-		err := setPowerOnAllNodes(currentJob.Switch, currentJob.Power)
+		err := setSwitchPower(currentJob.Switch, currentJob.Power)
 
 		jobResults.m.Lock()
 		jobResults.JobResults = append(jobResults.JobResults, JobResult{Id: currentJob.Id, Error: err})
@@ -189,6 +219,16 @@ func Init() {
 	daemonRunning.Store(false)
 }
 
+// Checks if all of the given jobs have finished
+func haveFinished(items []PowerJob) bool {
+	for _, item := range items {
+		if !hasFinished(item.Id) {
+			return false
+		}
+	}
+	return true
+}
+
 // Returns the number of currently pending jobs in the queue
 func GetPendingJobCount() int {
 	jobQueue.m.RLock()
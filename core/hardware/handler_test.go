@@ -83,7 +83,7 @@ func TestSetPower(t *testing.T) {
 		return
 	}
 	for _, req := range table {
-		if err := database.CreateSwitch(req.Switch, req.Switch, "test", 0); err != nil {
+		if err := database.CreateSwitch(database.Switch{Id: req.Switch, Name: req.Switch, RoomId: "test"}); err != nil {
 			t.Error(err.Error())
 			return
 		}
@@ -202,7 +202,7 @@ func TestSetPowerAsync(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			if err := database.CreateSwitch(req.Switch, req.Switch, "test", 0); err != nil {
+			if err := database.CreateSwitch(database.Switch{Id: req.Switch, Name: req.Switch, RoomId: "test"}); err != nil {
 				t.Error(err.Error())
 				return
 			}
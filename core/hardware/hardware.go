@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
 )
 
 type PowerJob struct {
@@ -39,10 +40,58 @@ func GetPowerState(switchId string) (bool, error) {
 	return switchItem.PowerOn, nil
 }
 
+// Is returned by power-on checks if a switch must not be turned on, for example because of an exceeded energy budget
+var ErrPowerOnRejected = errors.New("power-on rejected")
+
+// Is consulted before a switch is turned on, rejects the request by returning an error which wraps `ErrPowerOnRejected`
+type PowerOnCheck func(switchId string) error
+
+// Contains the checks of services which limit the usage of switches, is only modified during startup
+var powerOnChecks = make([]PowerOnCheck, 0)
+
+// Registers a check which is run before a switch is turned on
+// Must be called during startup, before any power requests are processed
+func RegisterPowerOnCheck(check PowerOnCheck) {
+	powerOnChecks = append(powerOnChecks, check)
+}
+
+// Returns an error if the switch must not be turned on
+// If a check rejects the request, the error wraps `ErrPowerOnRejected`
+func CheckPowerOn(switchId string) error {
+	for _, check := range powerOnChecks {
+		if err := check(switchId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Sets the powerstate of a specific switch
 // Checks if the switch exists
-// Checks if the user has all required permissions
+// Checks if the user has all required permissions and if the switch may be turned on
 func SetSwitchPowerAll(switchId string, powerOn bool, username string) error {
+	if err := ValidateSwitchPower(switchId, username); err != nil {
+		return err
+	}
+	if powerOn {
+		if err := CheckPowerOn(switchId); err != nil {
+			return fmt.Errorf("Failed to set power: %s", err.Error())
+		}
+	}
+	if err := SetPower(switchId, powerOn); err != nil {
+		return fmt.Errorf("Failed to set power: hardware error: %s", err.Error())
+	}
+	return nil
+}
+
+// Notifies the subscribers of the event bus that the power state of a switch has changed
+func publishSwitchChange(switchId string, powerOn bool) {
+	event.Publish(event.SwitchChanged{SwitchId: switchId, PowerOn: powerOn})
+}
+
+// Checks if the switch exists and if the user is allowed to change its power state
+// Returns an error which describes the first failed check
+func ValidateSwitchPower(switchId string, username string) error {
 	_, switchExists, err := database.GetSwitchById(switchId)
 	if err != nil {
 		return err
@@ -64,8 +113,78 @@ func SetSwitchPowerAll(switchId string, powerOn bool, username string) error {
 	if !userHasSwitchPermission {
 		return fmt.Errorf("Failed to set power: user is not allowed to interact with switch '%s'", switchId)
 	}
-	if err := SetPower(switchId, powerOn); err != nil {
-		return fmt.Errorf("Failed to set power: hardware error: %s", err.Error())
-	}
 	return nil
 }
+
+// Describes the outcome of a power action for a single switch
+// Used when the power of multiple switches is changed by one request
+type PowerActionResult struct {
+	Switch  string `json:"switch"`
+	Success bool   `json:"success"`
+	Skipped bool   `json:"skipped"` // Is set if the switch was not addressed, for example due to its `KeepOn` flag
+	Message string `json:"message"`
+}
+
+// Turns off every switch in a given room which the user is allowed to interact with
+// Returns false if the room does not exist, only switches which the user has permission to use are addressed
+// Switches which are marked as `KeepOn` or are already turned off are skipped
+func PowerOffRoom(roomId string, username string) ([]PowerActionResult, bool, error) {
+	_, roomExists, err := database.GetRoomDataById(roomId)
+	if err != nil {
+		return nil, false, err
+	}
+	if !roomExists {
+		return nil, false, nil
+	}
+	switches, err := database.ListUserSwitches(username)
+	if err != nil {
+		return nil, false, err
+	}
+	roomSwitches := make([]database.Switch, 0)
+	for _, switchItem := range switches {
+		if switchItem.RoomId == roomId {
+			roomSwitches = append(roomSwitches, switchItem)
+		}
+	}
+	return powerOffSwitches(roomSwitches), true, nil
+}
+
+// Turns off every switch in the house which the user is allowed to interact with
+// Switches which are marked as `KeepOn` or are already turned off are skipped
+func PowerOffAll(username string) ([]PowerActionResult, error) {
+	switches, err := database.ListUserSwitches(username)
+	if err != nil {
+		return nil, err
+	}
+	return powerOffSwitches(switches), nil
+}
+
+// Adds a power-off job for every eligible switch to the job queue at once and waits for their completion
+func powerOffSwitches(switches []database.Switch) []PowerActionResult {
+	results := make([]PowerActionResult, 0)
+	jobs := make([]PowerRequest, 0)
+	for _, switchItem := range switches {
+		if switchItem.KeepOn {
+			results = append(results, PowerActionResult{Switch: switchItem.Id, Success: true, Skipped: true, Message: "switch is marked as keep on"})
+			continue
+		}
+		if !switchItem.PowerOn {
+			results = append(results, PowerActionResult{Switch: switchItem.Id, Success: true, Skipped: true, Message: "switch is already turned off"})
+			continue
+		}
+		jobs = append(jobs, PowerRequest{Switch: switchItem.Id, Power: false})
+	}
+	for index, result := range SetPowerMany(jobs) {
+		if errors.Is(result.Error, ErrShutdownNotSupported) {
+			results = append(results, PowerActionResult{Switch: jobs[index].Switch, Success: true, Skipped: true, Message: "shutdown not supported"})
+			continue
+		}
+		if result.Error != nil {
+			log.Error(fmt.Sprintf("Failed to turn off switch '%s': %s", jobs[index].Switch, result.Error.Error()))
+			results = append(results, PowerActionResult{Switch: jobs[index].Switch, Success: false, Message: fmt.Sprintf("hardware error: %s", result.Error.Error())})
+			continue
+		}
+		results = append(results, PowerActionResult{Switch: jobs[index].Switch, Success: true, Message: "switch turned off"})
+	}
+	return results
+}
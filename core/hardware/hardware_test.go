@@ -1,6 +1,8 @@
 package hardware
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -69,7 +71,7 @@ func TestPower(t *testing.T) {
 		{"6", false},
 	}
 	for _, item := range table {
-		if err := database.CreateSwitch(item.Switch, item.Switch, "testing", 0); err != nil {
+		if err := database.CreateSwitch(database.Switch{Id: item.Switch, Name: item.Switch, RoomId: "testing"}); err != nil {
 			t.Error(err.Error())
 			return
 		}
@@ -150,3 +152,102 @@ func TestSendPowerRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestPowerOffRoom(t *testing.T) {
+	if err := database.CreateRoom(database.RoomData{Id: "power_off", Name: "power_off", Description: "power_off"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	table := []struct {
+		Switch        string
+		KeepOn        bool
+		PowerOn       bool
+		Skipped       bool
+		NetworkDevice bool // The switch is a network device which cannot be turned off
+	}{
+		{"power_off_1", false, true, false, false},
+		{"power_off_2", true, true, true, false},
+		{"power_off_3", false, false, true, false},
+		{"power_off_4", false, true, true, true},
+	}
+	for _, item := range table {
+		if err := database.CreateSwitch(database.Switch{Id: item.Switch, Name: item.Switch, RoomId: "power_off", KeepOn: item.KeepOn}); err != nil {
+			t.Error(err.Error())
+			return
+		}
+		if item.NetworkDevice {
+			if err := database.SetNetworkDevice(database.NetworkDevice{SwitchId: item.Switch, MacAddress: "00:11:22:33:44:55", StatusMode: database.NetworkStatusNone}); err != nil {
+				t.Error(err.Error())
+				return
+			}
+		}
+		if _, err := database.SetPowerState(item.Switch, item.PowerOn); err != nil {
+			t.Error(err.Error())
+			return
+		}
+	}
+	results, found, err := PowerOffRoom("power_off", "admin")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found {
+		t.Error("Room 'power_off' was not found")
+		return
+	}
+	if len(results) != len(table) {
+		t.Errorf("Invalid result count: want: %d got: %d", len(table), len(results))
+		return
+	}
+	for _, item := range table {
+		for _, result := range results {
+			if result.Switch != item.Switch {
+				continue
+			}
+			if result.Skipped != item.Skipped || !result.Success {
+				t.Errorf("Unexpected result for switch '%s': want skipped: %t got: %t (%s)", item.Switch, item.Skipped, result.Skipped, result.Message)
+				return
+			}
+		}
+		power, err := GetPowerState(item.Switch)
+		if err != nil {
+			t.Error(err.Error())
+			return
+		}
+		if power != (item.KeepOn || item.NetworkDevice) {
+			t.Errorf("Unexpected power state of switch '%s': want: %t got: %t", item.Switch, item.KeepOn || item.NetworkDevice, power)
+			return
+		}
+	}
+	if _, found, err := PowerOffRoom("invalid", "admin"); err != nil || found {
+		t.Errorf("Expected invalid room to be reported as not found: found: %t err: %v", found, err)
+	}
+}
+
+func TestCheckPowerOn(t *testing.T) {
+	if err := database.CreateSwitch(database.Switch{Id: "capped", Name: "capped", RoomId: "testing"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	previousChecks := powerOnChecks
+	defer func() { powerOnChecks = previousChecks }()
+	RegisterPowerOnCheck(func(switchId string) error {
+		if switchId == "capped" {
+			return fmt.Errorf("%w: test", ErrPowerOnRejected)
+		}
+		return nil
+	})
+	if err := CheckPowerOn("capped"); !errors.Is(err, ErrPowerOnRejected) {
+		t.Errorf("Expected power-on of switch 'capped' to be rejected, got: %v", err)
+		return
+	}
+	if err := CheckPowerOn("1"); err != nil {
+		t.Errorf("Power-on of switch '1' was rejected: %s", err.Error())
+		return
+	}
+	// Rejected switches can not be turned on through the regular power path
+	if err := SetSwitchPowerAll("capped", true, "admin"); err == nil {
+		t.Error("Switch 'capped' could be turned on despite being rejected")
+		return
+	}
+}
@@ -0,0 +1,258 @@
+package hardware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+)
+
+// Is returned if a network device without a shutdown url should be turned off
+// Callers which turn off many switches at once treat such switches as skipped
+var ErrShutdownNotSupported = errors.New("shutdown not supported")
+
+// Is used if a network device does not specify a broadcast address
+const defaultBroadcastAddress = "255.255.255.255:9"
+
+// Is used if a network device does not specify a grace period
+const defaultNetworkGracePeriod = 3 * time.Minute
+
+// Largest grace period in seconds which can be configured for a network device
+const MaxNetworkGracePeriod = 3600
+
+// Periodically updates the power states of network devices
+var networkScheduler *gocron.Scheduler
+
+// Contains when each network device was last switched by a power action
+// A device which has just been woken up is still booting and would otherwise be reported as turned off
+var networkDeviceSwitched = struct {
+	m     sync.Mutex
+	times map[string]time.Time
+}{
+	times: make(map[string]time.Time),
+}
+
+// Records that a network device has been switched by a power action
+func markNetworkDeviceSwitched(switchId string, now time.Time) {
+	networkDeviceSwitched.m.Lock()
+	defer networkDeviceSwitched.m.Unlock()
+	networkDeviceSwitched.times[switchId] = now
+}
+
+// Returns whether a network device has been switched so recently that its reachability must not be checked yet
+func inNetworkGracePeriod(device database.NetworkDevice, now time.Time) bool {
+	networkDeviceSwitched.m.Lock()
+	defer networkDeviceSwitched.m.Unlock()
+	switched, found := networkDeviceSwitched.times[device.SwitchId]
+	if !found {
+		return false
+	}
+	gracePeriod := defaultNetworkGracePeriod
+	if device.GracePeriod > 0 {
+		gracePeriod = time.Duration(device.GracePeriod) * time.Second
+	}
+	if now.Sub(switched) < gracePeriod {
+		return true
+	}
+	delete(networkDeviceSwitched.times, device.SwitchId)
+	return false
+}
+
+// Sets the power of a switch, is called by the job daemon
+// Switches which are configured as network devices are addressed directly, all other switches are sent to the hardware nodes
+func setSwitchPower(switchId string, powerOn bool) error {
+	device, isNetworkDevice, err := database.GetNetworkDeviceBySwitchId(switchId)
+	if err != nil {
+		log.Error("Failed to process power request: could not check if switch is a network device: ", err.Error())
+		return err
+	}
+	if !isNetworkDevice {
+		return setPowerOnAllNodes(switchId, powerOn)
+	}
+	if err := setNetworkDevicePower(device, powerOn); err != nil {
+		if errors.Is(err, ErrShutdownNotSupported) {
+			return err
+		}
+		event.Error("Network Device Request Failed", fmt.Sprintf("Power request to network device '%s' failed: %s", switchId, err.Error()))
+		return err
+	}
+	markNetworkDeviceSwitched(switchId, time.Now())
+	changed, err := database.SetPowerState(switchId, powerOn)
+	if err != nil {
+		log.Error("Failed to set power of network device: updating database entry failed: ", err.Error())
+		return err
+	}
+	if changed {
+		publishSwitchChange(switchId, powerOn)
+	}
+	return nil
+}
+
+// Turns a network device on using Wake-on-LAN or off using its shutdown url
+func setNetworkDevicePower(device database.NetworkDevice, powerOn bool) error {
+	if powerOn {
+		return sendMagicPacket(device.MacAddress, device.BroadcastAddress)
+	}
+	if device.ShutdownUrl == "" {
+		return fmt.Errorf("%w: network device '%s' has no shutdown url", ErrShutdownNotSupported, device.SwitchId)
+	}
+	client := http.Client{Timeout: 5 * time.Second}
+	res, err := client.Post(device.ShutdownUrl, "application/json", nil)
+	if err != nil {
+		log.Error("Network device shutdown request failed: ", err.Error())
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		log.Error(fmt.Sprintf("Network device shutdown request failed with status code: %s", res.Status))
+		return errors.New("shutdown of network device failed: non 2xx status code")
+	}
+	return nil
+}
+
+// Sends a Wake-on-LAN magic packet to the given MAC address
+// The packet consists of 6 bytes of 0xFF followed by 16 repetitions of the MAC address
+func sendMagicPacket(macAddress string, broadcastAddress string) error {
+	mac, err := net.ParseMAC(macAddress)
+	if err != nil {
+		return fmt.Errorf("invalid MAC address '%s': %s", macAddress, err.Error())
+	}
+	if len(mac) != 6 {
+		return fmt.Errorf("invalid MAC address '%s': Wake-on-LAN requires a 6 byte address", macAddress)
+	}
+	packet := make([]byte, 0, 102)
+	for i := 0; i < 6; i++ {
+		packet = append(packet, 0xFF)
+	}
+	for i := 0; i < 16; i++ {
+		packet = append(packet, mac...)
+	}
+	if broadcastAddress == "" {
+		broadcastAddress = defaultBroadcastAddress
+	}
+	conn, err := net.Dial("udp", broadcastAddress)
+	if err != nil {
+		log.Error("Failed to send magic packet: could not open connection: ", err.Error())
+		return err
+	}
+	defer conn.Close()
+	if _, err := conn.Write(packet); err != nil {
+		log.Error("Failed to send magic packet: ", err.Error())
+		return err
+	}
+	log.Debug(fmt.Sprintf("Sent Wake-on-LAN magic packet to '%s' via '%s'", macAddress, broadcastAddress))
+	return nil
+}
+
+// Checks if a network device is currently reachable using its status mode
+// The second return value is false if the device does not support status checks
+func isNetworkDeviceReachable(device database.NetworkDevice) (bool, bool) {
+	switch device.StatusMode {
+	case database.NetworkStatusPing:
+		// The system's ping binary is used because raw sockets require elevated privileges
+		// `--` prevents the host from being interpreted as an option
+		if err := exec.Command("ping", "-c", "1", "-W", "1", "--", device.Host).Run(); err != nil {
+			return false, true
+		}
+		return true, true
+	case database.NetworkStatusHttp:
+		client := http.Client{Timeout: time.Second}
+		res, err := client.Get(device.StatusUrl)
+		if err != nil {
+			return false, true
+		}
+		defer res.Body.Close()
+		return res.StatusCode >= 200 && res.StatusCode <= 299, true
+	default:
+		return false, false
+	}
+}
+
+// Updates the power states of all network devices based on their reachability
+// Devices which have been switched during their grace period are skipped
+func RunNetworkDeviceCheck() error {
+	devices, err := database.ListNetworkDevices()
+	if err != nil {
+		log.Error("Failed to check network devices: ", err.Error())
+		return err
+	}
+	now := time.Now()
+	for _, device := range devices {
+		if inNetworkGracePeriod(device, now) {
+			continue
+		}
+		reachable, supported := isNetworkDeviceReachable(device)
+		if !supported {
+			continue
+		}
+		changed, err := database.SetPowerState(device.SwitchId, reachable)
+		if err != nil {
+			log.Error("Failed to update power state of network device: ", err.Error())
+			return err
+		}
+		if changed {
+			log.Debug(fmt.Sprintf("Network device '%s' changed its power state to %t", device.SwitchId, reachable))
+			publishSwitchChange(device.SwitchId, reachable)
+		}
+	}
+	return nil
+}
+
+// Starts a scheduler which periodically checks the reachability of network devices
+func InitNetworkDeviceCheck() error {
+	networkScheduler = gocron.NewScheduler(time.Local)
+	runner := networkScheduler.Every(30 * time.Second)
+	if _, err := runner.Do(func() {
+		if err := RunNetworkDeviceCheck(); err != nil {
+			log.Error("Network device check failed: ", err.Error())
+		}
+	}); err != nil {
+		log.Error("Failed to setup network device check: ", err.Error())
+		return err
+	}
+	runner.StartImmediately()
+	networkScheduler.StartAsync()
+	return nil
+}
+
+// Checks if the configuration of a network device is complete and valid
+func ValidateNetworkDevice(device database.NetworkDevice) error {
+	mac, err := net.ParseMAC(device.MacAddress)
+	if err != nil || len(mac) != 6 {
+		return fmt.Errorf("invalid MAC address '%s'", device.MacAddress)
+	}
+	if device.BroadcastAddress != "" {
+		if _, _, err := net.SplitHostPort(device.BroadcastAddress); err != nil {
+			return fmt.Errorf("invalid broadcast address '%s': expected `host:port`", device.BroadcastAddress)
+		}
+	}
+	if device.GracePeriod > MaxNetworkGracePeriod {
+		return fmt.Errorf("grace period must not exceed %d seconds", MaxNetworkGracePeriod)
+	}
+	switch device.StatusMode {
+	case database.NetworkStatusNone:
+	case database.NetworkStatusPing:
+		if device.Host == "" {
+			return errors.New("status mode `ping` requires a host")
+		}
+		if strings.HasPrefix(device.Host, "-") || strings.ContainsAny(device.Host, " \t\n") {
+			return fmt.Errorf("invalid host '%s'", device.Host)
+		}
+	case database.NetworkStatusHttp:
+		if device.StatusUrl == "" {
+			return errors.New("status mode `http` requires a status url")
+		}
+	default:
+		return fmt.Errorf("invalid status mode '%s': expected `none`, `ping` or `http`", device.StatusMode)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package hardware
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestValidateNetworkDevice(t *testing.T) {
+	table := []struct {
+		Device database.NetworkDevice
+		Valid  bool
+	}{
+		{
+			Device: database.NetworkDevice{MacAddress: "00:11:22:33:44:55", StatusMode: database.NetworkStatusNone},
+			Valid:  true,
+		},
+		{
+			Device: database.NetworkDevice{MacAddress: "00:11:22:33:44:55", Host: "192.168.0.10", StatusMode: database.NetworkStatusPing},
+			Valid:  true,
+		},
+		{
+			Device: database.NetworkDevice{MacAddress: "00:11:22:33:44:55", BroadcastAddress: "192.168.0.255:9", StatusMode: database.NetworkStatusHttp, StatusUrl: "http://pc/status"},
+			Valid:  true,
+		},
+		{
+			Device: database.NetworkDevice{MacAddress: "invalid", StatusMode: database.NetworkStatusNone},
+			Valid:  false,
+		},
+		{
+			Device: database.NetworkDevice{MacAddress: "00:11:22:33:44:55", BroadcastAddress: "192.168.0.255", StatusMode: database.NetworkStatusNone},
+			Valid:  false,
+		},
+		{
+			Device: database.NetworkDevice{MacAddress: "00:11:22:33:44:55", StatusMode: database.NetworkStatusPing},
+			Valid:  false,
+		},
+		{
+			Device: database.NetworkDevice{MacAddress: "00:11:22:33:44:55", StatusMode: database.NetworkStatusHttp},
+			Valid:  false,
+		},
+		// Hosts must not be interpreted as options of the ping binary
+		{
+			Device: database.NetworkDevice{MacAddress: "00:11:22:33:44:55", Host: "-f", StatusMode: database.NetworkStatusPing},
+			Valid:  false,
+		},
+		{
+			Device: database.NetworkDevice{MacAddress: "00:11:22:33:44:55", Host: "pc -f", StatusMode: database.NetworkStatusPing},
+			Valid:  false,
+		},
+		{
+			Device: database.NetworkDevice{MacAddress: "00:11:22:33:44:55", StatusMode: "invalid"},
+			Valid:  false,
+		},
+		{
+			Device: database.NetworkDevice{MacAddress: "00:11:22:33:44:55", StatusMode: database.NetworkStatusNone, GracePeriod: MaxNetworkGracePeriod + 1},
+			Valid:  false,
+		},
+	}
+	for _, test := range table {
+		err := ValidateNetworkDevice(test.Device)
+		if test.Valid && err != nil {
+			t.Errorf("Network device %v should be valid but got error: %s", test.Device, err.Error())
+		}
+		if !test.Valid && err == nil {
+			t.Errorf("Network device %v should be invalid but passed validation", test.Device)
+		}
+	}
+}
+
+func TestSendMagicPacket(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	defer listener.Close()
+	if err := sendMagicPacket("00:11:22:33:44:55", listener.LocalAddr().String()); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := listener.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	packet := make([]byte, 200)
+	length, _, err := listener.ReadFrom(packet)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	// 6 bytes of 0xFF followed by 16 repetitions of the MAC address
+	want := bytes.Repeat([]byte{0xFF}, 6)
+	for i := 0; i < 16; i++ {
+		want = append(want, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55)
+	}
+	if !bytes.Equal(packet[:length], want) {
+		t.Errorf("Unexpected magic packet: want: %x got: %x", want, packet[:length])
+		return
+	}
+	// Invalid MAC addresses are rejected before anything is sent
+	if err := sendMagicPacket("invalid", listener.LocalAddr().String()); err == nil {
+		t.Error("Expected error for invalid MAC address which did not occur")
+	}
+}
+
+// The reachability of a recently switched device is not checked until its grace period has passed
+func TestNetworkGracePeriod(t *testing.T) {
+	now := time.Now()
+	table := []struct {
+		Device database.NetworkDevice
+		After  time.Duration
+		Grace  bool
+	}{
+		{Device: database.NetworkDevice{SwitchId: "grace_default"}, After: time.Minute, Grace: true},
+		{Device: database.NetworkDevice{SwitchId: "grace_default"}, After: defaultNetworkGracePeriod, Grace: false},
+		{Device: database.NetworkDevice{SwitchId: "grace_custom", GracePeriod: 10}, After: 9 * time.Second, Grace: true},
+		{Device: database.NetworkDevice{SwitchId: "grace_custom", GracePeriod: 10}, After: 11 * time.Second, Grace: false},
+	}
+	for _, test := range table {
+		markNetworkDeviceSwitched(test.Device.SwitchId, now)
+		if grace := inNetworkGracePeriod(test.Device, now.Add(test.After)); grace != test.Grace {
+			t.Errorf("Unexpected grace period of '%s' after %s: want: %t got: %t", test.Device.SwitchId, test.After, test.Grace, grace)
+			return
+		}
+	}
+	// Devices which have never been switched are always checked
+	if inNetworkGracePeriod(database.NetworkDevice{SwitchId: "grace_never"}, now) {
+		t.Error("Device which has never been switched is in its grace period")
+	}
+}
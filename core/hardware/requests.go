@@ -40,6 +40,7 @@ func checkNodeOnline(node database.HardwareNode) error {
 			log.Warn(fmt.Sprintf("Node `%s` failed to respond and is now offline", node.Name))
 			go event.Error("Node Offline",
 				fmt.Sprintf("Node %s went offline. Users will have to deal with increased wait times. It is advised to address this issue as soon as possible", node.Name))
+			event.Publish(event.NodeStatus{Url: node.Url, Name: node.Name, Online: false})
 		}
 		if errDB := database.SetNodeOnline(node.Url, false); errDB != nil {
 			log.Error("Failed to update power state of node: ", errDB.Error())
@@ -50,6 +51,7 @@ func checkNodeOnline(node database.HardwareNode) error {
 	if !node.Online {
 		log.Info(fmt.Sprintf("Node `%s` is now back online", node.Name))
 		go event.Info("Node Online", fmt.Sprintf("Node %s is back online.", node.Name))
+		event.Publish(event.NodeStatus{Url: node.Url, Name: node.Name, Online: true})
 	}
 	if errDB := database.SetNodeOnline(node.Url, true); errDB != nil {
 		log.Error("Failed to update power state of node: ", errDB.Error())
@@ -146,9 +148,13 @@ func setPowerOnAllNodes(switchName string, powerOn bool) error {
 			log.Debug("Successfully sent power request to: ", node.Name)
 		}
 	}
-	if _, err := database.SetPowerState(switchName, powerOn); err != nil {
-		log.Error("Failed to set power after addressing all nodes: updating database entry failed: ", err.Error())
-		return err
+	changed, errDB := database.SetPowerState(switchName, powerOn)
+	if errDB != nil {
+		log.Error("Failed to set power after addressing all nodes: updating database entry failed: ", errDB.Error())
+		return errDB
+	}
+	if changed {
+		publishSwitchChange(switchName, powerOn)
 	}
 	return err
 }
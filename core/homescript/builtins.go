@@ -0,0 +1,265 @@
+package homescript
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	hmsError "github.com/MikMuellerDev/homescript/homescript/error"
+	"github.com/MikMuellerDev/homescript/homescript/interpreter"
+)
+
+// Validates the arguments which are passed to a builtin of the smarthome server
+// Produces the same errors as the builtins of the Homescript interpreter
+func checkArgs(name string, location hmsError.Location, args []interpreter.Value, types ...interpreter.ValueType) *hmsError.Error {
+	if len(args) != len(types) {
+		plural := ""
+		if len(types) != 1 {
+			plural = "s"
+		}
+		return hmsError.NewError(
+			hmsError.TypeError,
+			location,
+			fmt.Sprintf("Function '%s' takes %d argument%s but %d were given", name, len(types), plural, len(args)),
+		)
+	}
+	for index, argType := range types {
+		if args[index].Type() != argType {
+			return hmsError.NewError(
+				hmsError.TypeError,
+				location,
+				fmt.Sprintf("Argument %d of function '%s' has to be of type %s", index+1, name, argType.Name()),
+			)
+		}
+	}
+	return nil
+}
+
+// Adds the builtins `arg` and `hasArg` which allow a script to read the arguments of its run
+// `arg('key')` returns the value of an argument and fails if it was not provided
+// `hasArg('key')` checks whether an argument was provided
+func addArgumentBuiltins(scope map[string]interpreter.Value, arguments map[string]string) {
+	scope["arg"] = interpreter.ValueFunction{
+		Callback: func(_ interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+			if err := checkArgs("arg", location, args, interpreter.String); err != nil {
+				return nil, err
+			}
+			key := args[0].(interpreter.ValueString).Value
+			value, exists := arguments[key]
+			if !exists {
+				return nil, hmsError.NewError(hmsError.ValueError, location, fmt.Sprintf("Argument '%s' was not provided", key))
+			}
+			return interpreter.ValueString{Value: value}, nil
+		},
+	}
+	scope["hasArg"] = interpreter.ValueFunction{
+		Callback: func(_ interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+			if err := checkArgs("hasArg", location, args, interpreter.String); err != nil {
+				return nil, err
+			}
+			_, exists := arguments[args[0].(interpreter.ValueString).Value]
+			return interpreter.ValueBoolean{Value: exists}, nil
+		},
+	}
+}
+
+// Is implemented by the executors of the smarthome server
+// The Homescript interpreter itself does not provide persistent storage
+type storageExecutor interface {
+	StorageGet(key string, global bool) (string, bool, error)
+	StorageSet(key string, value string, global bool) error
+	StorageDelete(key string, global bool) error
+	StorageIncrement(key string, global bool) (int, error)
+}
+
+func getStorageExecutor(executor interpreter.Executor, location hmsError.Location) (storageExecutor, *hmsError.Error) {
+	storage, ok := executor.(storageExecutor)
+	if !ok {
+		return nil, hmsError.NewError(hmsError.RuntimeError, location, "Storage is not available in this context")
+	}
+	return storage, nil
+}
+
+// Adds the builtins which access the persistent key-value storage of the user who runs the script
+// `storageGet('key')` returns the stored value or an empty string if the key does not exist
+// `storageHas('key')` checks whether a key exists
+// `storageSet('key', 'value')` creates or overwrites a value
+// `storageDelete('key')` deletes a value
+// `storageIncrement('key')` increments an integer value and returns it, a missing value is treated as 0
+// Each builtin is also available with the prefix `globalStorage` which accesses the storage shared by all users
+func addStorageBuiltins(scope map[string]interpreter.Value) {
+	for prefix, global := range map[string]bool{"storage": false, "globalStorage": true} {
+		prefix, global := prefix, global
+		scope[prefix+"Get"] = interpreter.ValueFunction{
+			Callback: func(executor interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+				if err := checkArgs(prefix+"Get", location, args, interpreter.String); err != nil {
+					return nil, err
+				}
+				storage, hmsErr := getStorageExecutor(executor, location)
+				if hmsErr != nil {
+					return nil, hmsErr
+				}
+				value, _, err := storage.StorageGet(args[0].(interpreter.ValueString).Value, global)
+				if err != nil {
+					return nil, hmsError.NewError(hmsError.RuntimeError, location, err.Error())
+				}
+				return interpreter.ValueString{Value: value}, nil
+			},
+		}
+		scope[prefix+"Has"] = interpreter.ValueFunction{
+			Callback: func(executor interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+				if err := checkArgs(prefix+"Has", location, args, interpreter.String); err != nil {
+					return nil, err
+				}
+				storage, hmsErr := getStorageExecutor(executor, location)
+				if hmsErr != nil {
+					return nil, hmsErr
+				}
+				_, found, err := storage.StorageGet(args[0].(interpreter.ValueString).Value, global)
+				if err != nil {
+					return nil, hmsError.NewError(hmsError.RuntimeError, location, err.Error())
+				}
+				return interpreter.ValueBoolean{Value: found}, nil
+			},
+		}
+		scope[prefix+"Set"] = interpreter.ValueFunction{
+			Callback: func(executor interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+				if err := checkArgs(prefix+"Set", location, args, interpreter.String, interpreter.String); err != nil {
+					return nil, err
+				}
+				storage, hmsErr := getStorageExecutor(executor, location)
+				if hmsErr != nil {
+					return nil, hmsErr
+				}
+				if err := storage.StorageSet(args[0].(interpreter.ValueString).Value, args[1].(interpreter.ValueString).Value, global); err != nil {
+					return nil, hmsError.NewError(hmsError.RuntimeError, location, err.Error())
+				}
+				return interpreter.ValueVoid{}, nil
+			},
+		}
+		scope[prefix+"Delete"] = interpreter.ValueFunction{
+			Callback: func(executor interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+				if err := checkArgs(prefix+"Delete", location, args, interpreter.String); err != nil {
+					return nil, err
+				}
+				storage, hmsErr := getStorageExecutor(executor, location)
+				if hmsErr != nil {
+					return nil, hmsErr
+				}
+				if err := storage.StorageDelete(args[0].(interpreter.ValueString).Value, global); err != nil {
+					return nil, hmsError.NewError(hmsError.RuntimeError, location, err.Error())
+				}
+				return interpreter.ValueVoid{}, nil
+			},
+		}
+		scope[prefix+"Increment"] = interpreter.ValueFunction{
+			Callback: func(executor interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+				if err := checkArgs(prefix+"Increment", location, args, interpreter.String); err != nil {
+					return nil, err
+				}
+				storage, hmsErr := getStorageExecutor(executor, location)
+				if hmsErr != nil {
+					return nil, hmsErr
+				}
+				counter, err := storage.StorageIncrement(args[0].(interpreter.ValueString).Value, global)
+				if err != nil {
+					return nil, hmsError.NewError(hmsError.RuntimeError, location, err.Error())
+				}
+				return interpreter.ValueNumber{Value: float64(counter)}, nil
+			},
+		}
+	}
+}
+
+// Is implemented by the executors of the smarthome server
+type httpExecutor interface {
+	HttpRequest(method string, url string, headers map[string]string, body string) (HttpResponse, error)
+	HttpStatus() int
+}
+
+// Parses header arguments of the form `Name: Value`, the first header argument is at position `offset` + 1
+func parseHttpHeaders(name string, location hmsError.Location, offset int, args []interpreter.Value) (map[string]string, *hmsError.Error) {
+	headers := make(map[string]string)
+	for index, arg := range args {
+		header, ok := arg.(interpreter.ValueString)
+		if !ok {
+			return nil, hmsError.NewError(
+				hmsError.TypeError,
+				location,
+				fmt.Sprintf("Argument %d of function '%s' has to be of type String", offset+index+1, name),
+			)
+		}
+		parts := strings.SplitN(header.Value, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, hmsError.NewError(
+				hmsError.ValueError,
+				location,
+				fmt.Sprintf("Invalid header '%s': headers must have the form 'Name: Value'", header.Value),
+			)
+		}
+		headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return headers, nil
+}
+
+// Sends an HTTP request using the executor and returns the body of the response
+func httpRequestBuiltin(executor interpreter.Executor, location hmsError.Location, method string, url string, body string, headers map[string]string) (interpreter.Value, *hmsError.Error) {
+	client, ok := executor.(httpExecutor)
+	if !ok {
+		return nil, hmsError.NewError(hmsError.RuntimeError, location, "HTTP requests are not available in this context")
+	}
+	response, err := client.HttpRequest(method, url, headers, body)
+	if err != nil {
+		return nil, hmsError.NewError(hmsError.RuntimeError, location, err.Error())
+	}
+	return interpreter.ValueString{Value: response.Body}, nil
+}
+
+// Adds the builtins which send HTTP requests to the hosts on the allowlist
+// `httpGet('url', 'Name: Value', ...)` sends a GET request with optional headers and returns the body of the response
+// `httpPost('url', 'body', 'Name: Value', ...)` sends a POST request with optional headers and returns the body of the response
+// `httpStatus()` returns the status code of the last response
+func addHttpBuiltins(scope map[string]interpreter.Value) {
+	scope["httpGet"] = interpreter.ValueFunction{
+		Callback: func(executor interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+			if len(args) < 1 {
+				return nil, hmsError.NewError(hmsError.TypeError, location, "Function 'httpGet' takes at least 1 argument but 0 were given")
+			}
+			if err := checkArgs("httpGet", location, args[:1], interpreter.String); err != nil {
+				return nil, err
+			}
+			headers, err := parseHttpHeaders("httpGet", location, 1, args[1:])
+			if err != nil {
+				return nil, err
+			}
+			return httpRequestBuiltin(executor, location, http.MethodGet, args[0].(interpreter.ValueString).Value, "", headers)
+		},
+	}
+	scope["httpPost"] = interpreter.ValueFunction{
+		Callback: func(executor interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+			if len(args) < 2 {
+				return nil, hmsError.NewError(hmsError.TypeError, location, fmt.Sprintf("Function 'httpPost' takes at least 2 arguments but %d were given", len(args)))
+			}
+			if err := checkArgs("httpPost", location, args[:2], interpreter.String, interpreter.String); err != nil {
+				return nil, err
+			}
+			headers, err := parseHttpHeaders("httpPost", location, 2, args[2:])
+			if err != nil {
+				return nil, err
+			}
+			return httpRequestBuiltin(executor, location, http.MethodPost, args[0].(interpreter.ValueString).Value, args[1].(interpreter.ValueString).Value, headers)
+		},
+	}
+	scope["httpStatus"] = interpreter.ValueFunction{
+		Callback: func(executor interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+			if err := checkArgs("httpStatus", location, args); err != nil {
+				return nil, err
+			}
+			client, ok := executor.(httpExecutor)
+			if !ok {
+				return nil, hmsError.NewError(hmsError.RuntimeError, location, "HTTP requests are not available in this context")
+			}
+			return interpreter.ValueNumber{Value: float64(client.HttpStatus())}, nil
+		},
+	}
+}
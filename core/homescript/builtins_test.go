@@ -0,0 +1,59 @@
+package homescript
+
+import (
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestArgumentBuiltins(t *testing.T) {
+	table := []struct {
+		Code       string
+		Arguments  map[string]string
+		Output     string
+		FirstError string
+	}{
+		{
+			Code:      "print(arg('room'))",
+			Arguments: map[string]string{"room": "kitchen"},
+			Output:    "kitchen",
+		},
+		{
+			Code:      "print(hasArg('room'), hasArg('other'))",
+			Arguments: map[string]string{"room": "kitchen"},
+			Output:    "truefalse",
+		},
+		{
+			Code:       "print(arg('room'))",
+			Arguments:  nil,
+			FirstError: "Argument 'room' was not provided",
+		},
+		{
+			Code:       "print(arg(1))",
+			Arguments:  nil,
+			FirstError: "Argument 1 of function 'arg' has to be of type String",
+		},
+	}
+	for _, test := range table {
+		output, _, errors := RunSaved("admin", database.Homescript{
+			Id:    "arguments_test",
+			Owner: "admin",
+			Code:  test.Code,
+		}, TriggerApi, test.Arguments)
+		if len(errors) > 0 {
+			if errors[0].Message != test.FirstError {
+				t.Errorf("Unexpected error for code '%s': want: %s got: %s", test.Code, test.FirstError, errors[0].Message)
+				return
+			}
+			continue
+		}
+		if test.FirstError != "" {
+			t.Errorf("Expected error for code '%s': want: %s got: none", test.Code, test.FirstError)
+			return
+		}
+		if output != test.Output {
+			t.Errorf("Unexpected output for code '%s': want: %s got: %s", test.Code, test.Output, output)
+			return
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package homescript
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Maximum amount of nested `exec` calls, including the first script
+const maxExecDepth = 10
+
+// Returns the call stack of a script which is called using `exec`
+// Fails if the script is already part of the call stack or if the maximum call depth would be exceeded
+func pushCallStack(callStack []string, homescriptId string) ([]string, error) {
+	newStack := make([]string, len(callStack), len(callStack)+1)
+	copy(newStack, callStack)
+	newStack = append(newStack, homescriptId)
+	for _, caller := range callStack {
+		if caller == homescriptId {
+			return nil, fmt.Errorf("Exec cycle detected: %s", formatCallStack(newStack))
+		}
+	}
+	if len(newStack) > maxExecDepth {
+		return nil, fmt.Errorf("Maximum exec depth of %d exceeded: %s", maxExecDepth, formatCallStack(newStack))
+	}
+	return newStack, nil
+}
+
+// Returns a human-readable representation of a call stack, for example `'a' -> 'b' -> 'a'`
+func formatCallStack(callStack []string) string {
+	quoted := make([]string, 0, len(callStack))
+	for _, homescriptId := range callStack {
+		quoted = append(quoted, fmt.Sprintf("'%s'", homescriptId))
+	}
+	return strings.Join(quoted, " -> ")
+}
@@ -0,0 +1,77 @@
+package homescript
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestPushCallStack(t *testing.T) {
+	table := []struct {
+		CallStack []string
+		Id        string
+		Error     string
+	}{
+		{
+			CallStack: []string{"a"},
+			Id:        "b",
+			Error:     "",
+		},
+		{
+			CallStack: []string{"a"},
+			Id:        "a",
+			Error:     "Exec cycle detected: 'a' -> 'a'",
+		},
+		{
+			CallStack: []string{"a", "b"},
+			Id:        "a",
+			Error:     "Exec cycle detected: 'a' -> 'b' -> 'a'",
+		},
+		{
+			CallStack: []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"},
+			Id:        "11",
+			Error:     "Maximum exec depth of 10 exceeded: '1' -> '2' -> '3' -> '4' -> '5' -> '6' -> '7' -> '8' -> '9' -> '10' -> '11'",
+		},
+	}
+	for _, test := range table {
+		callStack, err := pushCallStack(test.CallStack, test.Id)
+		if err != nil {
+			if err.Error() != test.Error {
+				t.Errorf("Unexpected error: want: %s got: %s", test.Error, err.Error())
+				return
+			}
+			continue
+		}
+		if test.Error != "" {
+			t.Errorf("Expected error: want: %s got: none", test.Error)
+			return
+		}
+		if len(callStack) != len(test.CallStack)+1 || callStack[len(callStack)-1] != test.Id {
+			t.Errorf("Unexpected call stack: got: %v", callStack)
+			return
+		}
+	}
+}
+
+func TestExecCycle(t *testing.T) {
+	for _, script := range []database.Homescript{
+		{Id: "cycle_a", Owner: "admin", Code: "exec('cycle_b')"},
+		{Id: "cycle_b", Owner: "admin", Code: "exec('cycle_a')"},
+	} {
+		if err := database.CreateNewHomescript(script); err != nil {
+			t.Error(err.Error())
+			return
+		}
+	}
+	_, _, err := RunById("admin", "cycle_a", TriggerLive)
+	if err == nil {
+		t.Error("Exec cycle did not fail")
+		return
+	}
+	want := fmt.Sprintf("Exec cycle detected: %s", formatCallStack([]string{"cycle_a", "cycle_b", "cycle_a"}))
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("Unexpected error: want: %s got: %s", want, err.Error())
+	}
+}
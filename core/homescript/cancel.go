@@ -0,0 +1,74 @@
+package homescript
+
+import (
+	"context"
+	"time"
+
+	"github.com/MikMuellerDev/homescript/homescript"
+	hmsError "github.com/MikMuellerDev/homescript/homescript/error"
+	"github.com/MikMuellerDev/homescript/homescript/interpreter"
+)
+
+// Returns the error which describes why a job's context has been cancelled
+func terminationError(ctx context.Context, scriptLabel string) *hmsError.Error {
+	message := "Homescript was terminated: killed by user"
+	if ctx.Err() == context.DeadlineExceeded {
+		message = "Homescript was terminated: maximum runtime exceeded"
+	}
+	return hmsError.NewError(hmsError.RuntimeError, hmsError.NewLocation(scriptLabel), message)
+}
+
+// Wraps every builtin of the interpreter so that it fails once the context is cancelled
+// Because Homescript has no loops, every long-running script has to call builtins repeatedly and is thus stopped at its next call
+// The `sleep` builtin is replaced with a version which returns as soon as the context is cancelled
+func makeCancellable(hmsInterpreter *homescript.Interpreter, ctx context.Context) {
+	for name, value := range hmsInterpreter.Scope {
+		switch builtin := value.(type) {
+		case interpreter.ValueFunction:
+			// `exit` is implemented by the interpreter itself and has no callback
+			if builtin.Callback == nil {
+				continue
+			}
+			callback := builtin.Callback
+			if name == "sleep" {
+				callback = cancellableSleep(ctx)
+			}
+			hmsInterpreter.Scope[name] = interpreter.ValueFunction{
+				Callback: func(executor interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+					if ctx.Err() != nil {
+						return nil, terminationError(ctx, location.Filename)
+					}
+					return callback(executor, location, args...)
+				},
+			}
+		case interpreter.ValueVariable:
+			callback := builtin.Callback
+			hmsInterpreter.Scope[name] = interpreter.ValueVariable{
+				Callback: func(executor interpreter.Executor, location hmsError.Location) (interpreter.Value, *hmsError.Error) {
+					if ctx.Err() != nil {
+						return nil, terminationError(ctx, location.Filename)
+					}
+					return callback(executor, location)
+				},
+			}
+		}
+	}
+}
+
+// Returns a `sleep` builtin which pauses the script but returns early if the context is cancelled
+func cancellableSleep(ctx context.Context) func(interpreter.Executor, hmsError.Location, ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+	return func(_ interpreter.Executor, location hmsError.Location, args ...interpreter.Value) (interpreter.Value, *hmsError.Error) {
+		if err := checkArgs("sleep", location, args, interpreter.Number); err != nil {
+			return nil, err
+		}
+		seconds := args[0].(interpreter.ValueNumber).Value
+		timer := time.NewTimer(time.Millisecond * time.Duration(seconds*1000))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return interpreter.ValueVoid{}, nil
+		case <-ctx.Done():
+			return nil, terminationError(ctx, location.Filename)
+		}
+	}
+}
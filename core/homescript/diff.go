@@ -0,0 +1,103 @@
+package homescript
+
+import "strings"
+
+type DiffOperation string
+
+const (
+	DiffEqual  DiffOperation = "equal"
+	DiffInsert DiffOperation = "insert"
+	DiffDelete DiffOperation = "delete"
+)
+
+// A single line of a diff between two versions of Homescript code
+// The line numbers start at 1, a line number is 0 if the line is not present in the respective version
+type DiffLine struct {
+	Operation DiffOperation `json:"operation"`
+	Text      string        `json:"text"`
+	OldLine   uint          `json:"oldLine"`
+	NewLine   uint          `json:"newLine"`
+}
+
+// Limits the size of the table which is used to compute the longest common subsequence
+// Without this limit, two large scripts could allocate gigabytes of memory
+const maxDiffCells = 2000 * 2000
+
+// Computes a line-based diff which transforms `oldCode` into `newCode`
+// Uses the longest common subsequence of both versions, deleted lines are listed before inserted lines
+// Lines which both versions share at their beginning and end are not part of the computation
+// If the remaining lines are too many, they are listed as deleted and inserted without searching for common lines
+func DiffCode(oldCode string, newCode string) []DiffLine {
+	oldLines := strings.Split(oldCode, "\n")
+	newLines := strings.Split(newCode, "\n")
+
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]DiffLine, 0)
+	for index := 0; index < prefix; index++ {
+		diff = append(diff, DiffLine{Operation: DiffEqual, Text: oldLines[index], OldLine: uint(index + 1), NewLine: uint(index + 1)})
+	}
+	diff = append(diff, diffLines(oldLines[prefix:len(oldLines)-suffix], newLines[prefix:len(newLines)-suffix], prefix, prefix)...)
+	for index := 0; index < suffix; index++ {
+		oldIndex := len(oldLines) - suffix + index
+		newIndex := len(newLines) - suffix + index
+		diff = append(diff, DiffLine{Operation: DiffEqual, Text: oldLines[oldIndex], OldLine: uint(oldIndex + 1), NewLine: uint(newIndex + 1)})
+	}
+	return diff
+}
+
+// Computes the diff of two slices of lines, the offsets are added to the line numbers
+func diffLines(oldLines []string, newLines []string, oldOffset int, newOffset int) []DiffLine {
+	diff := make([]DiffLine, 0)
+	if (len(oldLines)+1)*(len(newLines)+1) > maxDiffCells {
+		for index, line := range oldLines {
+			diff = append(diff, DiffLine{Operation: DiffDelete, Text: line, OldLine: uint(oldOffset + index + 1)})
+		}
+		for index, line := range newLines {
+			diff = append(diff, DiffLine{Operation: DiffInsert, Text: line, NewLine: uint(newOffset + index + 1)})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	lcs := make([][]int32, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			diff = append(diff, DiffLine{Operation: DiffEqual, Text: oldLines[i], OldLine: uint(oldOffset + i + 1), NewLine: uint(newOffset + j + 1)})
+			i++
+			j++
+		case j == len(newLines) || (i < len(oldLines) && lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, DiffLine{Operation: DiffDelete, Text: oldLines[i], OldLine: uint(oldOffset + i + 1)})
+			i++
+		default:
+			diff = append(diff, DiffLine{Operation: DiffInsert, Text: newLines[j], NewLine: uint(newOffset + j + 1)})
+			j++
+		}
+	}
+	return diff
+}
@@ -0,0 +1,80 @@
+package homescript
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffCode(t *testing.T) {
+	table := []struct {
+		Old  string
+		New  string
+		Want []DiffLine
+	}{
+		{
+			Old: "a\nb",
+			New: "a\nb",
+			Want: []DiffLine{
+				{Operation: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Operation: DiffEqual, Text: "b", OldLine: 2, NewLine: 2},
+			},
+		},
+		{
+			Old: "a\nb\nc",
+			New: "a\nc",
+			Want: []DiffLine{
+				{Operation: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Operation: DiffDelete, Text: "b", OldLine: 2},
+				{Operation: DiffEqual, Text: "c", OldLine: 3, NewLine: 2},
+			},
+		},
+		{
+			Old: "a\nc",
+			New: "a\nb\nc",
+			Want: []DiffLine{
+				{Operation: DiffEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Operation: DiffInsert, Text: "b", NewLine: 2},
+				{Operation: DiffEqual, Text: "c", OldLine: 2, NewLine: 3},
+			},
+		},
+		{
+			Old: "print('a')",
+			New: "print('b')",
+			Want: []DiffLine{
+				{Operation: DiffDelete, Text: "print('a')", OldLine: 1},
+				{Operation: DiffInsert, Text: "print('b')", NewLine: 1},
+			},
+		},
+	}
+	for _, test := range table {
+		diff := DiffCode(test.Old, test.New)
+		if !reflect.DeepEqual(diff, test.Want) {
+			t.Errorf("Unexpected diff of %q and %q: want: %v got: %v", test.Old, test.New, test.Want, diff)
+			return
+		}
+	}
+}
+
+func TestDiffCodeLimit(t *testing.T) {
+	// Both versions share their first and last line, the lines in between differ entirely
+	oldCode := "start\n" + strings.Repeat("a\n", 3000) + "end"
+	newCode := "start\n" + strings.Repeat("b\n", 3000) + "end"
+	diff := DiffCode(oldCode, newCode)
+	if len(diff) != 6002 {
+		t.Errorf("Unexpected diff length: want: 6002 got: %d", len(diff))
+		return
+	}
+	if diff[0] != (DiffLine{Operation: DiffEqual, Text: "start", OldLine: 1, NewLine: 1}) {
+		t.Errorf("Unexpected first line: got: %v", diff[0])
+		return
+	}
+	if diff[1] != (DiffLine{Operation: DiffDelete, Text: "a", OldLine: 2}) || diff[3001] != (DiffLine{Operation: DiffInsert, Text: "b", NewLine: 2}) {
+		t.Errorf("Differing lines are not listed as deleted and inserted: got: %v and %v", diff[1], diff[3001])
+		return
+	}
+	if diff[6001] != (DiffLine{Operation: DiffEqual, Text: "end", OldLine: 3002, NewLine: 3002}) {
+		t.Errorf("Unexpected last line: got: %v", diff[6001])
+		return
+	}
+}
@@ -0,0 +1,202 @@
+package homescript
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/MikMuellerDev/homescript/homescript/interpreter"
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/hardware"
+	"github.com/MikMuellerDev/smarthome/services/radigo"
+)
+
+// Describes which kind of side effect a script would have caused
+type DryRunActionType string
+
+const (
+	DryRunSwitch  DryRunActionType = "switch"
+	DryRunPlay    DryRunActionType = "play"
+	DryRunNotify  DryRunActionType = "notify"
+	DryRunLog     DryRunActionType = "log"
+	DryRunExec    DryRunActionType = "exec"
+	DryRunAddUser DryRunActionType = "addUser"
+	DryRunDelUser DryRunActionType = "delUser"
+	DryRunAddPerm DryRunActionType = "addPerm"
+	DryRunDelPerm DryRunActionType = "delPerm"
+
+	DryRunStorageSet    DryRunActionType = "storageSet"
+	DryRunStorageDelete DryRunActionType = "storageDelete"
+	DryRunHttp          DryRunActionType = "http"
+)
+
+// A side effect which has been recorded instead of being performed
+type DryRunAction struct {
+	Type    DryRunActionType `json:"type"`
+	Target  string           `json:"target"` // The switch, user, server, Homescript, storage key or url which is affected
+	Message string           `json:"message"`
+}
+
+// Collects the actions of a dry run in the order in which they were requested
+type actionRecorder struct {
+	m       sync.Mutex
+	actions []DryRunAction
+}
+
+func (self *actionRecorder) record(actionType DryRunActionType, target string, message string) {
+	self.m.Lock()
+	defer self.m.Unlock()
+	self.actions = append(self.actions, DryRunAction{Type: actionType, Target: target, Message: message})
+}
+
+func (self *actionRecorder) getActions() []DryRunAction {
+	self.m.Lock()
+	defer self.m.Unlock()
+	output := make([]DryRunAction, len(self.actions))
+	copy(output, self.actions)
+	return output
+}
+
+// An executor which records side effects instead of performing them
+// Functions which only read data, for example `SwitchOn`, use the real implementation
+// Permissions are still checked so that the dry run fails where the real run would fail
+type DryRunExecutor struct {
+	*Executor
+	recorder *actionRecorder
+}
+
+// Returns an error if the user of the executor lacks the given permission
+func (self *DryRunExecutor) requirePermission(permission database.PermissionType, action string) error {
+	hasPermission, err := database.UserHasPermission(self.Username, permission)
+	if err != nil {
+		return fmt.Errorf("Failed to %s: could not validate your permissions: %s", action, err.Error())
+	}
+	if !hasPermission {
+		return fmt.Errorf("Failed to %s: you lack the permission '%s'", action, permission)
+	}
+	return nil
+}
+
+func (self *DryRunExecutor) Switch(switchId string, powerOn bool) error {
+	if err := hardware.ValidateSwitchPower(switchId, self.Username); err != nil {
+		return err
+	}
+	onOffText := "on"
+	if !powerOn {
+		onOffText = "off"
+	}
+	self.recorder.record(DryRunSwitch, switchId, fmt.Sprintf("Would turn switch '%s' %s", switchId, onOffText))
+	return nil
+}
+
+func (self *DryRunExecutor) Play(server string, mode string) error {
+	if mode == "" {
+		return fmt.Errorf("Failed to play: the mode must not be empty")
+	}
+	if _, err := radigo.GetUserServer(self.Username, server); err != nil {
+		return fmt.Errorf("Failed to play: %s", err.Error())
+	}
+	self.recorder.record(DryRunPlay, server, fmt.Sprintf("Would play mode '%s' on server '%s'", mode, server))
+	return nil
+}
+
+func (self *DryRunExecutor) Notify(title string, description string, level interpreter.LogLevel) error {
+	self.recorder.record(DryRunNotify, self.Username, fmt.Sprintf("Would send notification '%s' with level %d: %s", title, level, description))
+	return nil
+}
+
+func (self *DryRunExecutor) Log(title string, description string, level interpreter.LogLevel) error {
+	if err := self.requirePermission(database.PermissionLogs, "add log event"); err != nil {
+		return err
+	}
+	if level > 5 {
+		return fmt.Errorf("Failed to add log event: invalid logging level <%d>: valid logging levels are 1, 2, 3, 4, or 5", level)
+	}
+	self.recorder.record(DryRunLog, "", fmt.Sprintf("Would add log event '%s' with level %d: %s", title, level, description))
+	return nil
+}
+
+// Checks whether the called script exists and whether the call is valid, but does not run it
+func (self *DryRunExecutor) Exec(homescriptId string) (string, error) {
+	if _, err := pushCallStack(self.callStack, homescriptId); err != nil {
+		return "", err
+	}
+	_, found, err := database.GetAccessibleHomescriptById(homescriptId, self.Username, database.HomescriptAccessRun)
+	if err != nil {
+		return "", fmt.Errorf("Failed to exec: database failure: %s", err.Error())
+	}
+	if !found {
+		return "", fmt.Errorf("Invalid Homescript id: no data associated with id")
+	}
+	self.recorder.record(DryRunExec, homescriptId, fmt.Sprintf("Would execute Homescript '%s'", homescriptId))
+	return "", nil
+}
+
+func (self *DryRunExecutor) AddUser(username string, password string, forename string, surname string) error {
+	if err := self.requirePermission(database.PermissionManageUsers, "add user"); err != nil {
+		return err
+	}
+	self.recorder.record(DryRunAddUser, username, fmt.Sprintf("Would add user '%s' (%s %s)", username, forename, surname))
+	return nil
+}
+
+func (self *DryRunExecutor) DelUser(username string) error {
+	if err := self.requirePermission(database.PermissionManageUsers, "remove user"); err != nil {
+		return err
+	}
+	self.recorder.record(DryRunDelUser, username, fmt.Sprintf("Would remove user '%s'", username))
+	return nil
+}
+
+func (self *DryRunExecutor) AddPerm(username string, permission string) error {
+	if err := self.requirePermission(database.PermissionManageUsers, "add permission"); err != nil {
+		return err
+	}
+	if !database.DoesPermissionExist(permission) {
+		return fmt.Errorf("Failed to add permission: the permission '%s' does not exist.", permission)
+	}
+	self.recorder.record(DryRunAddPerm, username, fmt.Sprintf("Would grant permission '%s' to user '%s'", permission, username))
+	return nil
+}
+
+func (self *DryRunExecutor) DelPerm(username string, permission string) error {
+	if err := self.requirePermission(database.PermissionManageUsers, "remove permission"); err != nil {
+		return err
+	}
+	if !database.DoesPermissionExist(permission) {
+		return fmt.Errorf("The permission '%s' does not exist.", permission)
+	}
+	self.recorder.record(DryRunDelPerm, username, fmt.Sprintf("Would remove permission '%s' from user '%s'", permission, username))
+	return nil
+}
+
+// Runs code without side effects and returns the actions it would have performed
+// Dry runs are not added to the run history
+func DryRun(username string, scriptLabel string, scriptCode string, arguments map[string]string) (string, int, []HomescriptError, []DryRunAction) {
+	recorder := &actionRecorder{actions: make([]DryRunAction, 0)}
+	output, exitCode, hmsErrors := run(context.Background(), runConfig{
+		username:    username,
+		scriptLabel: scriptLabel,
+		code:        scriptCode,
+		trigger:     TriggerDryRun,
+		arguments:   arguments,
+		dryRun:      recorder,
+	})
+	return output, exitCode, hmsErrors, recorder.getActions()
+}
+
+// Like `DryRun` but uses the code of a saved Homescript
+func DryRunSaved(username string, homescriptItem database.Homescript, arguments map[string]string) (string, int, []HomescriptError, []DryRunAction) {
+	recorder := &actionRecorder{actions: make([]DryRunAction, 0)}
+	output, exitCode, hmsErrors := run(context.Background(), runConfig{
+		username:     username,
+		homescriptId: homescriptItem.Id,
+		scriptLabel:  homescriptItem.Id,
+		code:         homescriptItem.Code,
+		trigger:      TriggerDryRun,
+		arguments:    arguments,
+		callStack:    rootCallStack(homescriptItem.Id),
+		dryRun:       recorder,
+	})
+	return output, exitCode, hmsErrors, recorder.getActions()
+}
@@ -0,0 +1,67 @@
+package homescript
+
+import (
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/hardware"
+)
+
+func TestDryRun(t *testing.T) {
+	if err := database.CreateRoom(database.RoomData{Id: "dry_run"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := database.CreateSwitch(database.Switch{Id: "dry_run", RoomId: "dry_run"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	powerBefore, err := hardware.GetPowerState("dry_run")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	output, exitCode, errors, actions := DryRun(
+		"admin",
+		"dry_run",
+		"print(switchOn('dry_run')); switch('dry_run', on); notify('title', 'description', 1); addUser('dry_run', 'password', 'a', 'b')",
+		nil,
+	)
+	if len(errors) > 0 || exitCode != 0 {
+		t.Errorf("Dry run failed: exit code: %d errors: %v", exitCode, errors)
+		return
+	}
+	if output != "false" {
+		t.Errorf("Unexpected output: want: false got: %s", output)
+		return
+	}
+	wantTypes := []DryRunActionType{DryRunSwitch, DryRunNotify, DryRunAddUser}
+	if len(actions) != len(wantTypes) {
+		t.Errorf("Unexpected amount of actions: want: %d got: %d (%v)", len(wantTypes), len(actions), actions)
+		return
+	}
+	for index, action := range actions {
+		if action.Type != wantTypes[index] {
+			t.Errorf("Unexpected action at index %d: want: %s got: %s", index, wantTypes[index], action.Type)
+			return
+		}
+	}
+	// The side effects must not have been performed
+	powerAfter, err := hardware.GetPowerState("dry_run")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if powerAfter != powerBefore {
+		t.Error("Dry run changed the power state of a switch")
+		return
+	}
+	_, userExists, err := database.GetUserByUsername("dry_run")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if userExists {
+		t.Error("Dry run added a user")
+	}
+}
@@ -1,9 +1,12 @@
 package homescript
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/exp/utf8string"
@@ -13,25 +16,42 @@ import (
 	"github.com/MikMuellerDev/smarthome/core/event"
 	"github.com/MikMuellerDev/smarthome/core/hardware"
 	"github.com/MikMuellerDev/smarthome/core/user"
+	"github.com/MikMuellerDev/smarthome/services/radigo"
+	"github.com/MikMuellerDev/smarthome/services/weather"
 )
 
 type Executor struct {
-	ScriptName string
-	Username   string
-	Output     string
+	ScriptName     string
+	Username       string
+	Output         string
+	outputLock     sync.Mutex      // The output may be read while a terminated interpreter is still running
+	ctx            context.Context // Is cancelled once the job of this executor is killed or times out
+	stream         StreamFunc      // Receives the events of a streamed run, is nil otherwise
+	callStack      []string        // Ids of the saved Homescripts in the current `exec` chain
+	runId          uint            // Id of the current run in the run history, 0 if it could not be recorded
+	ownerCode      bool            // Scripts started using `exec` only run code which has last been written by their owner
+	lastHttpStatus int             // Status code of the last HTTP response, is read by the `httpStatus` builtin
 }
 
 // Emulates printing to the console
 // Instead, appends the provided message to the output of the executor
 // Exists in order to return the script's output to the user
 func (self *Executor) Print(args ...string) {
-	var output string
+	self.outputLock.Lock()
+	defer self.outputLock.Unlock()
 	for _, arg := range args {
 		self.Output += arg
-		output += arg
+		self.emit(StreamEvent{Type: StreamEventOutput, Output: arg})
 	}
 }
 
+// Returns the output which has been printed so far
+func (self *Executor) getOutput() string {
+	self.outputLock.Lock()
+	defer self.outputLock.Unlock()
+	return self.Output
+}
+
 // Returns a boolean if the requested switch is on or off
 // Returns an error if the provided switch does not exist
 func (self *Executor) SwitchOn(switchId string) (bool, error) {
@@ -49,8 +69,10 @@ func (self *Executor) Switch(switchId string, powerOn bool) error {
 	err := hardware.SetSwitchPowerAll(switchId, powerOn, self.Username)
 	if err != nil {
 		log.Debug(fmt.Sprintf("[Homescript] ERROR: script: '%s' user: '%s': failed to set power: %s", self.ScriptName, self.Username, err.Error()))
+		self.emit(StreamEvent{Type: StreamEventError, Switch: switchId, PowerOn: powerOn, Message: err.Error()})
 		return err
 	}
+	self.emit(StreamEvent{Type: StreamEventSwitch, Switch: switchId, PowerOn: powerOn})
 	onOffText := "on"
 	if !powerOn {
 		onOffText = "off"
@@ -60,9 +82,13 @@ func (self *Executor) Switch(switchId string, powerOn bool) error {
 }
 
 // Sends a mode request to a given radiGo server
-// TODO: implement this feature
+// Checks if the server exists and if the user is allowed to use it
 func (self *Executor) Play(server string, mode string) error {
-	return errors.New("The feature 'radiGo' is not yet implemented")
+	if err := radigo.Play(self.Username, server, mode); err != nil {
+		log.Debug(fmt.Sprintf("[Homescript] ERROR: script: '%s' user: '%s': failed to play: %s", self.ScriptName, self.Username, err.Error()))
+		return err
+	}
+	return nil
 }
 
 // Sends a notification to the user who issues this command
@@ -219,10 +245,26 @@ func (self *Executor) Log(
 }
 
 // Executes another Homescript based on its Id
-func (self Executor) Exec(homescriptId string) (string, error) {
-	output, exitCode, err := RunById(self.Username, homescriptId)
+// The called script is terminated together with the calling script
+// Fails if the call would create a cycle or exceed the maximum call depth
+func (self *Executor) Exec(homescriptId string) (string, error) {
+	callStack, err := pushCallStack(self.callStack, homescriptId)
 	if err != nil {
-		self.Print(fmt.Sprintf("Exec failed: called homescript failed with exit code %d", exitCode))
+		log.Debug(fmt.Sprintf("[Homescript] script: '%s' user: '%s': exec failed: %s", self.ScriptName, self.Username, err.Error()))
+		self.emit(StreamEvent{Type: StreamEventError, Message: err.Error()})
+		return "", err
+	}
+	output, exitCode, err := runById(self.ctx, runConfig{
+		username:     self.Username,
+		homescriptId: homescriptId,
+		trigger:      TriggerExec,
+		callStack:    callStack,
+		parentRunId:  self.runId,
+		ownerCode:    self.ownerCode,
+	})
+	if err != nil {
+		log.Debug(fmt.Sprintf("[Homescript] script: '%s' user: '%s': exec failed: called homescript failed with exit code %d", self.ScriptName, self.Username, exitCode))
+		self.emit(StreamEvent{Type: StreamEventError, Message: err.Error()})
 		return output, err
 	}
 	return output, nil
@@ -233,14 +275,22 @@ func (self *Executor) GetUser() string {
 	return self.Username
 }
 
-// TODO: Will later be implemented, should return the weather as a human-readable string
+// Returns the current weather at the location of the server as a human-readable string, for example `light rain`
 func (self *Executor) GetWeather() (string, error) {
-	return "rainy", nil
+	data, err := weather.GetWeather()
+	if err != nil {
+		return "", fmt.Errorf("Failed to get weather: %s", err.Error())
+	}
+	return data.Description, nil
 }
 
-// TODO: Will later be implemented, should return the temperature in Celsius
+// Returns the current temperature at the location of the server in degrees Celsius, rounded to the nearest integer
 func (self *Executor) GetTemperature() (int, error) {
-	return 42, nil
+	data, err := weather.GetWeather()
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get temperature: %s", err.Error())
+	}
+	return int(math.Round(data.Temperature)), nil
 }
 
 // Returns the current time variables
@@ -0,0 +1,68 @@
+package homescript
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+// Adds a run to the run history before it is executed and returns its id
+// Recording the run early allows runs started using `exec` to reference the run which has started them
+// Failures are only logged because they must not affect the outcome of the run, the returned id is 0 in this case
+func startRecord(config runConfig, startedAt time.Time) uint {
+	run := database.HomescriptRun{
+		ScriptLabel: config.scriptLabel,
+		Username:    config.username,
+		Trigger:     string(config.trigger),
+		StartedAt:   startedAt,
+	}
+	if config.homescriptId != "" {
+		run.HomescriptId = &config.homescriptId
+	}
+	if config.parentRunId != 0 {
+		run.ParentRunId = &config.parentRunId
+	}
+	id, err := database.AddHomescriptRun(run)
+	if err != nil {
+		log.Error("Failed to record Homescript run: ", err.Error())
+		return 0
+	}
+	return id
+}
+
+// Stores the result of a run and removes runs which exceed the retention
+func finishRecord(id uint, config runConfig, output string, exitCode int, hmsErrors []HomescriptError) {
+	if id == 0 {
+		return
+	}
+	if hmsErrors == nil {
+		hmsErrors = make([]HomescriptError, 0)
+	}
+	encodedErrors, err := json.Marshal(hmsErrors)
+	if err != nil {
+		log.Error("Failed to record Homescript run: could not encode errors: ", err.Error())
+		return
+	}
+	if err := database.FinishHomescriptRun(id, time.Now(), exitCode, output, encodedErrors); err != nil {
+		log.Error("Failed to record Homescript run: ", err.Error())
+		return
+	}
+	if config.homescriptId == "" {
+		if err := database.PruneInlineHomescriptRuns(config.username, config.scriptLabel, database.DefaultRunRetention); err != nil {
+			log.Error("Failed to apply run retention: ", err.Error())
+		}
+		return
+	}
+	homescriptItem, found, err := database.GetHomescriptById(config.homescriptId)
+	if err != nil || !found {
+		return
+	}
+	retention := homescriptItem.RunRetention
+	if retention == 0 {
+		retention = database.DefaultRunRetention
+	}
+	if err := database.PruneHomescriptRuns(config.homescriptId, retention); err != nil {
+		log.Error("Failed to apply run retention: ", err.Error())
+	}
+}
@@ -1,13 +1,16 @@
 package homescript
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/MikMuellerDev/homescript/homescript"
 	hmsError "github.com/MikMuellerDev/homescript/homescript/error"
+	hmsInterpreter "github.com/MikMuellerDev/homescript/homescript/interpreter"
 	"github.com/MikMuellerDev/smarthome/core/database"
 )
 
@@ -51,34 +54,208 @@ func convertErrors(errorItems ...hmsError.Error) []HomescriptError {
 	return outputErrors
 }
 
+// Describes a single run of Homescript code
+type runConfig struct {
+	username     string
+	homescriptId string // Is empty if the code does not belong to a saved Homescript
+	revisionId   uint   // If not 0, `runById` loads the code of this revision instead of the current code
+	ownerCode    bool   // If true, `runById` loads the code which has last been written by the owner of the Homescript
+	scriptLabel  string
+	code         string
+	trigger      TriggerSource
+	arguments    map[string]string // Can be read by the script using `arg` and `hasArg`
+	stream       StreamFunc        // Receives the events of the run if it is not nil
+	callStack    []string          // Ids of the saved Homescripts in the current `exec` chain, including this one
+	parentRunId  uint              // Id of the run which started this run using `exec`, 0 otherwise
+	dryRun       *actionRecorder   // If set, side effects are recorded instead of being performed
+}
+
+// Returns the call stack of a saved Homescript which is started directly
+func rootCallStack(homescriptId string) []string {
+	return []string{homescriptId}
+}
+
 // Executes a given homescript as a given user, returns the output and a possible error slice
-func Run(username string, scriptLabel string, scriptCode string) (string, int, []HomescriptError) {
+// The run is registered in the job table and is terminated once it exceeds the configured maximum runtime
+func Run(username string, scriptLabel string, scriptCode string, trigger TriggerSource) (string, int, []HomescriptError) {
+	return run(context.Background(), runConfig{
+		username:    username,
+		scriptLabel: scriptLabel,
+		code:        scriptCode,
+		trigger:     trigger,
+	})
+}
+
+// Like `Run` but passes print output, switch actions and errors to the given function as they happen
+// The script is terminated once the context is cancelled, for example if the client has disconnected
+func RunStreaming(ctx context.Context, username string, scriptLabel string, scriptCode string, trigger TriggerSource, stream StreamFunc) (string, int, []HomescriptError) {
+	return run(ctx, runConfig{
+		username:    username,
+		scriptLabel: scriptLabel,
+		code:        scriptCode,
+		trigger:     trigger,
+		stream:      stream,
+	})
+}
+
+// Executes a saved Homescript which has already been loaded from the database
+// The arguments can be read by the script using `arg` and `hasArg`
+func RunSaved(username string, homescriptItem database.Homescript, trigger TriggerSource, arguments map[string]string) (string, int, []HomescriptError) {
+	return run(context.Background(), runConfig{
+		username:     username,
+		homescriptId: homescriptItem.Id,
+		scriptLabel:  homescriptItem.Id,
+		code:         homescriptItem.Code,
+		trigger:      trigger,
+		arguments:    arguments,
+		callStack:    rootCallStack(homescriptItem.Id),
+	})
+}
+
+// Derives the job's context from the given parent context and records the run in the run history
+func run(parent context.Context, config runConfig) (string, int, []HomescriptError) {
+	if config.dryRun != nil {
+		return execute(parent, config, 0)
+	}
+	runId := startRecord(config, time.Now())
+	output, exitCode, hmsErrors := execute(parent, config, runId)
+	finishRecord(runId, config, output, exitCode, hmsErrors)
+	return output, exitCode, hmsErrors
+}
+
+// Executes the code of a Homescript in a new job
+// `runId` is the id of the run in the run history and is passed to nested runs
+func execute(parent context.Context, config runConfig, runId uint) (string, int, []HomescriptError) {
+	job, ctx := registerJob(parent, config.username, config.scriptLabel, config.trigger)
+	defer unregisterJob(job.Id)
+
 	executor := &Executor{
-		Username:   username,
-		ScriptName: scriptLabel,
+		Username:   config.username,
+		ScriptName: config.scriptLabel,
+		ctx:        ctx,
+		stream:     config.stream,
+		callStack:  config.callStack,
+		runId:      runId,
+		ownerCode:  config.ownerCode,
+	}
+	parser := homescript.NewParser(homescript.NewLexer(config.scriptLabel, config.code))
+	ast, syntaxErrors := parser.Parse()
+	if len(syntaxErrors) > 0 {
+		log.Debug(fmt.Sprintf("Homescript '%s' ran by user '%s' has terminated: %s", config.scriptLabel, config.username, syntaxErrors[0].Message))
+		return "", 1, convertErrors(syntaxErrors...)
 	}
-	exitCode, runtimeErrors := homescript.Run(
-		executor,
-		scriptLabel,
-		scriptCode,
-	)
-	if len(runtimeErrors) > 0 {
-		log.Debug(fmt.Sprintf("Homescript '%s' ran by user '%s' has terminated: %s", scriptLabel, username, runtimeErrors[0].Message))
-		return executor.Output, 1, convertErrors(runtimeErrors...)
+	var interpreterExecutor hmsInterpreter.Executor = executor
+	if config.dryRun != nil {
+		interpreterExecutor = &DryRunExecutor{Executor: executor, recorder: config.dryRun}
+	}
+	interpreter := homescript.NewInterpreter(ast, interpreterExecutor)
+	addArgumentBuiltins(interpreter.Scope, config.arguments)
+	addStorageBuiltins(interpreter.Scope)
+	addHttpBuiltins(interpreter.Scope)
+	makeCancellable(&interpreter, ctx)
+
+	type result struct {
+		exitCode int
+		err      *hmsError.Error
 	}
-	log.Debug(fmt.Sprintf("Homescript '%s' ran by user '%s' was executed successfully", scriptLabel, username))
-	return executor.Output, exitCode, make([]HomescriptError, 0)
+	done := make(chan result, 1)
+	go func() {
+		exitCode, err := interpreter.Run()
+		done <- result{exitCode: exitCode, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			log.Debug(fmt.Sprintf("Homescript '%s' ran by user '%s' has terminated: %s", config.scriptLabel, config.username, res.err.Message))
+			return executor.getOutput(), 1, convertErrors(*res.err)
+		}
+		log.Debug(fmt.Sprintf("Homescript '%s' ran by user '%s' was executed successfully", config.scriptLabel, config.username))
+		return executor.getOutput(), res.exitCode, make([]HomescriptError, 0)
+	case <-ctx.Done():
+		// The interpreter goroutine terminates at its next builtin call
+		err := terminationError(ctx, config.scriptLabel)
+		log.Debug(fmt.Sprintf("Homescript '%s' ran by user '%s' was terminated: %s", config.scriptLabel, config.username, err.Message))
+		return executor.getOutput(), 1, convertErrors(*err)
+	}
+}
+
+// Executes a saved Homescript as a given user who has not started the run themselves, for example using an automation
+// Only code which has last been written by the owner of the Homescript is executed, changes of editors are ignored
+func RunById(username string, homescriptId string, trigger TriggerSource) (string, int, error) {
+	return runById(context.Background(), runConfig{
+		username:     username,
+		homescriptId: homescriptId,
+		trigger:      trigger,
+		callStack:    rootCallStack(homescriptId),
+		ownerCode:    true,
+	})
+}
+
+// Executes a previous revision of a saved Homescript as a given user
+// Is used by automations which have been pinned to a revision
+func RunRevisionById(username string, homescriptId string, revisionId uint, trigger TriggerSource) (string, int, error) {
+	return runById(context.Background(), runConfig{
+		username:     username,
+		homescriptId: homescriptId,
+		revisionId:   revisionId,
+		trigger:      trigger,
+		callStack:    rootCallStack(homescriptId),
+	})
+}
+
+// Returns the code of a saved Homescript which has last been written by its owner
+// Users who have been granted edit access must not be able to change the code which runs with the owner's permissions without the owner starting it
+// A Homescript without revisions has never been modified, so its current code is used
+func getOwnerCode(homescriptItem database.Homescript) (string, error) {
+	revision, found, err := database.GetNewestHomescriptRevisionOfAuthor(homescriptItem.Id, homescriptItem.Owner)
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return revision.Code, nil
+	}
+	revisions, err := database.ListHomescriptRevisions(homescriptItem.Id)
+	if err != nil {
+		return "", err
+	}
+	if len(revisions) > 0 {
+		return "", fmt.Errorf("Homescript '%s' has no code written by its owner '%s'", homescriptItem.Id, homescriptItem.Owner)
+	}
+	return homescriptItem.Code, nil
 }
 
-func RunById(username string, homescriptId string) (string, int, error) {
-	homescriptItem, hasBeenFound, err := database.GetUserHomescriptById(homescriptId, username)
+// Like `RunById` but derives the job's context from the given parent context
+// The code and label of the config are loaded from the saved Homescript
+// Homescripts which have been shared with the user using at least run access can also be run
+func runById(parent context.Context, config runConfig) (string, int, error) {
+	homescriptItem, hasBeenFound, err := database.GetAccessibleHomescriptById(config.homescriptId, config.username, database.HomescriptAccessRun)
 	if err != nil {
 		return "database error", 500, err
 	}
 	if !hasBeenFound {
 		return "not found error", 404, errors.New("Invalid Homescript id: no data associated with id")
 	}
-	output, exitCode, errorsHms := Run(username, homescriptItem.Id, homescriptItem.Code)
+	config.scriptLabel = homescriptItem.Id
+	config.code = homescriptItem.Code
+	if config.ownerCode && config.revisionId == 0 {
+		code, err := getOwnerCode(homescriptItem)
+		if err != nil {
+			return "database error", 500, err
+		}
+		config.code = code
+	}
+	if config.revisionId != 0 {
+		revision, found, err := database.GetHomescriptRevision(config.homescriptId, config.revisionId)
+		if err != nil {
+			return "database error", 500, err
+		}
+		if !found {
+			return "not found error", 404, fmt.Errorf("Invalid Homescript revision: '%s' has no revision with id %d", config.homescriptId, config.revisionId)
+		}
+		config.code = revision.Code
+	}
+	output, exitCode, errorsHms := run(parent, config)
 	if len(errorsHms) > 0 {
 		return "execution error", exitCode, fmt.Errorf("Homescript terminated with exit code %d: %s", exitCode, errorsHms[0].Message)
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/MikMuellerDev/smarthome/core/database"
 	"github.com/MikMuellerDev/smarthome/core/event"
 	"github.com/MikMuellerDev/smarthome/core/hardware"
+	"github.com/MikMuellerDev/smarthome/services/radigo"
 )
 
 func TestMain(m *testing.M) {
@@ -18,6 +19,7 @@ func TestMain(m *testing.M) {
 	InitLogger(log)
 	event.InitLogger(log)
 	hardware.InitLogger(log)
+	radigo.InitLogger(log)
 	hardware.Init()
 	if err := initDB(true); err != nil {
 		panic(err.Error())
@@ -54,7 +56,7 @@ func TestRun(t *testing.T) {
 		t.Error(err.Error())
 		return
 	}
-	if err := database.CreateSwitch("test", "", "test", 0); err != nil {
+	if err := database.CreateSwitch(database.Switch{Id: "test", RoomId: "test"}); err != nil {
 		t.Error(err.Error())
 		return
 	}
@@ -230,7 +232,7 @@ func TestRun(t *testing.T) {
 			}{
 				Output:     "",
 				Code:       1,
-				FirstError: "The feature 'radiGo' is not yet implemented",
+				FirstError: "Failed to play: the mode must not be empty",
 			},
 		},
 		{
@@ -260,7 +262,7 @@ func TestRun(t *testing.T) {
 	}
 	for _, test := range table {
 		output, code, errors := Run(
-			"admin", "testing", test.Code,
+			"admin", "testing", test.Code, TriggerLive,
 		)
 		if len(errors) > 0 {
 			if errors[0].Message != test.Result.FirstError {
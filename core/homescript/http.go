@@ -0,0 +1,127 @@
+package homescript
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+// Limits the HTTP requests which are sent by Homescripts
+const (
+	HttpRequestTimeout  = 10 * time.Second
+	MaxHttpResponseSize = 1 << 20 // Larger response bodies are rejected
+)
+
+// The result of an HTTP request which was sent by a Homescript
+// Responses with an error status are not treated as failures
+type HttpResponse struct {
+	StatusCode int
+	Body       string
+}
+
+// Follows redirects only to hosts which are on the allowlist
+var httpClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return checkHttpHost(req.URL)
+	},
+}
+
+// Returns an error if the host of the url is not on the allowlist
+func checkHttpHost(target *url.URL) error {
+	allowed, err := database.IsHomescriptHttpHostAllowed(target.Hostname())
+	if err != nil {
+		return fmt.Errorf("Failed to validate host: database failure: %s", err.Error())
+	}
+	if !allowed {
+		return fmt.Errorf("Host '%s' is not allowed: ask your administrator to add it to the allowlist", target.Hostname())
+	}
+	return nil
+}
+
+// Validates the permission of the user, the method and the url of an HTTP request
+func validateHttpRequest(username string, method string, rawUrl string) (*url.URL, error) {
+	hasPermission, err := database.UserHasPermission(username, database.PermissionHomescriptHttp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to send HTTP request: could not validate your permissions: %s", err.Error())
+	}
+	if !hasPermission {
+		return nil, fmt.Errorf("Failed to send HTTP request: you lack the permission '%s'", database.PermissionHomescriptHttp)
+	}
+	if method != http.MethodGet && method != http.MethodPost {
+		return nil, fmt.Errorf("Failed to send HTTP request: unsupported method '%s': valid methods are GET and POST", method)
+	}
+	target, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to send HTTP request: invalid url: %s", err.Error())
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("Failed to send HTTP request: unsupported scheme '%s': valid schemes are http and https", target.Scheme)
+	}
+	if err := checkHttpHost(target); err != nil {
+		return nil, fmt.Errorf("Failed to send HTTP request: %s", err.Error())
+	}
+	return target, nil
+}
+
+// Sends a GET or POST request to a host on the allowlist and returns the status code and body of the response
+// The request is cancelled after `HttpRequestTimeout` or once the job of the executor is terminated
+func (self *Executor) HttpRequest(method string, rawUrl string, headers map[string]string, body string) (HttpResponse, error) {
+	target, err := validateHttpRequest(self.Username, method, rawUrl)
+	if err != nil {
+		return HttpResponse{}, err
+	}
+	parent := self.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, HttpRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), strings.NewReader(body))
+	if err != nil {
+		return HttpResponse{}, fmt.Errorf("Failed to send HTTP request: %s", err.Error())
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		log.Debug(fmt.Sprintf("[Homescript] ERROR: script: '%s' user: '%s': HTTP request failed: %s", self.ScriptName, self.Username, err.Error()))
+		return HttpResponse{}, fmt.Errorf("Failed to send HTTP request: %s", err.Error())
+	}
+	defer res.Body.Close()
+	resBody, err := io.ReadAll(io.LimitReader(res.Body, MaxHttpResponseSize+1))
+	if err != nil {
+		return HttpResponse{}, fmt.Errorf("Failed to read HTTP response: %s", err.Error())
+	}
+	if len(resBody) > MaxHttpResponseSize {
+		return HttpResponse{}, fmt.Errorf("Failed to read HTTP response: the body exceeds the maximum size of %d bytes", MaxHttpResponseSize)
+	}
+	self.lastHttpStatus = res.StatusCode
+	log.Debug(fmt.Sprintf("[Homescript] script: '%s' user: '%s': %s %s: %d", self.ScriptName, self.Username, method, target.String(), res.StatusCode))
+	return HttpResponse{StatusCode: res.StatusCode, Body: string(resBody)}, nil
+}
+
+// Returns the status code of the last HTTP response, 0 if no request has succeeded yet
+func (self *Executor) HttpStatus() int {
+	return self.lastHttpStatus
+}
+
+// Validates the request but does not send it, the response is empty
+func (self *DryRunExecutor) HttpRequest(method string, rawUrl string, headers map[string]string, body string) (HttpResponse, error) {
+	target, err := validateHttpRequest(self.Username, method, rawUrl)
+	if err != nil {
+		return HttpResponse{}, err
+	}
+	self.recorder.record(DryRunHttp, target.String(), fmt.Sprintf("Would send %s request to '%s'", method, target.String()))
+	return HttpResponse{}, nil
+}
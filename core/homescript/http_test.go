@@ -0,0 +1,78 @@
+package homescript
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestHttpRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("X-Test"), body)
+	}))
+	defer server.Close()
+	executor := Executor{Username: "admin", ScriptName: "http_test"}
+
+	// Requests to hosts which are not on the allowlist fail
+	if _, err := executor.HttpRequest(http.MethodGet, server.URL, nil, ""); err == nil {
+		t.Error("Request to a host which is not allowed succeeded")
+		return
+	}
+	if err := database.AddHomescriptHttpHost(database.HomescriptHttpHost{Host: "127.0.0.1"}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	defer database.DeleteHomescriptHttpHost("127.0.0.1")
+
+	table := []struct {
+		Method  string
+		Path    string
+		Headers map[string]string
+		Body    string
+		Status  int
+		Output  string
+	}{
+		{Method: http.MethodGet, Path: "/", Status: 200, Output: "GET  "},
+		{Method: http.MethodPost, Path: "/", Headers: map[string]string{"X-Test": "header"}, Body: "body", Status: 200, Output: "POST header body"},
+		{Method: http.MethodGet, Path: "/missing", Status: 404, Output: ""},
+	}
+	for _, test := range table {
+		response, err := executor.HttpRequest(test.Method, server.URL+test.Path, test.Headers, test.Body)
+		if err != nil {
+			t.Error(err.Error())
+			return
+		}
+		if response.StatusCode != test.Status || response.Body != test.Output {
+			t.Errorf("Unexpected response for %s %s: want: %d '%s' got: %d '%s'", test.Method, test.Path, test.Status, test.Output, response.StatusCode, response.Body)
+			return
+		}
+	}
+	if _, err := executor.HttpRequest(http.MethodDelete, server.URL, nil, ""); err == nil {
+		t.Error("Request with an unsupported method succeeded")
+		return
+	}
+
+	// Builtins
+	output, _, errors := RunSaved("admin", database.Homescript{
+		Id:    "http_test",
+		Owner: "admin",
+		Code:  fmt.Sprintf("print(httpPost('%s', 'body', 'X-Test: builtin'), httpStatus())", server.URL),
+	}, TriggerApi, nil)
+	if len(errors) > 0 {
+		t.Error(errors[0].Message)
+		return
+	}
+	if output != "POST builtin body200" {
+		t.Errorf("Unexpected output: want: 'POST builtin body200' got: '%s'", output)
+		return
+	}
+}
@@ -0,0 +1,164 @@
+package homescript
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+)
+
+// Describes what started a Homescript run
+type TriggerSource string
+
+const (
+	TriggerLive        TriggerSource = "live"        // Code which was sent by a user, for example from the editor
+	TriggerAutomation  TriggerSource = "automation"  // A saved Homescript which is run by an automation
+	TriggerSchedule    TriggerSource = "schedule"    // The code of a schedule
+	TriggerExec        TriggerSource = "exec"        // A saved Homescript which is run by another script using `exec`
+	TriggerApi         TriggerSource = "api"         // A saved Homescript which is run by its id, for example from a wall tablet
+	TriggerQuickAction TriggerSource = "quickAction" // A saved Homescript which is run from the quick actions dashboard
+	TriggerWebhook     TriggerSource = "webhook"     // A saved Homescript which is run by an incoming webhook
+	TriggerDryRun      TriggerSource = "dryRun"      // Code which is analyzed without performing its side effects
+)
+
+// Is used if the server configuration could not be retrieved
+const defaultTimeout = 300 * time.Second
+
+// A currently running Homescript
+type Job struct {
+	Id          uint64             `json:"id"`
+	Owner       string             `json:"owner"`
+	ScriptLabel string             `json:"scriptLabel"`
+	Trigger     TriggerSource      `json:"trigger"`
+	StartedAt   time.Time          `json:"startedAt"`
+	Deadline    *time.Time         `json:"deadline"` // Is nil if the run has no maximum runtime
+	cancel      context.CancelFunc // Stops the interpreter of this job
+}
+
+// Contains every running job, the key is the job's id
+var jobs = struct {
+	m      sync.RWMutex
+	items  map[uint64]*Job
+	nextId uint64
+}{
+	items: make(map[uint64]*Job),
+}
+
+// Returns the maximum runtime of a Homescript as configured by the administrator
+// A value of 0 means that scripts may run forever
+func getTimeout() time.Duration {
+	config, found, err := database.GetServerConfiguration()
+	if err != nil || !found {
+		log.Warn("Could not retrieve Homescript timeout from server configuration, using default")
+		return defaultTimeout
+	}
+	return time.Duration(config.HomescriptTimeout) * time.Second
+}
+
+// Adds a new job to the job table
+// The returned context is cancelled once the job is killed or its maximum runtime is exceeded
+// Nested runs pass the context of their parent so that killing the parent also kills the child
+func registerJob(parent context.Context, owner string, scriptLabel string, trigger TriggerSource) (*Job, context.Context) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	job := Job{
+		Owner:       owner,
+		ScriptLabel: scriptLabel,
+		Trigger:     trigger,
+		StartedAt:   time.Now(),
+	}
+	if timeout := getTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(parent, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(parent)
+	}
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline {
+		job.Deadline = &deadline
+	}
+	job.cancel = cancel
+
+	jobs.m.Lock()
+	jobs.nextId++
+	job.Id = jobs.nextId
+	jobs.items[job.Id] = &job
+	jobs.m.Unlock()
+	publishJobStatus(job, false)
+	return &job, ctx
+}
+
+// Removes a job from the job table after it has finished
+func unregisterJob(id uint64) {
+	jobs.m.Lock()
+	job, exists := jobs.items[id]
+	if exists {
+		job.cancel() // Releases the resources of the context
+		delete(jobs.items, id)
+	}
+	jobs.m.Unlock()
+	if exists {
+		publishJobStatus(*job, true)
+	}
+}
+
+// Notifies the subscribers of the event bus that a job has been started or has finished
+// Is called without holding the lock of the job table so that synchronous subscribers can query the jobs
+func publishJobStatus(job Job, finished bool) {
+	event.Publish(event.HomescriptJob{
+		Id:          job.Id,
+		Owner:       job.Owner,
+		ScriptLabel: job.ScriptLabel,
+		Trigger:     string(job.Trigger),
+		Finished:    finished,
+	})
+}
+
+// Returns all currently running jobs, ordered by their start time
+func GetJobs() []Job {
+	jobs.m.RLock()
+	defer jobs.m.RUnlock()
+	output := make([]Job, 0)
+	for _, job := range jobs.items {
+		output = append(output, *job)
+	}
+	sort.Slice(output, func(i, j int) bool { return output[i].Id < output[j].Id })
+	return output
+}
+
+// Returns the currently running jobs of a given user
+func GetUserJobs(username string) []Job {
+	output := make([]Job, 0)
+	for _, job := range GetJobs() {
+		if job.Owner == username {
+			output = append(output, job)
+		}
+	}
+	return output
+}
+
+// Returns a running job given its id
+func GetJobById(id uint64) (Job, bool) {
+	jobs.m.RLock()
+	defer jobs.m.RUnlock()
+	job, exists := jobs.items[id]
+	if !exists {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Stops the interpreter of a running job
+// The job is removed from the job table once its interpreter has terminated
+// Returns false if no job with the given id is running
+func KillJob(id uint64) bool {
+	jobs.m.RLock()
+	defer jobs.m.RUnlock()
+	job, exists := jobs.items[id]
+	if !exists {
+		return false
+	}
+	job.cancel()
+	return true
+}
@@ -0,0 +1,56 @@
+package homescript
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKillJob(t *testing.T) {
+	type runResult struct {
+		exitCode int
+		errors   []HomescriptError
+	}
+	done := make(chan runResult, 1)
+	go func() {
+		_, exitCode, errors := Run("admin", "kill_test", "sleep(10)", TriggerLive)
+		done <- runResult{exitCode: exitCode, errors: errors}
+	}()
+	// Wait until the job has been registered
+	var jobId uint64
+	for attempt := 0; attempt < 100 && jobId == 0; attempt++ {
+		for _, job := range GetUserJobs("admin") {
+			if job.ScriptLabel == "kill_test" {
+				jobId = job.Id
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if jobId == 0 {
+		t.Error("Job was not registered")
+		return
+	}
+	if !KillJob(jobId) {
+		t.Errorf("Could not kill job %d", jobId)
+		return
+	}
+	select {
+	case result := <-done:
+		if result.exitCode != 1 || len(result.errors) == 0 {
+			t.Errorf("Killed job did not fail: exit code: %d errors: %v", result.exitCode, result.errors)
+			return
+		}
+		if result.errors[0].Message != "Homescript was terminated: killed by user" {
+			t.Errorf("Unexpected error message: %s", result.errors[0].Message)
+			return
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("Job did not terminate after it has been killed")
+		return
+	}
+	if _, found := GetJobById(jobId); found {
+		t.Errorf("Job %d is still present in the job table after termination", jobId)
+	}
+	if KillJob(jobId) {
+		t.Error("Killing a terminated job succeeded")
+	}
+}
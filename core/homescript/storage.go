@@ -0,0 +1,218 @@
+package homescript
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+// Limits the size of the persistent storage of Homescripts
+const (
+	MaxStorageKeyLength   = 50
+	MaxStorageValueLength = 4096
+	MaxStorageEntries     = 200 // Applies to each user and to the global storage
+)
+
+var (
+	ErrStorageForbidden = errors.New("Permission denied: modifying the global storage requires the permission to manage Homescripts")
+	ErrStorageDatabase  = errors.New("database failure") // Is wrapped by errors which are caused by the database
+)
+
+// Serializes modifications of the storage so that read-modify-write operations like increments are atomic
+var storageLock sync.Mutex
+
+// Returns the owner of the storage which is used by a user
+func storageOwner(username string, global bool) string {
+	if global {
+		return database.GlobalStorageOwner
+	}
+	return username
+}
+
+// Checks whether a user may modify the requested storage
+// Modifying the global storage requires the permission to manage Homescripts, otherwise `ErrStorageForbidden` is returned
+func CheckStorageModification(username string, global bool) error {
+	if !global {
+		return nil
+	}
+	hasPermission, err := database.UserHasPermission(username, database.PermissionManageHomescripts)
+	if err != nil {
+		return fmt.Errorf("Failed to check permission: %w: %s", ErrStorageDatabase, err.Error())
+	}
+	if !hasPermission {
+		return ErrStorageForbidden
+	}
+	return nil
+}
+
+// Validates the length of a storage key and value
+func validateStorageEntry(key string, value string) error {
+	if key == "" || len(key) > MaxStorageKeyLength {
+		return fmt.Errorf("Storage keys must not be empty and must not exceed %d characters", MaxStorageKeyLength)
+	}
+	if len(value) > MaxStorageValueLength {
+		return fmt.Errorf("Storage values must not exceed %d characters", MaxStorageValueLength)
+	}
+	return nil
+}
+
+// Returns a value of the personal or the global storage
+func GetStorageValue(username string, global bool, key string) (string, bool, error) {
+	entry, found, err := database.GetStorageEntry(storageOwner(username, global), key)
+	if err != nil {
+		return "", false, fmt.Errorf("Failed to read storage: %w: %s", ErrStorageDatabase, err.Error())
+	}
+	return entry.Value, found, nil
+}
+
+// Creates or overwrites a value of the personal or the global storage
+// Fails if the entry is too large or if a new entry would exceed the maximum amount of entries
+func SetStorageValue(username string, global bool, key string, value string) error {
+	storageLock.Lock()
+	defer storageLock.Unlock()
+	return setStorageValue(username, global, key, value)
+}
+
+// Like `SetStorageValue` but requires the caller to hold the storage lock
+func setStorageValue(username string, global bool, key string, value string) error {
+	if err := validateStorageEntry(key, value); err != nil {
+		return err
+	}
+	owner := storageOwner(username, global)
+	_, exists, err := database.GetStorageEntry(owner, key)
+	if err != nil {
+		return fmt.Errorf("Failed to write storage: %w: %s", ErrStorageDatabase, err.Error())
+	}
+	if !exists {
+		count, err := database.CountStorageEntries(owner)
+		if err != nil {
+			return fmt.Errorf("Failed to write storage: %w: %s", ErrStorageDatabase, err.Error())
+		}
+		if count >= MaxStorageEntries {
+			return fmt.Errorf("Failed to write storage: the maximum of %d entries has been reached", MaxStorageEntries)
+		}
+	}
+	if err := database.SetStorageEntry(owner, key, value, username); err != nil {
+		return fmt.Errorf("Failed to write storage: %w: %s", ErrStorageDatabase, err.Error())
+	}
+	return nil
+}
+
+// Deletes a value of the personal or the global storage, does nothing if the key does not exist
+func DeleteStorageValue(username string, global bool, key string) error {
+	storageLock.Lock()
+	defer storageLock.Unlock()
+	if err := database.DeleteStorageEntry(storageOwner(username, global), key); err != nil {
+		return fmt.Errorf("Failed to delete storage entry: %w: %s", ErrStorageDatabase, err.Error())
+	}
+	return nil
+}
+
+// Parses a stored counter, a missing value is treated as 0
+func parseStorageCounter(key string, value string, found bool) (int, error) {
+	if !found {
+		return 0, nil
+	}
+	counter, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("Storage entry '%s' cannot be incremented: '%s' is not an integer", key, value)
+	}
+	return counter, nil
+}
+
+// Atomically increments an integer value of the personal or the global storage and returns the new value
+// A missing value is treated as 0
+func IncrementStorageValue(username string, global bool, key string) (int, error) {
+	storageLock.Lock()
+	defer storageLock.Unlock()
+	value, found, err := GetStorageValue(username, global, key)
+	if err != nil {
+		return 0, err
+	}
+	counter, err := parseStorageCounter(key, value, found)
+	if err != nil {
+		return 0, err
+	}
+	counter++
+	if err := setStorageValue(username, global, key, strconv.Itoa(counter)); err != nil {
+		return 0, err
+	}
+	return counter, nil
+}
+
+// Reads a value of the storage of the user who runs the script
+func (self *Executor) StorageGet(key string, global bool) (string, bool, error) {
+	return GetStorageValue(self.Username, global, key)
+}
+
+// Writes a value to the storage of the user who runs the script
+func (self *Executor) StorageSet(key string, value string, global bool) error {
+	if err := CheckStorageModification(self.Username, global); err != nil {
+		return err
+	}
+	return SetStorageValue(self.Username, global, key, value)
+}
+
+// Deletes a value from the storage of the user who runs the script
+func (self *Executor) StorageDelete(key string, global bool) error {
+	if err := CheckStorageModification(self.Username, global); err != nil {
+		return err
+	}
+	return DeleteStorageValue(self.Username, global, key)
+}
+
+// Increments a value of the storage of the user who runs the script
+func (self *Executor) StorageIncrement(key string, global bool) (int, error) {
+	if err := CheckStorageModification(self.Username, global); err != nil {
+		return 0, err
+	}
+	return IncrementStorageValue(self.Username, global, key)
+}
+
+func (self *DryRunExecutor) StorageSet(key string, value string, global bool) error {
+	if err := CheckStorageModification(self.Username, global); err != nil {
+		return err
+	}
+	if err := validateStorageEntry(key, value); err != nil {
+		return err
+	}
+	self.recorder.record(DryRunStorageSet, key, fmt.Sprintf("Would set %s '%s' to '%s'", storageName(global), key, value))
+	return nil
+}
+
+func (self *DryRunExecutor) StorageDelete(key string, global bool) error {
+	if err := CheckStorageModification(self.Username, global); err != nil {
+		return err
+	}
+	self.recorder.record(DryRunStorageDelete, key, fmt.Sprintf("Would delete %s '%s'", storageName(global), key))
+	return nil
+}
+
+// Returns the incremented value without storing it
+func (self *DryRunExecutor) StorageIncrement(key string, global bool) (int, error) {
+	if err := CheckStorageModification(self.Username, global); err != nil {
+		return 0, err
+	}
+	value, found, err := self.StorageGet(key, global)
+	if err != nil {
+		return 0, err
+	}
+	counter, err := parseStorageCounter(key, value, found)
+	if err != nil {
+		return 0, err
+	}
+	counter++
+	self.recorder.record(DryRunStorageSet, key, fmt.Sprintf("Would set %s '%s' to '%d'", storageName(global), key, counter))
+	return counter, nil
+}
+
+// Describes the storage in the messages of dry runs
+func storageName(global bool) string {
+	if global {
+		return "global storage entry"
+	}
+	return "storage entry"
+}
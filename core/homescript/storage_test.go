@@ -0,0 +1,141 @@
+package homescript
+
+import (
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestStorageBuiltins(t *testing.T) {
+	table := []struct {
+		Code       string
+		Output     string
+		FirstError string
+	}{
+		{
+			Code:   "storageSet('storage_test', 'on'); print(storageGet('storage_test'))",
+			Output: "on",
+		},
+		{
+			Code:   "print(storageHas('storage_test'), storageHas('storage_test_missing'))",
+			Output: "truefalse",
+		},
+		{
+			Code:   "storageDelete('storage_test'); print(storageHas('storage_test'), storageGet('storage_test'))",
+			Output: "false",
+		},
+		{
+			Code:   "print(storageIncrement('storage_test_counter'), storageIncrement('storage_test_counter'))",
+			Output: "12",
+		},
+		{
+			Code:   "globalStorageSet('storage_test', 'global'); print(storageHas('storage_test'), globalStorageGet('storage_test'))",
+			Output: "falseglobal",
+		},
+		{
+			Code:       "storageSet('storage_test', 'on'); storageIncrement('storage_test')",
+			FirstError: "Storage entry 'storage_test' cannot be incremented: 'on' is not an integer",
+		},
+		{
+			Code:       "storageSet('', 'on')",
+			FirstError: "Storage keys must not be empty and must not exceed 50 characters",
+		},
+	}
+	for _, test := range table {
+		output, _, errors := RunSaved("admin", database.Homescript{
+			Id:    "storage_test",
+			Owner: "admin",
+			Code:  test.Code,
+		}, TriggerApi, nil)
+		if len(errors) > 0 {
+			if errors[0].Message != test.FirstError {
+				t.Errorf("Unexpected error for code '%s': want: %s got: %s", test.Code, test.FirstError, errors[0].Message)
+				return
+			}
+			continue
+		}
+		if test.FirstError != "" {
+			t.Errorf("Expected error for code '%s': want: %s got: none", test.Code, test.FirstError)
+			return
+		}
+		if output != test.Output {
+			t.Errorf("Unexpected output for code '%s': want: %s got: %s", test.Code, test.Output, output)
+			return
+		}
+	}
+	for _, key := range []string{"storage_test", "storage_test_counter"} {
+		if err := DeleteStorageValue("admin", false, key); err != nil {
+			t.Error(err.Error())
+			return
+		}
+	}
+	if err := DeleteStorageValue("admin", true, "storage_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
+
+// The dry run must not modify the storage
+func TestDryRunStorage(t *testing.T) {
+	_, _, errors, actions := DryRun("admin", "dry_run_storage_test", "storageSet('dry_run_storage_test', 'on')", nil)
+	if len(errors) > 0 {
+		t.Error(errors[0].Message)
+		return
+	}
+	if len(actions) != 1 || actions[0].Type != DryRunStorageSet {
+		t.Errorf("Unexpected recorded actions: %v", actions)
+		return
+	}
+	_, found, err := GetStorageValue("admin", false, "dry_run_storage_test")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if found {
+		t.Error("Dry run modified the storage")
+		return
+	}
+}
+
+// Modifying the global storage requires the permission to manage Homescripts, reading it does not
+func TestGlobalStoragePermission(t *testing.T) {
+	if err := database.AddUser(database.FullUser{
+		Username: "storage_test",
+	}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := SetStorageValue("admin", true, "storage_permission_test", "1"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	for _, code := range []string{
+		"globalStorageSet('storage_permission_test', '2')",
+		"globalStorageDelete('storage_permission_test')",
+		"globalStorageIncrement('storage_permission_test')",
+	} {
+		_, _, errors := Run("storage_test", "storage_test", code, TriggerApi)
+		if len(errors) == 0 {
+			t.Errorf("Code '%s' modified the global storage without permission", code)
+			return
+		}
+		_, _, errors, actions := DryRun("storage_test", "storage_test", code, nil)
+		if len(errors) == 0 || len(actions) != 0 {
+			t.Errorf("Dry run of code '%s' accepted a global storage modification without permission", code)
+			return
+		}
+	}
+	output, _, errors := Run("storage_test", "storage_test", "print(globalStorageGet('storage_permission_test'))", TriggerApi)
+	if len(errors) > 0 {
+		t.Error(errors[0].Message)
+		return
+	}
+	if output != "1" {
+		t.Errorf("Global storage was modified without permission: want: 1 got: %s", output)
+		return
+	}
+	if err := DeleteStorageValue("admin", true, "storage_permission_test"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+}
@@ -0,0 +1,32 @@
+package homescript
+
+// Describes what happened during a streamed Homescript run
+type StreamEventType string
+
+const (
+	StreamEventOutput StreamEventType = "output" // The script has printed something
+	StreamEventSwitch StreamEventType = "switch" // The script has changed the power state of a switch
+	StreamEventError  StreamEventType = "error"  // An action of the script has failed
+)
+
+// Is emitted while a streamed Homescript is running
+// Only the fields which belong to the event's type are set
+type StreamEvent struct {
+	Type    StreamEventType `json:"type"`
+	Output  string          `json:"output,omitempty"`
+	Switch  string          `json:"switch,omitempty"`
+	PowerOn bool            `json:"powerOn"`
+	Message string          `json:"message,omitempty"`
+}
+
+// Is called for every event of a streamed run
+// Because an interrupted interpreter may still finish its current builtin, the function may be called after the run has returned
+type StreamFunc func(event StreamEvent)
+
+// Forwards an event to the listener of the executor, if there is one
+func (self *Executor) emit(event StreamEvent) {
+	if self.stream == nil {
+		return
+	}
+	self.stream(event)
+}
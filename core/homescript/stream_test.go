@@ -0,0 +1,52 @@
+package homescript
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestRunStreaming(t *testing.T) {
+	var m sync.Mutex
+	events := make([]StreamEvent, 0)
+	output, exitCode, errors := RunStreaming(
+		context.Background(),
+		"admin",
+		"stream_test",
+		"print('hello'); print(' world'); switch('does_not_exist', on)",
+		TriggerLive,
+		func(event StreamEvent) {
+			m.Lock()
+			defer m.Unlock()
+			events = append(events, event)
+		},
+	)
+	if output != "hello world" {
+		t.Errorf("Unexpected output: want: %s got: %s", "hello world", output)
+		return
+	}
+	if exitCode != 1 || len(errors) == 0 {
+		t.Errorf("Script did not fail: exit code: %d errors: %v", exitCode, errors)
+		return
+	}
+	m.Lock()
+	defer m.Unlock()
+	want := []StreamEvent{
+		{Type: StreamEventOutput, Output: "hello"},
+		{Type: StreamEventOutput, Output: " world"},
+		{Type: StreamEventError, Switch: "does_not_exist", PowerOn: true},
+	}
+	if len(events) != len(want) {
+		t.Errorf("Unexpected amount of events: want: %d got: %d (%v)", len(want), len(events), events)
+		return
+	}
+	for index, event := range events {
+		if event.Type != want[index].Type ||
+			event.Output != want[index].Output ||
+			event.Switch != want[index].Switch ||
+			event.PowerOn != want[index].PowerOn {
+			t.Errorf("Unexpected event at index %d: want: %v got: %v", index, want[index], event)
+			return
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package homescript
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+var (
+	ErrWebhookNotFound    = errors.New("no webhook with this token exists")
+	ErrWebhookDisabled    = errors.New("webhook is disabled")
+	ErrWebhookRateLimited = errors.New("rate limit of webhook exceeded")
+	ErrWebhookForbidden   = errors.New("owner of webhook is not allowed to use Homescript")
+)
+
+// Contains the time of each call of a webhook during the last minute, the key is the webhook's id
+var webhookCalls = struct {
+	m     sync.Mutex
+	items map[uint][]time.Time
+}{
+	items: make(map[uint][]time.Time),
+}
+
+// Generates a new random webhook token and returns it together with its hash
+// Only the hash is stored, the token is shown to the user once
+func GenerateWebhookToken() (string, string, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	return token, HashWebhookToken(token), nil
+}
+
+// Returns the hash under which a webhook token is stored
+func HashWebhookToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// Registers a call of a webhook and returns false if the webhook has been called too often during the last minute
+func allowWebhookCall(id uint, rateLimit uint) bool {
+	webhookCalls.m.Lock()
+	defer webhookCalls.m.Unlock()
+	now := time.Now()
+	recentCalls := make([]time.Time, 0)
+	for _, call := range webhookCalls.items[id] {
+		if now.Sub(call) < time.Minute {
+			recentCalls = append(recentCalls, call)
+		}
+	}
+	if uint(len(recentCalls)) >= rateLimit {
+		webhookCalls.items[id] = recentCalls
+		return false
+	}
+	webhookCalls.items[id] = append(recentCalls, now)
+	return true
+}
+
+// Forgets the recent calls of a webhook, is used once the webhook has been deleted
+func resetWebhookCalls(id uint) {
+	webhookCalls.m.Lock()
+	defer webhookCalls.m.Unlock()
+	delete(webhookCalls.items, id)
+}
+
+// Deletes a webhook, its token becomes invalid immediately
+func DeleteWebhook(id uint) error {
+	if err := database.DeleteWebhookById(id); err != nil {
+		return err
+	}
+	resetWebhookCalls(id)
+	return nil
+}
+
+// Runs the Homescript of the webhook with the given token as the webhook's owner
+// The script can read the request using `arg('method')`, `arg('body')` and `arg('query.<key>')`
+func RunWebhook(token string, method string, query url.Values, body string) (string, int, []HomescriptError, error) {
+	webhook, found, err := database.GetWebhookByTokenHash(HashWebhookToken(token))
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if !found {
+		return "", 0, nil, ErrWebhookNotFound
+	}
+	if !webhook.Enabled {
+		return "", 0, nil, ErrWebhookDisabled
+	}
+	if !allowWebhookCall(webhook.Id, webhook.RateLimit) {
+		return "", 0, nil, ErrWebhookRateLimited
+	}
+	hasPermission, err := database.UserHasPermission(webhook.Owner, database.PermissionHomescript)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if !hasPermission {
+		return "", 0, nil, ErrWebhookForbidden
+	}
+	homescriptItem, found, err := database.GetUserHomescriptById(webhook.HomescriptId, webhook.Owner)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	if !found {
+		return "", 0, nil, ErrWebhookNotFound
+	}
+	arguments := map[string]string{
+		"method": method,
+		"body":   body,
+	}
+	for key := range query {
+		arguments[fmt.Sprintf("query.%s", key)] = query.Get(key)
+	}
+	// Changes of users who have been granted edit access must not run with the owner's permissions
+	code, err := getOwnerCode(homescriptItem)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	log.Debug(fmt.Sprintf("Webhook '%s' (%d) is running Homescript '%s' of user '%s'", webhook.Name, webhook.Id, webhook.HomescriptId, webhook.Owner))
+	output, exitCode, hmsErrors := run(context.Background(), runConfig{
+		username:     webhook.Owner,
+		homescriptId: homescriptItem.Id,
+		scriptLabel:  homescriptItem.Id,
+		code:         code,
+		trigger:      TriggerWebhook,
+		arguments:    arguments,
+		callStack:    rootCallStack(homescriptItem.Id),
+		ownerCode:    true,
+	})
+	return output, exitCode, hmsErrors, nil
+}
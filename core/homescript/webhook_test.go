@@ -0,0 +1,61 @@
+package homescript
+
+import (
+	"testing"
+)
+
+func TestGenerateWebhookToken(t *testing.T) {
+	token, hash, err := GenerateWebhookToken()
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(token) != 64 || len(hash) != 64 {
+		t.Errorf("Unexpected token or hash length: token: %d hash: %d", len(token), len(hash))
+		return
+	}
+	if HashWebhookToken(token) != hash {
+		t.Error("Hash of token does not match the generated hash")
+		return
+	}
+	otherToken, _, err := GenerateWebhookToken()
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if otherToken == token {
+		t.Error("Generated the same token twice")
+	}
+}
+
+func TestAllowWebhookCall(t *testing.T) {
+	for call := 0; call < 3; call++ {
+		if !allowWebhookCall(42, 3) {
+			t.Errorf("Call %d was rejected although it is within the rate limit", call)
+			return
+		}
+	}
+	if allowWebhookCall(42, 3) {
+		t.Error("Call was allowed although the rate limit was exceeded")
+		return
+	}
+	// Other webhooks are not affected
+	if !allowWebhookCall(43, 3) {
+		t.Error("Call of another webhook was rejected")
+		return
+	}
+	// The calls of a deleted webhook are forgotten
+	resetWebhookCalls(42)
+	webhookCalls.m.Lock()
+	_, exists := webhookCalls.items[42]
+	webhookCalls.m.Unlock()
+	if exists {
+		t.Error("Calls of webhook were kept after it was reset")
+	}
+}
+
+func TestRunWebhook(t *testing.T) {
+	if _, _, _, err := RunWebhook("invalid", "POST", nil, ""); err != ErrWebhookNotFound {
+		t.Errorf("Unexpected error for invalid token: want: %v got: %v", ErrWebhookNotFound, err)
+	}
+}
@@ -38,6 +38,9 @@ func ActivateAutomationSystem() error {
 			log.Debug(fmt.Sprintf("Skipping activation of automation %d: automation is disabled", automation.Id))
 			continue // Skip disabled automations
 		}
+		if !isTimeBased(automation.Trigger) {
+			continue // Is started by its trigger instead of the scheduler
+		}
 		automationJob := scheduler.Cron(automation.CronExpression)
 		automationJob.Tag(fmt.Sprintf("%d", automation.Id))
 		_, err := automationJob.Do(automationRunnerFunc, automation.Id)
@@ -60,7 +63,7 @@ func DeactivateAutomationSystem() error {
 		return err // This is a critical error which can not be recovered from
 	}
 	for _, automation := range automations {
-		if automation.Enabled {
+		if automation.Enabled && isTimeBased(automation.Trigger) {
 			if err := scheduler.RemoveByTag(fmt.Sprintf("%d", automation.Id)); err != nil {
 				log.Error(fmt.Sprintf("Failed to deactivate automation '%d': could not stop scheduler: %s", automation.Id, err.Error()))
 				continue
@@ -85,6 +88,13 @@ func Init() error {
 	}
 	scheduler = gocron.NewScheduler(time.Local)
 	scheduler.TagsUnique()
+	// Weather triggers are evaluated periodically, independent of the cron jobs of the automations
+	if _, err := scheduler.Every(weatherTriggerInterval).Tag("weatherTriggers").Do(evaluateWeatherTriggers); err != nil {
+		log.Error("Failed to initialize automation scheduler: could not setup weather trigger evaluation: ", err.Error())
+		return err
+	}
+	// Event-triggered automations are started by the event bus
+	subscribeToEvents()
 	if serverConfig.AutomationEnabled {
 		if err := ActivateAutomationSystem(); err != nil {
 			log.Error("Failed to activate automation system: could not activate persistent jobs: ", err.Error())
@@ -1,6 +1,7 @@
 package automation
 
 import (
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -38,16 +39,16 @@ func createMockData() error {
 	if err := database.CreateRoom(database.RoomData{Id: "test_room"}); err != nil {
 		panic(err.Error())
 	}
-	if err := database.CreateSwitch("test_switch", "", "test_room", 0); err != nil {
+	if err := database.CreateSwitch(database.Switch{Id: "test_switch", RoomId: "test_room"}); err != nil {
 		panic(err.Error())
 	}
-	if err := database.CreateSwitch("test_switch_modify", "", "test_room", 0); err != nil {
+	if err := database.CreateSwitch(database.Switch{Id: "test_switch_modify", RoomId: "test_room"}); err != nil {
 		panic(err.Error())
 	}
-	if err := database.CreateSwitch("test_switch_inactive", "", "test_room", 0); err != nil {
+	if err := database.CreateSwitch(database.Switch{Id: "test_switch_inactive", RoomId: "test_room"}); err != nil {
 		panic(err.Error())
 	}
-	if err := database.CreateSwitch("test_switch_abort", "", "test_room", 0); err != nil {
+	if err := database.CreateSwitch(database.Switch{Id: "test_switch_abort", RoomId: "test_room"}); err != nil {
 		panic(err.Error())
 	}
 	_, doesExists, err := database.GetUserHomescriptById("test", "admin")
@@ -156,17 +157,16 @@ func TestAutomation(t *testing.T) {
 		return
 	}
 	// Normal automation
-	if _, err := CreateNewAutomation(
-		"name",
-		"description",
-		uint8(then.Hour()),
-		uint8(then.Minute()),
-		[]uint8{0, 1, 2, 3, 4, 5, 6},
-		"test",
-		"admin",
-		true,
-		database.TimingNormal,
-	); err != nil {
+	if _, err := CreateNewAutomation(database.Automation{
+		Name:           "name",
+		Description:    "description",
+		CronExpression: fmt.Sprintf("%d %d * * *", then.Minute(), then.Hour()),
+		HomescriptId:   "test",
+		Owner:          "admin",
+		Enabled:        true,
+		TimingMode:     database.TimingNormal,
+		Trigger:        database.TriggerCron,
+	}); err != nil {
 		t.Error(err.Error())
 		return
 	}
@@ -202,17 +202,16 @@ func TestModificationToDifferentScript(t *testing.T) {
 		return
 	}
 	// Create initial automation
-	modifyId, err := CreateNewAutomation(
-		"name",
-		"description",
-		uint8(then.Hour()),
-		uint8(then.Minute()),
-		[]uint8{0, 1, 2, 3, 4, 5, 6},
-		"test",
-		"admin",
-		true,
-		database.TimingNormal,
-	)
+	modifyId, err := CreateNewAutomation(database.Automation{
+		Name:           "name",
+		Description:    "description",
+		CronExpression: fmt.Sprintf("%d %d * * *", then.Minute(), then.Hour()),
+		HomescriptId:   "test",
+		Owner:          "admin",
+		Enabled:        true,
+		TimingMode:     database.TimingNormal,
+		Trigger:        database.TriggerCron,
+	})
 	if err != nil {
 		t.Error(err.Error())
 		return
@@ -266,17 +265,16 @@ func TestModificationToAbort(t *testing.T) {
 		return
 	}
 	// Create initial automation
-	abortId, err := CreateNewAutomation(
-		"name",
-		"description",
-		uint8(then.Hour()),
-		uint8(then.Minute()),
-		[]uint8{0, 1, 2, 3, 4, 5, 6},
-		"test_abort",
-		"admin",
-		true,
-		database.TimingNormal,
-	)
+	abortId, err := CreateNewAutomation(database.Automation{
+		Name:           "name",
+		Description:    "description",
+		CronExpression: fmt.Sprintf("%d %d * * *", then.Minute(), then.Hour()),
+		HomescriptId:   "test_abort",
+		Owner:          "admin",
+		Enabled:        true,
+		TimingMode:     database.TimingNormal,
+		Trigger:        database.TriggerCron,
+	})
 	if err != nil {
 		t.Error(err.Error())
 		return
@@ -329,17 +327,15 @@ func TestModificationToAbort(t *testing.T) {
 func TestStartInactiveAutomation(t *testing.T) {
 	now := time.Now()
 	then := now.Add(time.Minute)
-	if _, err := CreateNewAutomation(
-		"name",
-		"description",
-		uint8(then.Hour()),
-		uint8(then.Minute()),
-		[]uint8{0, 1, 2, 3, 4, 5, 6},
-		"test_inactive",
-		"admin",
-		false,
-		database.TimingNormal,
-	); err != nil {
+	if _, err := CreateNewAutomation(database.Automation{
+		Name:           "name",
+		Description:    "description",
+		CronExpression: fmt.Sprintf("%d %d * * *", then.Minute(), then.Hour()),
+		HomescriptId:   "test_inactive",
+		Owner:          "admin",
+		TimingMode:     database.TimingNormal,
+		Trigger:        database.TriggerCron,
+	}); err != nil {
 		t.Error(err.Error())
 		return
 	}
@@ -365,32 +361,30 @@ func TestTimingModes(t *testing.T) {
 		t.Error(err.Error())
 		return
 	}
-	sunriseId, err := CreateNewAutomation(
-		"name",
-		"description",
-		23,
-		59,
-		[]uint8{0, 1, 2, 3, 4, 5, 6},
-		"test",
-		"admin",
-		true,
-		database.TimingSunrise,
-	)
+	sunriseId, err := CreateNewAutomation(database.Automation{
+		Name:           "name",
+		Description:    "description",
+		CronExpression: "59 23 * * *",
+		HomescriptId:   "test",
+		Owner:          "admin",
+		Enabled:        true,
+		TimingMode:     database.TimingSunrise,
+		Trigger:        database.TriggerCron,
+	})
 	if err != nil {
 		t.Error(err.Error())
 		return
 	}
-	sunSetId, err := CreateNewAutomation(
-		"name",
-		"description",
-		23,
-		59,
-		[]uint8{0, 1, 2, 3, 4, 5, 6},
-		"test",
-		"admin",
-		true,
-		database.TimingSunset,
-	)
+	sunSetId, err := CreateNewAutomation(database.Automation{
+		Name:           "name",
+		Description:    "description",
+		CronExpression: "59 23 * * *",
+		HomescriptId:   "test",
+		Owner:          "admin",
+		Enabled:        true,
+		TimingMode:     database.TimingSunset,
+		Trigger:        database.TriggerCron,
+	})
 	if err != nil {
 		t.Error(err.Error())
 		return
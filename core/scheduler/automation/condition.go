@@ -0,0 +1,122 @@
+package automation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/MikMuellerDev/smarthome/services/weather"
+)
+
+// Weather conditions consist of comparisons which are joined by `and`, for example `temperature < 2 and windSpeed >= 10`
+// Each comparison has the form `<field> <operator> <value>`, the operands have to be separated by spaces
+// The field `condition` is compared as a string, for example `condition == Rain`
+
+// Returns the numeric weather values which can be used in conditions
+var conditionFields = map[string]func(weather.Weather) float64{
+	"temperature":             func(data weather.Weather) float64 { return data.Temperature },
+	"feelsLike":               func(data weather.Weather) float64 { return data.FeelsLike },
+	"humidity":                func(data weather.Weather) float64 { return float64(data.Humidity) },
+	"windSpeed":               func(data weather.Weather) float64 { return data.WindSpeed },
+	"rain":                    func(data weather.Weather) float64 { return data.Rain },
+	"forecastRain":            func(data weather.Weather) float64 { return data.ForecastRain },
+	"forecastRainProbability": func(data weather.Weather) float64 { return data.ForecastRainProbability },
+}
+
+var conditionOperators = []string{"<", "<=", ">", ">=", "==", "!="}
+
+type conditionClause struct {
+	field    string
+	operator string
+	number   float64
+	text     string // Is only used by the `condition` field
+}
+
+type WeatherCondition []conditionClause
+
+// Parses a condition expression, returns an error which describes the first invalid part
+func ParseWeatherCondition(expression string) (WeatherCondition, error) {
+	if strings.TrimSpace(expression) == "" {
+		return nil, fmt.Errorf("condition must not be empty")
+	}
+	condition := make(WeatherCondition, 0)
+	for _, part := range strings.Split(expression, " and ") {
+		tokens := strings.Fields(part)
+		if len(tokens) != 3 {
+			return nil, fmt.Errorf("invalid comparison '%s': expected `<field> <operator> <value>`", strings.TrimSpace(part))
+		}
+		clause := conditionClause{field: tokens[0], operator: tokens[1]}
+		validOperator := false
+		for _, operator := range conditionOperators {
+			if clause.operator == operator {
+				validOperator = true
+			}
+		}
+		if !validOperator {
+			return nil, fmt.Errorf("invalid operator '%s': valid operators are %s", clause.operator, strings.Join(conditionOperators, ", "))
+		}
+		if clause.field == "condition" {
+			if clause.operator != "==" && clause.operator != "!=" {
+				return nil, fmt.Errorf("invalid operator '%s': the field `condition` can only be compared using == and !=", clause.operator)
+			}
+			clause.text = tokens[2]
+			condition = append(condition, clause)
+			continue
+		}
+		if _, ok := conditionFields[clause.field]; !ok {
+			return nil, fmt.Errorf("invalid field '%s'", clause.field)
+		}
+		number, err := strconv.ParseFloat(tokens[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value '%s': field '%s' requires a number", tokens[2], clause.field)
+		}
+		clause.number = number
+		condition = append(condition, clause)
+	}
+	return condition, nil
+}
+
+// Returns whether a single comparison is met
+// While the condition is active, thresholds of `<` and `>` comparisons are relaxed by the hysteresis
+// This prevents the trigger from flapping if a value oscillates around its threshold
+func (self conditionClause) evaluate(data weather.Weather, active bool, hysteresis float64) bool {
+	if self.field == "condition" {
+		equal := strings.EqualFold(data.Condition, self.text)
+		return equal == (self.operator == "==")
+	}
+	value := conditionFields[self.field](data)
+	threshold := self.number
+	if active {
+		switch self.operator {
+		case ">", ">=":
+			threshold -= hysteresis
+		case "<", "<=":
+			threshold += hysteresis
+		}
+	}
+	switch self.operator {
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "==":
+		return value == threshold
+	default:
+		return value != threshold
+	}
+}
+
+// Returns whether every comparison of the condition is met
+// `active` specifies whether the condition was met during the previous evaluation
+func (self WeatherCondition) Evaluate(data weather.Weather, active bool, hysteresis float64) bool {
+	for _, clause := range self {
+		if !clause.evaluate(data, active, hysteresis) {
+			return false
+		}
+	}
+	return true
+}
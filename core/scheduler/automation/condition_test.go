@@ -0,0 +1,88 @@
+package automation
+
+import (
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/services/weather"
+)
+
+func TestParseWeatherCondition(t *testing.T) {
+	table := []struct {
+		Expression string
+		Valid      bool
+	}{
+		{"temperature < 2", true},
+		{"temperature < 2 and windSpeed >= 10", true},
+		{"condition == Rain", true},
+		{"condition != Clear and humidity > 80", true},
+		{"forecastRainProbability >= 0.5", true},
+		{"", false},
+		{"temperature <", false},
+		{"temperature => 2", false},
+		{"pressure > 1000", false},
+		{"temperature > warm", false},
+		{"condition > Rain", false},
+	}
+	for _, test := range table {
+		_, err := ParseWeatherCondition(test.Expression)
+		if (err == nil) != test.Valid {
+			t.Errorf("Parsing condition '%s': want valid: %t got error: %v", test.Expression, test.Valid, err)
+			return
+		}
+	}
+}
+
+func TestEvaluateWeatherCondition(t *testing.T) {
+	data := weather.Weather{
+		Condition:   "Rain",
+		Temperature: 1.5,
+		WindSpeed:   12,
+		Humidity:    90,
+	}
+	table := []struct {
+		Expression string
+		Want       bool
+	}{
+		{"temperature < 2", true},
+		{"temperature < 2 and windSpeed >= 10", true},
+		{"temperature < 2 and windSpeed >= 15", false},
+		{"condition == rain", true},
+		{"condition != Rain", false},
+		{"humidity == 90", true},
+	}
+	for _, test := range table {
+		condition, err := ParseWeatherCondition(test.Expression)
+		if err != nil {
+			t.Error(err.Error())
+			return
+		}
+		if got := condition.Evaluate(data, false, 0); got != test.Want {
+			t.Errorf("Evaluating condition '%s': want: %t got: %t", test.Expression, test.Want, got)
+			return
+		}
+	}
+}
+
+func TestWeatherConditionHysteresis(t *testing.T) {
+	condition, err := ParseWeatherCondition("temperature < 2")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	data := weather.Weather{Temperature: 2.5}
+	// An inactive condition uses the exact threshold
+	if condition.Evaluate(data, false, 1) {
+		t.Error("Inactive condition is met although the temperature is above the threshold")
+		return
+	}
+	// An active condition remains met until the value exceeds the threshold plus the hysteresis
+	if !condition.Evaluate(data, true, 1) {
+		t.Error("Active condition is not met although the temperature is within the hysteresis")
+		return
+	}
+	data.Temperature = 3.5
+	if condition.Evaluate(data, true, 1) {
+		t.Error("Active condition is met although the temperature exceeds the hysteresis")
+		return
+	}
+}
@@ -0,0 +1,151 @@
+package automation
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+)
+
+// Time which has to pass without another matching event before an event-triggered automation runs
+// This way, a switch which is toggled several times in a row only runs the automation once
+// Must be a var in order to be overridden by the unit test
+var eventTriggerDebounce = 5 * time.Second
+
+// Runs an automation once its debounce time has passed
+// Must be a var in order to be overridden by the unit test
+var runEventTriggeredAutomation = automationRunnerFunc
+
+// Amount of events of each topic which can wait to be handled before further events are dropped
+const eventQueueSize = 100
+
+// Maps each event trigger to the topic of the event bus it reacts to
+var eventTopics = map[database.TriggerType]event.Topic{
+	database.TriggerSwitch:   event.TopicSwitchChanged,
+	database.TriggerNode:     event.TopicNodeStatus,
+	database.TriggerLogin:    event.TopicUserLogin,
+	database.TriggerReminder: event.TopicReminderOverdue,
+}
+
+// Contains the pending runs of event-triggered automations
+var eventTriggers = struct {
+	m      sync.Mutex
+	timers map[uint]*time.Timer
+}{
+	timers: make(map[uint]*time.Timer),
+}
+
+// The handlers are only subscribed once, even if the automation system is initialized multiple times
+var subscribeEventsOnce sync.Once
+
+// Subscribes to every topic which can trigger automations
+func subscribeToEvents() {
+	subscribeEventsOnce.Do(func() {
+		for _, topic := range eventTopics {
+			// Handling an event requires database queries, so the publisher must not wait for it
+			event.SubscribeAsync(topic, eventQueueSize, event.OverflowDrop, handleEvent)
+		}
+	})
+}
+
+// Returns whether an event matches the trigger of an automation
+func matchesEvent(automation database.Automation, message event.Message) bool {
+	if topic, isEvent := eventTopics[automation.Trigger]; !isEvent || topic != message.Topic {
+		return false
+	}
+	switch payload := message.Payload.(type) {
+	case event.SwitchChanged:
+		return payload.SwitchId == automation.TriggerSubject && matchesState(automation.TriggerState, payload.PowerOn, "on", "off")
+	case event.NodeStatus:
+		return payload.Url == automation.TriggerSubject && matchesState(automation.TriggerState, payload.Online, "online", "offline")
+	case event.UserLogin:
+		// Without a subject, the automation reacts to logins of its owner
+		if automation.TriggerSubject == "" {
+			return payload.Username == automation.Owner
+		}
+		return payload.Username == automation.TriggerSubject
+	case event.ReminderOverdue:
+		// Only reminders of the owner can trigger the automation
+		if payload.Owner != automation.Owner {
+			return false
+		}
+		return automation.TriggerSubject == "" || fmt.Sprintf("%d", payload.ReminderId) == automation.TriggerSubject
+	default:
+		return false
+	}
+}
+
+// Returns whether a boolean state matches the required state of a trigger, an empty requirement matches every state
+func matchesState(required string, state bool, trueState string, falseState string) bool {
+	if required == "" {
+		return true
+	}
+	if state {
+		return required == trueState
+	}
+	return required == falseState
+}
+
+// Schedules a run of every enabled automation whose trigger matches the event
+func handleEvent(message event.Message) {
+	config, found, err := database.GetServerConfiguration()
+	if err != nil || !found {
+		log.Error("Failed to handle event: could not retrieve server configuration")
+		return
+	}
+	if !config.AutomationEnabled {
+		return
+	}
+	automations, err := database.GetAutomations()
+	if err != nil {
+		log.Error("Failed to handle event: database failure: ", err.Error())
+		return
+	}
+	for _, automation := range automations {
+		if automation.Enabled && matchesEvent(automation, message) {
+			log.Debug(fmt.Sprintf("Event '%s' triggered automation '%d'", message.Topic, automation.Id))
+			debounceEventTrigger(automation.Id)
+		}
+	}
+}
+
+// Runs the automation after the debounce time has passed
+// If the automation is triggered again during this time, the pending run is postponed
+func debounceEventTrigger(id uint) {
+	eventTriggers.m.Lock()
+	defer eventTriggers.m.Unlock()
+	scheduleEventTrigger(id)
+}
+
+// Like `debounceEventTrigger` but requires the caller to hold the lock of the event triggers
+func scheduleEventTrigger(id uint) {
+	if pending, exists := eventTriggers.timers[id]; exists {
+		pending.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(eventTriggerDebounce, func() {
+		eventTriggers.m.Lock()
+		// The timer could have been replaced or cancelled while this function was waiting for the lock
+		// Stopping it was too late in this case, so the run is skipped here instead
+		if eventTriggers.timers[id] != timer {
+			eventTriggers.m.Unlock()
+			return
+		}
+		delete(eventTriggers.timers, id)
+		eventTriggers.m.Unlock()
+		runEventTriggeredAutomation(id)
+	})
+	eventTriggers.timers[id] = timer
+}
+
+// Cancels a pending run of an event-triggered automation, is used if an automation is modified or removed
+func resetEventTrigger(id uint) {
+	eventTriggers.m.Lock()
+	defer eventTriggers.m.Unlock()
+	if pending, exists := eventTriggers.timers[id]; exists {
+		pending.Stop()
+		delete(eventTriggers.timers, id)
+	}
+}
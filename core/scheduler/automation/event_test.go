@@ -0,0 +1,114 @@
+package automation
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+)
+
+func TestMatchesEvent(t *testing.T) {
+	table := []struct {
+		Automation database.Automation
+		Message    event.Message
+		Want       bool
+	}{
+		// Switch triggers match their subject and optionally the new state
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerSwitch, TriggerSubject: "s1", TriggerState: "on"},
+			Message:    event.Message{Topic: event.TopicSwitchChanged, Payload: event.SwitchChanged{SwitchId: "s1", PowerOn: true}},
+			Want:       true,
+		},
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerSwitch, TriggerSubject: "s1", TriggerState: "on"},
+			Message:    event.Message{Topic: event.TopicSwitchChanged, Payload: event.SwitchChanged{SwitchId: "s1", PowerOn: false}},
+			Want:       false,
+		},
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerSwitch, TriggerSubject: "s1"},
+			Message:    event.Message{Topic: event.TopicSwitchChanged, Payload: event.SwitchChanged{SwitchId: "s1", PowerOn: false}},
+			Want:       true,
+		},
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerSwitch, TriggerSubject: "s1"},
+			Message:    event.Message{Topic: event.TopicSwitchChanged, Payload: event.SwitchChanged{SwitchId: "s2", PowerOn: true}},
+			Want:       false,
+		},
+		// Events of other topics never match
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerNode, TriggerSubject: "s1"},
+			Message:    event.Message{Topic: event.TopicSwitchChanged, Payload: event.SwitchChanged{SwitchId: "s1", PowerOn: true}},
+			Want:       false,
+		},
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerCron},
+			Message:    event.Message{Topic: event.TopicSwitchChanged, Payload: event.SwitchChanged{SwitchId: "s1", PowerOn: true}},
+			Want:       false,
+		},
+		// Login triggers without a subject only react to the owner
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerLogin},
+			Message:    event.Message{Topic: event.TopicUserLogin, Payload: event.UserLogin{Username: "admin"}},
+			Want:       true,
+		},
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerLogin},
+			Message:    event.Message{Topic: event.TopicUserLogin, Payload: event.UserLogin{Username: "user"}},
+			Want:       false,
+		},
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerLogin, TriggerSubject: "user"},
+			Message:    event.Message{Topic: event.TopicUserLogin, Payload: event.UserLogin{Username: "user"}},
+			Want:       true,
+		},
+		// Reminder triggers only react to reminders of the owner
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerReminder},
+			Message:    event.Message{Topic: event.TopicReminderOverdue, Payload: event.ReminderOverdue{ReminderId: 1, Owner: "admin"}},
+			Want:       true,
+		},
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerReminder},
+			Message:    event.Message{Topic: event.TopicReminderOverdue, Payload: event.ReminderOverdue{ReminderId: 1, Owner: "user"}},
+			Want:       false,
+		},
+		{
+			Automation: database.Automation{Owner: "admin", Trigger: database.TriggerReminder, TriggerSubject: "2"},
+			Message:    event.Message{Topic: event.TopicReminderOverdue, Payload: event.ReminderOverdue{ReminderId: 1, Owner: "admin"}},
+			Want:       false,
+		},
+	}
+	for index, test := range table {
+		if got := matchesEvent(test.Automation, test.Message); got != test.Want {
+			t.Errorf("Test %d: want: %t got: %t", index, test.Want, got)
+			return
+		}
+	}
+}
+
+// A trigger which arrives after the pending run has fired but before it has acquired the lock must not cause two runs
+func TestDebounceEventTrigger(t *testing.T) {
+	debounceBefore, runnerBefore := eventTriggerDebounce, runEventTriggeredAutomation
+	defer func() {
+		eventTriggerDebounce, runEventTriggeredAutomation = debounceBefore, runnerBefore
+	}()
+	eventTriggerDebounce = 50 * time.Millisecond
+	var runs int32
+	runEventTriggeredAutomation = func(id uint) {
+		atomic.AddInt32(&runs, 1)
+	}
+	id := uint(4242)
+
+	// The first timer fires while the lock is held, then it is replaced by a second trigger
+	eventTriggers.m.Lock()
+	scheduleEventTrigger(id)
+	time.Sleep(2 * eventTriggerDebounce)
+	scheduleEventTrigger(id)
+	eventTriggers.m.Unlock()
+	time.Sleep(3 * eventTriggerDebounce)
+	if count := atomic.LoadInt32(&runs); count != 1 {
+		t.Errorf("Unexpected amount of runs after a replaced trigger: want: 1 got: %d", count)
+	}
+}
@@ -2,6 +2,7 @@ package automation
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/MikMuellerDev/smarthome/core/database"
 	"github.com/MikMuellerDev/smarthome/core/event"
@@ -9,10 +10,63 @@ import (
 	"github.com/MikMuellerDev/smarthome/core/user"
 )
 
+// Amount of runs which are kept in the history of each automation
+const runHistoryRetention = 50
+
+// Describes a single execution of an automation
+type automationRun struct {
+	id        uint
+	manual    bool
+	startedAt time.Time
+}
+
+// Publishes the progress of an automation run on the event bus
+// Once the run has finished, its outcome is also added to the run history
+// Runs of automations which could not be retrieved are only logged, because the automation has no owner and may already be deleted
+func publishRunStatus(run automationRun, job database.Automation, status event.RunStatus, errorMessage string) {
+	event.Publish(event.JobStatus{
+		Kind:   event.TopicAutomationRun,
+		Id:     run.id,
+		Name:   job.Name,
+		Owner:  job.Owner,
+		Status: status,
+		Error:  errorMessage,
+	})
+	if status == event.RunStarted {
+		return
+	}
+	if _, err := database.AddAutomationRun(database.AutomationRun{
+		AutomationId: run.id,
+		Manual:       run.manual,
+		StartedAt:    run.startedAt,
+		FinishedAt:   time.Now(),
+		Status:       string(status),
+		Error:        errorMessage,
+	}); err != nil {
+		log.Error(fmt.Sprintf("Failed to add run of automation '%d' to its history: %s", run.id, err.Error()))
+		return
+	}
+	if err := database.PruneAutomationRuns(run.id, runHistoryRetention); err != nil {
+		log.Error(fmt.Sprintf("Failed to prune run history of automation '%d': %s", run.id, err.Error()))
+	}
+}
+
 // Is called when the scheduler executes the given automation
 // The automationRunnerFunc automatically tries to fetch the required configuration from the provided id
 // Error handling is accomplished by logging to the internal event system and notifying the user about their automations failure
 func automationRunnerFunc(id uint) {
+	runAutomation(automationRun{id: id, startedAt: time.Now()})
+}
+
+// Runs the given automation immediately, regardless of its trigger
+// Unlike a scheduled run, a manual run also executes disabled automations, ignores the weather condition and does not alter the next execution time
+func RunAutomationNow(id uint) {
+	runAutomation(automationRun{id: id, manual: true, startedAt: time.Now()})
+}
+
+// Executes an automation, is used by scheduled, event-triggered and manual runs
+func runAutomation(run automationRun) {
+	id := run.id
 	job, jobFound, err := database.GetAutomationById(id)
 	if err != nil {
 		log.Error(fmt.Sprintf("Automation with id: '%d' could not be executed: database failure: %s", id, err.Error()))
@@ -49,8 +103,9 @@ func automationRunnerFunc(id uint) {
 		return
 	}
 	// Notify and reminf the user about the disabled automation
-	if !job.Enabled {
+	if !job.Enabled && !run.manual {
 		log.Info(fmt.Sprintf("Automation '%s' was not executed because it is deactivated", job.Name))
+		publishRunStatus(run, job, event.RunSkipped, "automation is disabled")
 		if err := user.Notify(
 			job.Owner,
 			"Automation Skipped",
@@ -63,9 +118,10 @@ func automationRunnerFunc(id uint) {
 		return
 	}
 	// If the timing mode is set to either 'sunrise' or 'sunset', a new time with according cron-expression should be generated
-	if job.TimingMode != database.TimingNormal {
-		if err := updateJobTime(id, job.TimingMode == database.TimingSunrise); err != nil {
+	if job.TimingMode != database.TimingNormal && !run.manual {
+		if err := updateJobTime(id); err != nil {
 			log.Error("Failed to run automation: could not update next launch time: ", err.Error())
+			publishRunStatus(run, job, event.RunFailed, fmt.Sprintf("could not update next launch time: %s", err.Error()))
 			event.Error(
 				"Automation Failed",
 				fmt.Sprintf("Automation '%s' failed because its next launch time could not be adjusted: %s", job.Name, err.Error()),
@@ -82,10 +138,34 @@ func automationRunnerFunc(id uint) {
 			return
 		}
 	}
+	// An automation with a weather condition is skipped if the condition is not met
+	// For weather triggers, the condition has already been checked by the trigger
+	if job.Trigger != database.TriggerWeather && job.Condition != "" && !run.manual {
+		conditionMet, err := checkWeatherCondition(job.Condition)
+		if err != nil {
+			log.Warn(fmt.Sprintf("Automation '%s' was skipped because its weather condition could not be checked: %s", job.Name, err.Error()))
+			publishRunStatus(run, job, event.RunSkipped, fmt.Sprintf("weather condition could not be checked: %s", err.Error()))
+			if err := user.Notify(
+				job.Owner,
+				"Automation Skipped",
+				fmt.Sprintf("Automation '%s' was not executed because its weather condition '%s' could not be checked: %s", job.Name, job.Condition, err.Error()),
+				user.NotificationLevelWarn,
+			); err != nil {
+				log.Error("Failed to notify user: ", err.Error())
+			}
+			return
+		}
+		if !conditionMet {
+			log.Debug(fmt.Sprintf("Automation '%s' was skipped because its weather condition '%s' is not met", job.Name, job.Condition))
+			publishRunStatus(run, job, event.RunSkipped, "weather condition is not met")
+			return
+		}
+	}
 	log.Debug(fmt.Sprintf("Automation '%d' is running", id))
 	_, scriptExists, err := database.GetUserHomescriptById(job.HomescriptId, job.Owner)
 	if err != nil {
 		log.Error(fmt.Sprintf("Automation '%s' failed because its Homescript Id could not be retrieved from the database: %s", job.Name, err.Error()))
+		publishRunStatus(run, job, event.RunFailed, "database failure")
 		event.Error(
 			"Automation Failed",
 			fmt.Sprintf("Automation '%s' could not be executed because it s Homescript Id could not be retrieved from the database: %s", job.Name, err.Error()),
@@ -103,6 +183,7 @@ func automationRunnerFunc(id uint) {
 	}
 	if !scriptExists {
 		log.Error(fmt.Sprintf("Automation '%s' failed because its Homescript Id: '%s' is invalid", job.Name, job.HomescriptId))
+		publishRunStatus(run, job, event.RunFailed, fmt.Sprintf("Homescript '%s' does not exist", job.HomescriptId))
 		event.Error(
 			"Automation Failed",
 			fmt.Sprintf("Automation '%s' failed because its Homescript Id: '%s' is invalid. This indicates a bad configuration.", job.Name, job.HomescriptId),
@@ -118,9 +199,17 @@ func automationRunnerFunc(id uint) {
 		}
 		return
 	}
-	output, exitCode, err := homescript.RunById(job.Owner, job.HomescriptId)
+	publishRunStatus(run, job, event.RunStarted, "")
+	var output string
+	var exitCode int
+	if job.HomescriptRevision != nil {
+		output, exitCode, err = homescript.RunRevisionById(job.Owner, job.HomescriptId, *job.HomescriptRevision, homescript.TriggerAutomation)
+	} else {
+		output, exitCode, err = homescript.RunById(job.Owner, job.HomescriptId, homescript.TriggerAutomation)
+	}
 	if err != nil {
 		log.Warn(fmt.Sprintf("Automation '%s' failed during the execution of Homescript: '%s', which terminated abnormally", job.Name, job.HomescriptId))
+		publishRunStatus(run, job, event.RunFailed, err.Error())
 		event.Error(
 			"Automation Failed",
 			fmt.Sprintf("Automation '%s' failed during execution of the referenced Homescript (id: '%s'). Error: %s", job.Name, job.HomescriptId, err.Error()),
@@ -136,6 +225,7 @@ func automationRunnerFunc(id uint) {
 		}
 		return
 	}
+	publishRunStatus(run, job, event.RunSucceeded, "")
 	event.Debug(
 		"Automation Executed Successfully",
 		fmt.Sprintf("Automation '%d' of user '%s' has executed successfully. HMS-Exit code: %d, HMS-Output: '%s'", id, job.Owner, exitCode, output),
@@ -3,6 +3,7 @@ package automation
 import (
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/nathan-osman/go-sunrise"
@@ -18,58 +19,108 @@ type SunTime struct {
 	Minute uint
 }
 
-// Returns (sunrise, sunset) based on the provided coordinates which are stored in the server configuration
-func CalculateSunRiseSet(lat float32, lon float32) (SunTime, SunTime) {
-	sunRise, sunSet := sunrise.SunriseSunset(
-		float64(lat), float64(lon),
-		time.Now().Year(), time.Now().Month(), time.Now().Day(),
+// Elevation of the center of the sun in degrees at which each variant of sunrise and sunset takes place
+// The default value accounts for the refraction of the atmosphere and the radius of the sun
+var twilightElevations = map[database.Twilight]float64{
+	database.TwilightNone:         -0.833,
+	database.TwilightCivil:        -6,
+	database.TwilightNautical:     -12,
+	database.TwilightAstronomical: -18,
+}
+
+// Largest offset in minutes which can be applied to the time of sunrise or sunset
+const MaxSunOffset = 240
+
+// Returns whether the twilight variant is known
+func IsValidTwilight(twilight database.Twilight) bool {
+	_, valid := twilightElevations[twilight]
+	return valid
+}
+
+// Calculates the time at which the sun reaches the elevation of the twilight variant on the given day
+// If `rising` is true, the morning event is returned, otherwise the evening event
+// Returns false if the sun does not reach the elevation on this day, for example during polar summer
+func calculateSunEvent(lat float64, lon float64, day time.Time, twilight database.Twilight, rising bool) (time.Time, bool) {
+	var (
+		d                 = sunrise.MeanSolarNoon(lon, day.Year(), day.Month(), day.Day())
+		solarAnomaly      = sunrise.SolarMeanAnomaly(d)
+		equationOfCenter  = sunrise.EquationOfCenter(solarAnomaly)
+		eclipticLongitude = sunrise.EclipticLongitude(solarAnomaly, equationOfCenter, d)
+		solarTransit      = sunrise.SolarTransit(d, solarAnomaly, eclipticLongitude)
+		declination       = sunrise.Declination(eclipticLongitude) * sunrise.Degree
+		latitude          = lat * sunrise.Degree
 	)
-	return SunTime{
-			uint(sunRise.Local().Hour()), uint(sunRise.Local().Minute()),
-		}, SunTime{
-			uint(sunSet.Local().Hour()), uint(sunSet.Local().Minute()),
-		}
+	// `sunrise.HourAngle` only supports the default elevation, so the hour angle is calculated here
+	cosHourAngle := (math.Sin(twilightElevations[twilight]*sunrise.Degree) - math.Sin(latitude)*math.Sin(declination)) /
+		(math.Cos(latitude) * math.Cos(declination))
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return time.Time{}, false
+	}
+	frac := math.Acos(cosHourAngle) / sunrise.Degree / 360
+	if rising {
+		return sunrise.JulianDayToTime(solarTransit - frac), true
+	}
+	return sunrise.JulianDayToTime(solarTransit + frac), true
+}
+
+// Calculates the local time at which an automation with the given sun timing should run on the given day
+// The offset is specified in minutes, negative values lead to an earlier time
+func calculateSunTime(lat float32, lon float32, day time.Time, timingMode database.TimingMode, twilight database.Twilight, offset int) (SunTime, bool) {
+	eventTime, ok := calculateSunEvent(float64(lat), float64(lon), day, twilight, timingMode == database.TimingSunrise)
+	if !ok {
+		return SunTime{}, false
+	}
+	eventTime = eventTime.Add(time.Duration(offset) * time.Minute).Local()
+	return SunTime{uint(eventTime.Hour()), uint(eventTime.Minute())}, true
 }
 
-// Given a jobId and whether sunrise or sunset should is activated, the next execution time is modified
-func updateJobTime(id uint, useSunRise bool) error {
+// Generates a new cron expression for an automation which uses the timing mode `sunrise` or `sunset`
+// The days are taken from the current cron expression, the time is calculated for today
+// If the sun does not reach the required elevation today, the current cron expression is kept
+func generateSunCronExpression(cronExpression string, timingMode database.TimingMode, twilight database.Twilight, offset int) (string, error) {
 	// Obtain the server's configuration in order to determine the latitude and longitude
 	config, found, err := database.GetServerConfiguration()
 	if err != nil || !found {
 		log.Error("Failed to update job launch time: could not obtain the server's configuration")
-		return errors.New("could not update launch time: failed to obtain server config")
-	}
-	// Retrieve the current job in order to get its current cron-expression (for the days)
-	job, found, err := database.GetAutomationById(id)
-	if err != nil || !found {
-		return errors.New("could not update launch time: invalid id supplied")
+		return "", errors.New("could not update launch time: failed to obtain server config")
 	}
-	// Calculate both the sunrise and sunset time
-	sunRise, sunSet := CalculateSunRiseSet(config.Latitude, config.Longitude)
-	// Select the time which is desired
-	var finalTime SunTime
-	if useSunRise {
-		finalTime = sunRise
-	} else {
-		finalTime = sunSet
+	finalTime, ok := calculateSunTime(config.Latitude, config.Longitude, time.Now(), timingMode, twilight, offset)
+	if !ok {
+		log.Warn(fmt.Sprintf("Could not calculate %s time with twilight '%s': the sun does not reach the required elevation today, keeping the previous time", timingMode, twilight))
+		return cronExpression, nil
 	}
 	// Extract the days from the cron-expression
-	days, err := GetDaysFromCronExpression(job.CronExpression)
+	days, err := GetDaysFromCronExpression(cronExpression)
 	if err != nil {
-		log.Error(fmt.Sprintf("Failed to extract days from cron-expression '%s': Error: %s", job.CronExpression, err))
-		return err
+		log.Error(fmt.Sprintf("Failed to extract days from cron-expression '%s': Error: %s", cronExpression, err))
+		return "", err
 	}
-	cronExpression, err := GenerateCronExpression(uint8(finalTime.Hour), uint8(finalTime.Minute), days)
-	if err != nil {
-		return err
+	return GenerateCronExpression(uint8(finalTime.Hour), uint8(finalTime.Minute), days)
+}
+
+// Given a jobId, the next execution time is recalculated based on its timing mode, twilight and offset
+func updateJobTime(id uint) error {
+	// Retrieve the current job in order to get its current cron-expression (for the days)
+	job, found, err := database.GetAutomationById(id)
+	if err != nil || !found {
+		return errors.New("could not update launch time: invalid id supplied")
 	}
+	// The new execution time is calculated while the automation is modified
 	if err := ModifyAutomationById(id, database.AutomationWithoutIdAndUsername{
-		Name:           job.Name,
-		Description:    job.Description,
-		CronExpression: cronExpression,
-		HomescriptId:   job.HomescriptId,
-		Enabled:        job.Enabled,
-		TimingMode:     job.TimingMode,
+		Name:               job.Name,
+		Description:        job.Description,
+		CronExpression:     job.CronExpression,
+		HomescriptId:       job.HomescriptId,
+		HomescriptRevision: job.HomescriptRevision,
+		Enabled:            job.Enabled,
+		TimingMode:         job.TimingMode,
+		Trigger:            job.Trigger,
+		Condition:          job.Condition,
+		Hysteresis:         job.Hysteresis,
+		TriggerSubject:     job.TriggerSubject,
+		TriggerState:       job.TriggerState,
+		Twilight:           job.Twilight,
+		SunOffset:          job.SunOffset,
 	}); err != nil {
 		log.Error(fmt.Sprintf("Failed to update next execution time of automation '%d': could not modify automation: %s", id, err.Error()))
 		return err
@@ -0,0 +1,70 @@
+package automation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nathan-osman/go-sunrise"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestCalculateSunEvent(t *testing.T) {
+	day := time.Date(2022, time.June, 21, 12, 0, 0, 0, time.UTC)
+	// Without twilight, the result must match the library's sunrise and sunset
+	wantRise, wantSet := sunrise.SunriseSunset(52.5, 13.4, day.Year(), day.Month(), day.Day())
+	rise, ok := calculateSunEvent(52.5, 13.4, day, database.TwilightNone, true)
+	if !ok || !isWithinMinute(rise, wantRise) {
+		t.Errorf("Unexpected sunrise: want: %s got: %s", wantRise, rise)
+		return
+	}
+	set, ok := calculateSunEvent(52.5, 13.4, day, database.TwilightNone, false)
+	if !ok || !isWithinMinute(set, wantSet) {
+		t.Errorf("Unexpected sunset: want: %s got: %s", wantSet, set)
+		return
+	}
+	// Dawn begins before sunrise, dusk ends after sunset
+	previousRise, previousSet := rise, set
+	for _, twilight := range []database.Twilight{database.TwilightCivil, database.TwilightNautical} {
+		dawn, ok := calculateSunEvent(52.5, 13.4, day, twilight, true)
+		if !ok || !dawn.Before(previousRise) {
+			t.Errorf("Dawn of twilight '%s' does not begin before %s: got: %s", twilight, previousRise, dawn)
+			return
+		}
+		dusk, ok := calculateSunEvent(52.5, 13.4, day, twilight, false)
+		if !ok || !dusk.After(previousSet) {
+			t.Errorf("Dusk of twilight '%s' does not end after %s: got: %s", twilight, previousSet, dusk)
+			return
+		}
+		previousRise, previousSet = dawn, dusk
+	}
+	// During summer, the sun does not reach -18° at this latitude
+	if _, ok := calculateSunEvent(52.5, 13.4, day, database.TwilightAstronomical, true); ok {
+		t.Error("Astronomical twilight should not be reached on the summer solstice")
+		return
+	}
+}
+
+func TestCalculateSunTimeOffset(t *testing.T) {
+	day := time.Date(2022, time.March, 20, 12, 0, 0, 0, time.UTC)
+	sunSet, ok := calculateSunTime(52.5, 13.4, day, database.TimingSunset, database.TwilightNone, 0)
+	if !ok {
+		t.Error("Could not calculate sunset")
+		return
+	}
+	beforeSunSet, ok := calculateSunTime(52.5, 13.4, day, database.TimingSunset, database.TwilightNone, -30)
+	if !ok {
+		t.Error("Could not calculate sunset with offset")
+		return
+	}
+	difference := int(sunSet.Hour*60+sunSet.Minute) - int(beforeSunSet.Hour*60+beforeSunSet.Minute)
+	if difference != 30 {
+		t.Errorf("Unexpected offset: want: 30 got: %d", difference)
+		return
+	}
+}
+
+func isWithinMinute(a time.Time, b time.Time) bool {
+	difference := a.Sub(b)
+	return difference < time.Minute && difference > -time.Minute
+}
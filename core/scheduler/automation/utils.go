@@ -3,6 +3,7 @@ package automation
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/MikMuellerDev/smarthome/core/database"
 	"github.com/MikMuellerDev/smarthome/core/user"
@@ -18,70 +19,115 @@ type Automation struct {
 	Owner           string
 	Enabled         bool
 	TimingMode      database.TimingMode
+	// If set, this revision of the Homescript is run instead of its current code
+	HomescriptRevision *uint
+	Trigger            database.TriggerType
+	Condition          string
+	Hysteresis         float64
+	TriggerSubject     string
+	TriggerState       string
+	Twilight           database.Twilight
+	SunOffset          int
+	// Is nil if the automation is not registered in the scheduler, for example because it is disabled or started by an event
+	NextRun *time.Time
+	// Is nil if the automation has never run
+	LastRun *database.AutomationRun
 }
 
-// Creates a new automation which an according database entry
-// Sets up the scheduler based on the provided hour, minute, and days of the week on which the automation should run
-func CreateNewAutomation(
-	name string,
-	description string,
-	hour uint8,
-	minute uint8,
-	days []uint8,
-	homescriptId string,
-	owner string,
-	enabled bool,
-	timingMode database.TimingMode,
-) (uint, error) {
-	// Generate a cron expression based on the input data
-	// The `days` slice should not contain more than 7 elements
-	cronExpression, err := GenerateCronExpression(
-		hour,
-		minute,
-		days,
-	)
+// Returns the next time at which the scheduler runs the given automation
+// Returns nil if the automation is currently not scheduled
+func getNextRun(id uint) *time.Time {
+	if scheduler == nil {
+		return nil
+	}
+	jobs, err := scheduler.FindJobsByTag(fmt.Sprintf("%d", id))
 	if err != nil {
-		log.Error("Could not create automation: failed to generate cron expression: unexpected input: ", err.Error())
-		return 0, err
+		return nil
+	}
+	nextRun := jobs[0].NextRun()
+	if nextRun.IsZero() {
+		return nil
+	}
+	return &nextRun
+}
+
+// Returns the newest entry of the automation's run history or nil if it has never run
+func getLastRun(id uint) (*database.AutomationRun, error) {
+	lastRun, found, err := database.GetLastAutomationRun(id)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &lastRun, nil
+}
+
+// Returns a description of when an automation which is not time-based runs
+func describeTrigger(trigger database.TriggerType, condition string, subject string, state string) string {
+	switch trigger {
+	case database.TriggerWeather:
+		return fmt.Sprintf("once its condition '%s' is met", condition)
+	case database.TriggerSwitch:
+		if state == "" {
+			return fmt.Sprintf("when switch '%s' changes its power state", subject)
+		}
+		return fmt.Sprintf("when switch '%s' is turned %s", subject, state)
+	case database.TriggerNode:
+		if state == "" {
+			return fmt.Sprintf("when node '%s' goes online or offline", subject)
+		}
+		return fmt.Sprintf("when node '%s' goes %s", subject, state)
+	case database.TriggerLogin:
+		if subject == "" {
+			return "when you log in"
+		}
+		return fmt.Sprintf("when user '%s' logs in", subject)
+	case database.TriggerReminder:
+		if subject == "" {
+			return "when one of your reminders becomes overdue"
+		}
+		return fmt.Sprintf("when reminder '%s' becomes overdue", subject)
+	default:
+		return fmt.Sprintf("when its trigger '%s' fires", trigger)
+	}
+}
+
+// Creates a new automation which an according database entry
+// Sets up the scheduler based on the cron expression of the automation, the id of the input is ignored
+func CreateNewAutomation(newAutomation database.Automation) (uint, error) {
+	if !IsValidCronExpression(newAutomation.CronExpression) {
+		log.Error("Could not create automation: invalid cron expression provided")
+		return 0, errors.New("could not create automation: invalid cron expression provided")
 	}
 	// Insert the automation into the database
-	newAutomationId, err := database.CreateNewAutomation(
-		database.Automation{
-			Name:           name,
-			Description:    description,
-			CronExpression: cronExpression,
-			HomescriptId:   homescriptId,
-			Owner:          owner,
-			Enabled:        enabled,
-			TimingMode:     timingMode,
-		},
-	)
+	newAutomationId, err := database.CreateNewAutomation(newAutomation)
 	if err != nil {
 		log.Error("Could not create automation: database failure: ", err.Error())
 		return 0, err
 	}
 	// TODO: why is it necessary to generate the HumanReadableCronExpression?
-	cronDescription, err := generateHumanReadableCronExpression(cronExpression)
+	cronDescription, err := generateHumanReadableCronExpression(newAutomation.CronExpression)
 	if err != nil {
 		log.Error("Could not create automation: failed to generate human readable string: ", err.Error())
 		return 0, err
 	}
-	if enabled {
+	if !isTimeBased(newAutomation.Trigger) {
+		cronDescription = describeTrigger(newAutomation.Trigger, newAutomation.Condition, newAutomation.TriggerSubject, newAutomation.TriggerState)
+	}
+	if newAutomation.Enabled {
 		if err := user.Notify(
-			owner,
+			newAutomation.Owner,
 			"Automation Added",
-			fmt.Sprintf("Automation '%s' has been added to the system. It will be executed %s", name, cronDescription),
+			fmt.Sprintf("Automation '%s' has been added to the system. It will be executed %s", newAutomation.Name, cronDescription),
 			1,
 		); err != nil {
 			log.Error("Failed to notify user: ", err.Error())
 			return 0, err
 		}
-		log.Debug(fmt.Sprintf("Created new automation '%s' for user '%s'. It will be executed %s", name, owner, cronDescription))
+		log.Debug(fmt.Sprintf("Created new automation '%s' for user '%s'. It will be executed %s", newAutomation.Name, newAutomation.Owner, cronDescription))
 	} else {
 		if err := user.Notify(
-			owner,
+			newAutomation.Owner,
 			"Inactive Automation Added",
-			fmt.Sprintf("Automation '%s' has been added to the system, however it is currently disabled and thus will not be executed %s", name, description),
+			fmt.Sprintf("Automation '%s' has been added to the system, however it is currently disabled and thus will not be executed %s", newAutomation.Name, newAutomation.Description),
 			2,
 		); err != nil {
 			log.Error("Failed to notify user: ", err.Error())
@@ -99,19 +145,22 @@ func CreateNewAutomation(
 	if !serverConfig.AutomationEnabled { // If the automation scheduler is disabled, do not add the scheduler
 		return newAutomationId, nil
 	}
-	if timingMode != database.TimingNormal {
+	if !isTimeBased(newAutomation.Trigger) { // The automation is started by its trigger instead of the scheduler
+		return newAutomationId, nil
+	}
+	if newAutomation.TimingMode != database.TimingNormal {
 		// Add a dummy scheduler which does nothing in order to prevent the modify function from failing
-		automationJob := scheduler.Cron(cronExpression)
+		automationJob := scheduler.Cron(newAutomation.CronExpression)
 		automationJob.Tag(fmt.Sprintf("%d", newAutomationId))
 		if _, err := automationJob.Do(func() {}); err != nil {
 			log.Error("Failed to register dummy cron job: ", err.Error())
 			return 0, err
 		}
 		// If the timing mode is set to either `sunrise` or `sunset`, do not activate the automation, update it instead
-		return newAutomationId, updateJobTime(newAutomationId, timingMode == database.TimingSunrise)
+		return newAutomationId, updateJobTime(newAutomationId)
 	}
 	// Otherwise, register a cron job for the automation
-	automationJob := scheduler.Cron(cronExpression)
+	automationJob := scheduler.Cron(newAutomation.CronExpression)
 	automationJob.Tag(fmt.Sprintf("%d", newAutomationId))
 	if _, err = automationJob.Do(automationRunnerFunc, newAutomationId); err != nil {
 		log.Error("Failed to register cron job: ", err.Error())
@@ -140,7 +189,9 @@ func RemoveAutomation(automationId uint) error {
 		log.Error("Failed to remove automation: could not retrieve server configuration due to database failure")
 		return errors.New("failed to remove automation: could not retrieve server configuration due to database failure")
 	}
-	if !previousAutomation.Enabled || !serverConfig.AutomationEnabled { // A disabled automation cannot be removed from the scheduler, so return here
+	resetWeatherTrigger(automationId)
+	resetEventTrigger(automationId)
+	if !previousAutomation.Enabled || !serverConfig.AutomationEnabled || !isTimeBased(previousAutomation.Trigger) { // A disabled automation cannot be removed from the scheduler, so return here
 		log.Trace(fmt.Sprintf("Removed an already disabled automation id: '%d'", automationId))
 		return nil
 	}
@@ -176,17 +227,32 @@ func GetUserAutomations(username string) ([]Automation, error) {
 			log.Error("Failed to list automations of user: could not generate cron description: ", err.Error())
 			return nil, err
 		}
+		lastRun, err := getLastRun(automation.Id)
+		if err != nil {
+			log.Error("Failed to list automations of user: could not get last run: ", err.Error())
+			return nil, err
+		}
 		automations = append(automations,
 			Automation{
-				Id:              automation.Id,
-				Name:            automation.Name,
-				Description:     automation.Description,
-				CronExpression:  automation.CronExpression,
-				CronDescription: cronDescription,
-				HomescriptId:    automation.HomescriptId,
-				Owner:           automation.Owner,
-				Enabled:         automation.Enabled,
-				TimingMode:      automation.TimingMode,
+				Id:                 automation.Id,
+				Name:               automation.Name,
+				Description:        automation.Description,
+				CronExpression:     automation.CronExpression,
+				CronDescription:    cronDescription,
+				HomescriptId:       automation.HomescriptId,
+				HomescriptRevision: automation.HomescriptRevision,
+				Owner:              automation.Owner,
+				Enabled:            automation.Enabled,
+				TimingMode:         automation.TimingMode,
+				Trigger:            automation.Trigger,
+				Condition:          automation.Condition,
+				Hysteresis:         automation.Hysteresis,
+				TriggerSubject:     automation.TriggerSubject,
+				TriggerState:       automation.TriggerState,
+				Twilight:           automation.Twilight,
+				SunOffset:          automation.SunOffset,
+				NextRun:            getNextRun(automation.Id),
+				LastRun:            lastRun,
 			},
 		)
 	}
@@ -209,16 +275,31 @@ func GetUserAutomationById(username string, automationId uint) (Automation, bool
 			log.Error("Failed to get user automation by id: could not generate cron description: ", err.Error())
 			return Automation{}, false, err
 		}
+		lastRun, err := getLastRun(automation.Id)
+		if err != nil {
+			log.Error("Failed to get user automation by id: could not get last run: ", err.Error())
+			return Automation{}, false, err
+		}
 		return Automation{
-			Id:              automation.Id,
-			Name:            automation.Name,
-			Description:     automation.Description,
-			CronExpression:  automation.CronExpression,
-			CronDescription: cronDescription,
-			HomescriptId:    automation.HomescriptId,
-			Owner:           automation.Owner,
-			Enabled:         automation.Enabled,
-			TimingMode:      automation.TimingMode,
+			Id:                 automation.Id,
+			Name:               automation.Name,
+			Description:        automation.Description,
+			CronExpression:     automation.CronExpression,
+			CronDescription:    cronDescription,
+			HomescriptId:       automation.HomescriptId,
+			HomescriptRevision: automation.HomescriptRevision,
+			Owner:              automation.Owner,
+			Enabled:            automation.Enabled,
+			TimingMode:         automation.TimingMode,
+			Trigger:            automation.Trigger,
+			Condition:          automation.Condition,
+			Hysteresis:         automation.Hysteresis,
+			TriggerSubject:     automation.TriggerSubject,
+			TriggerState:       automation.TriggerState,
+			Twilight:           automation.Twilight,
+			SunOffset:          automation.SunOffset,
+			NextRun:            getNextRun(automation.Id),
+			LastRun:            lastRun,
 		}, true, nil
 	}
 	return Automation{}, false, nil
@@ -226,11 +307,20 @@ func GetUserAutomationById(username string, automationId uint) (Automation, bool
 
 // Changes the metadata of a given automation, then restarts it so it uses the updated values such as execution time
 // Is also used after an automation with non-normal timing has been added
+// If the automation uses the timing mode `sunrise` or `sunset`, its execution time is recalculated for today
 func ModifyAutomationById(automationId uint, newAutomation database.AutomationWithoutIdAndUsername) error {
 	if !IsValidCronExpression(newAutomation.CronExpression) {
 		log.Error("Failed to modify automation: invalid cron expression provided")
 		return errors.New("failed to modify automation: invalid cron expression provided")
 	}
+	if newAutomation.TimingMode != database.TimingNormal && isTimeBased(newAutomation.Trigger) {
+		cronExpression, err := generateSunCronExpression(newAutomation.CronExpression, newAutomation.TimingMode, newAutomation.Twilight, newAutomation.SunOffset)
+		if err != nil {
+			log.Error("Failed to modify automation: could not calculate execution time: ", err.Error())
+			return err
+		}
+		newAutomation.CronExpression = cronExpression
+	}
 	automationBefore, exists, err := database.GetAutomationById(automationId)
 	if err != nil {
 		log.Error("Failed to modify automation by id: could not get previous state due to database failure: ", err.Error())
@@ -244,7 +334,9 @@ func ModifyAutomationById(automationId uint, newAutomation database.AutomationWi
 		log.Error("Failed to modify automation by id: database failure during modification: ", err.Error())
 		return err
 	}
-	if automationBefore.Enabled { // If the automation was enabled before it was modified, remove it from the cron jobs
+	resetWeatherTrigger(automationId)
+	resetEventTrigger(automationId)
+	if automationBefore.Enabled && isTimeBased(automationBefore.Trigger) { // If the automation was enabled before it was modified, remove it from the cron jobs
 		// After the metadata has been changed, restart the scheduler
 		if err := scheduler.RemoveByTag(fmt.Sprintf("%d", automationId)); err != nil {
 			log.Error("Failed to remove automation item: could not stop cron job: ", err.Error())
@@ -253,12 +345,14 @@ func ModifyAutomationById(automationId uint, newAutomation database.AutomationWi
 	}
 	if newAutomation.Enabled {
 		// Restart the scheduler after the old one was disabled
-		// Only add the scheduler if it is enabled in the new version
-		automationJob := scheduler.Cron(newAutomation.CronExpression)
-		automationJob.Tag(fmt.Sprintf("%d", automationId))
-		if _, err := automationJob.Do(automationRunnerFunc, automationId); err != nil {
-			log.Error("Failed to modify automation, registering cron job failed: ", err.Error())
-			return err
+		// Only add the scheduler if it is enabled in the new version and started by its cron expression
+		if isTimeBased(newAutomation.Trigger) {
+			automationJob := scheduler.Cron(newAutomation.CronExpression)
+			automationJob.Tag(fmt.Sprintf("%d", automationId))
+			if _, err := automationJob.Do(automationRunnerFunc, automationId); err != nil {
+				log.Error("Failed to modify automation, registering cron job failed: ", err.Error())
+				return err
+			}
 		}
 		log.Debug(fmt.Sprintf("Automation %d has been modified and restarted", automationId))
 		if !automationBefore.Enabled {
@@ -8,17 +8,15 @@ import (
 
 func TestCreateAutomation(t *testing.T) {
 	TestInit(t)
-	id, err := CreateNewAutomation(
-		"name",
-		"description",
-		0,
-		0,
-		[]uint8{0},
-		"test",
-		"admin",
-		false,
-		database.TimingNormal,
-	)
+	id, err := CreateNewAutomation(database.Automation{
+		Name:           "name",
+		Description:    "description",
+		CronExpression: "0 0 * * 0",
+		HomescriptId:   "test",
+		Owner:          "admin",
+		TimingMode:     database.TimingNormal,
+		Trigger:        database.TriggerCron,
+	})
 	if err != nil {
 		t.Error(err.Error())
 		return
@@ -42,17 +40,15 @@ func TestCreateAutomation(t *testing.T) {
 
 func TestModifyAutomation(t *testing.T) {
 	TestInit(t)
-	id, err := CreateNewAutomation(
-		"name",
-		"description",
-		0,
-		0,
-		[]uint8{0},
-		"test",
-		"admin",
-		false,
-		database.TimingSunrise,
-	)
+	id, err := CreateNewAutomation(database.Automation{
+		Name:           "name",
+		Description:    "description",
+		CronExpression: "0 0 * * 0",
+		HomescriptId:   "test",
+		Owner:          "admin",
+		TimingMode:     database.TimingSunrise,
+		Trigger:        database.TriggerCron,
+	})
 	if err != nil {
 		t.Error(err.Error())
 		return
@@ -91,17 +87,15 @@ func TestModifyAutomation(t *testing.T) {
 // For actual execution tests, have a look at `automation_test.go`
 func TestRemoveAutomation(t *testing.T) {
 	TestInit(t)
-	id, err := CreateNewAutomation(
-		"name",
-		"description",
-		0,
-		0,
-		[]uint8{0},
-		"test",
-		"admin",
-		false,
-		database.TimingNormal,
-	)
+	id, err := CreateNewAutomation(database.Automation{
+		Name:           "name",
+		Description:    "description",
+		CronExpression: "0 0 * * 0",
+		HomescriptId:   "test",
+		Owner:          "admin",
+		TimingMode:     database.TimingNormal,
+		Trigger:        database.TriggerCron,
+	})
 	if err != nil {
 		t.Error(err.Error())
 		return
@@ -133,17 +127,16 @@ func TestRemoveAutomation(t *testing.T) {
 func TestGetUserAutomations(t *testing.T) {
 	TestInit(t)
 	for i := 0; i < 100; i++ {
-		if _, err := CreateNewAutomation(
-			"name",
-			"description",
-			1,
-			1,
-			[]uint8{0},
-			"test",
-			"admin",
-			true,
-			database.TimingNormal,
-		); err != nil {
+		if _, err := CreateNewAutomation(database.Automation{
+			Name:           "name",
+			Description:    "description",
+			CronExpression: "1 1 * * 0",
+			HomescriptId:   "test",
+			Owner:          "admin",
+			Enabled:        true,
+			TimingMode:     database.TimingNormal,
+			Trigger:        database.TriggerCron,
+		}); err != nil {
 			t.Error(err.Error())
 			return
 		}
@@ -0,0 +1,104 @@
+package automation
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/services/weather"
+)
+
+// Time between two evaluations of weather triggers
+const weatherTriggerInterval = 5 * time.Minute
+
+// Contains whether the condition of each weather-triggered automation was met during the last evaluation
+// An automation only runs once its condition changes from unmet to met
+// The state is not persisted, automations without a state are seeded during their next evaluation
+var weatherTriggers = struct {
+	m      sync.Mutex
+	active map[uint]bool
+}{
+	active: make(map[uint]bool),
+}
+
+// Returns whether the automation is started by the scheduler using its cron expression
+// Automations which were created before triggers existed have no trigger type
+func isTimeBased(trigger database.TriggerType) bool {
+	return trigger == database.TriggerCron || trigger == ""
+}
+
+// Forgets the previous state of a weather trigger, is used if an automation is modified or removed
+func resetWeatherTrigger(id uint) {
+	weatherTriggers.m.Lock()
+	defer weatherTriggers.m.Unlock()
+	delete(weatherTriggers.active, id)
+}
+
+// Checks the conditions of all enabled weather-triggered automations and runs those whose condition has become true
+func evaluateWeatherTriggers() {
+	config, found, err := database.GetServerConfiguration()
+	if err != nil || !found {
+		log.Error("Failed to evaluate weather triggers: could not retrieve server configuration")
+		return
+	}
+	if !config.AutomationEnabled {
+		return
+	}
+	automations, err := database.GetAutomations()
+	if err != nil {
+		log.Error("Failed to evaluate weather triggers: database failure: ", err.Error())
+		return
+	}
+	triggered := make([]database.Automation, 0)
+	for _, automation := range automations {
+		if automation.Enabled && automation.Trigger == database.TriggerWeather {
+			triggered = append(triggered, automation)
+		}
+	}
+	if len(triggered) == 0 {
+		return
+	}
+	data, err := weather.GetWeather()
+	if err != nil {
+		log.Warn("Failed to evaluate weather triggers: could not get weather: ", err.Error())
+		return
+	}
+	weatherTriggers.m.Lock()
+	defer weatherTriggers.m.Unlock()
+	for _, automation := range triggered {
+		condition, err := ParseWeatherCondition(automation.Condition)
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to evaluate weather trigger of automation '%d': invalid condition: %s", automation.Id, err.Error()))
+			continue
+		}
+		if updateWeatherTrigger(automation.Id, condition, data, automation.Hysteresis) {
+			log.Debug(fmt.Sprintf("Weather condition '%s' of automation '%d' is met", automation.Condition, automation.Id))
+			go automationRunnerFunc(automation.Id)
+		}
+	}
+}
+
+// Stores the new state of a weather trigger and returns whether its automation should run
+// The first evaluation after a restart or a modification only records the state
+// Otherwise, every condition which is already met would run its automation again after each restart
+// Requires the caller to hold the lock of the weather triggers
+func updateWeatherTrigger(id uint, condition WeatherCondition, data weather.Weather, hysteresis float64) bool {
+	wasActive, known := weatherTriggers.active[id]
+	isActive := condition.Evaluate(data, wasActive, hysteresis)
+	weatherTriggers.active[id] = isActive
+	return known && isActive && !wasActive
+}
+
+// Returns whether the current weather meets the given condition
+func checkWeatherCondition(expression string) (bool, error) {
+	condition, err := ParseWeatherCondition(expression)
+	if err != nil {
+		return false, fmt.Errorf("invalid condition: %s", err.Error())
+	}
+	data, err := weather.GetWeather()
+	if err != nil {
+		return false, fmt.Errorf("could not get weather: %s", err.Error())
+	}
+	return condition.Evaluate(data, false, 0), nil
+}
@@ -0,0 +1,36 @@
+package automation
+
+import (
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/services/weather"
+)
+
+// A condition which is already met during the first evaluation must not run the automation
+func TestUpdateWeatherTrigger(t *testing.T) {
+	condition, err := ParseWeatherCondition("temperature < 2")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	id := uint(4242)
+	defer resetWeatherTrigger(id)
+	table := []struct {
+		Temperature float64
+		Run         bool
+	}{
+		{Temperature: 0, Run: false}, // Seeds the state
+		{Temperature: 1, Run: false},
+		{Temperature: 5, Run: false},
+		{Temperature: 1, Run: true},
+		{Temperature: 0, Run: false},
+	}
+	weatherTriggers.m.Lock()
+	defer weatherTriggers.m.Unlock()
+	for index, test := range table {
+		if run := updateWeatherTrigger(id, condition, weather.Weather{Temperature: test.Temperature}, 0); run != test.Run {
+			t.Errorf("Unexpected result of evaluation %d at %.1f°C: want: %t got: %t", index, test.Temperature, test.Run, run)
+			return
+		}
+	}
+}
@@ -9,6 +9,18 @@ import (
 	"github.com/MikMuellerDev/smarthome/core/user"
 )
 
+// Publishes the progress of a schedule on the event bus
+func publishRunStatus(id uint, job database.Schedule, status event.RunStatus, errorMessage string) {
+	event.Publish(event.JobStatus{
+		Kind:   event.TopicScheduleRun,
+		Id:     id,
+		Name:   job.Name,
+		Owner:  job.Owner,
+		Status: status,
+		Error:  errorMessage,
+	})
+}
+
 // Executes a given scheduler
 // If the user's schedulers are currently disabled
 // the job runner will still be executed and remove the current scheduler but without running the homescript
@@ -17,27 +29,33 @@ func scheduleRunnerFunc(id uint) {
 	job, jobFound, err := database.GetScheduleById(id)
 	if err != nil {
 		log.Error(fmt.Sprintf("Failed to run schedule '%s': database failure whilst retrieving job information: %s", job.Name, err.Error()))
+		publishRunStatus(id, job, event.RunFailed, "database failure")
 		return
 	}
 	if !jobFound {
 		log.Error(fmt.Sprintf("Failed to run schedule '%s': no metadata saved in the database: %s", job.Name, err.Error()))
+		publishRunStatus(id, job, event.RunFailed, "schedule not found")
 		return
 	}
 	owner, found, err := database.GetUserByUsername(job.Owner)
 	if err != nil {
 		log.Error(fmt.Sprintf("Failed to run schedule '%s': database error whilst retrieving user information: %s", job.Name, err.Error()))
+		publishRunStatus(id, job, event.RunFailed, "database failure")
 		return
 	}
 	if !found {
 		log.Error(fmt.Sprintf("Owner %s of schedule %d does not exist", job.Owner, job.Id))
+		publishRunStatus(id, job, event.RunFailed, "owner does not exist")
 		return
 	}
 	if err := database.DeleteScheduleById(id); err != nil {
 		log.Error("Executing schedule failed: could not remove schedule from database: ", err.Error())
+		publishRunStatus(id, job, event.RunFailed, "database failure")
 		return
 	}
 	if !owner.SchedulerEnabled {
 		log.Info(fmt.Sprintf("Not running schedule '%s' because user's schedules are currently disabled", job.Name))
+		publishRunStatus(id, job, event.RunSkipped, "schedules of the owner are disabled")
 		if err := user.Notify(
 			owner.Username,
 			"Schedule Skipped",
@@ -54,13 +72,16 @@ func scheduleRunnerFunc(id uint) {
 		return
 	}
 	log.Debug(fmt.Sprintf("Schedule '%d' is running", id))
+	publishRunStatus(id, job, event.RunStarted, "")
 	_, exitCode, hmsErrors := homescript.Run(
 		owner.Username,
 		fmt.Sprintf("schedule_%d_job.hms", id),
 		job.HomescriptCode,
+		homescript.TriggerSchedule,
 	)
 	if len(hmsErrors) > 0 {
 		log.Error("Executing scheduler's homescript failed: ", hmsErrors[0].ErrorType)
+		publishRunStatus(id, job, event.RunFailed, hmsErrors[0].Message)
 		if err := user.Notify(
 			owner.Username,
 			"Schedule Failed",
@@ -76,6 +97,7 @@ func scheduleRunnerFunc(id uint) {
 		)
 		return
 	}
+	publishRunStatus(id, job, event.RunSucceeded, "")
 	if err := user.Notify(
 		job.Owner,
 		"Schedule Executed Successfully",
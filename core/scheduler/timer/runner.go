@@ -0,0 +1,60 @@
+package timer
+
+import (
+	"fmt"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+	"github.com/MikMuellerDev/smarthome/core/hardware"
+	"github.com/MikMuellerDev/smarthome/core/user"
+)
+
+// Executes a given power timer
+// The timer is removed from the database before the power action is performed so that it is never executed twice
+// Errors are reported to the owner of the timer using a notification
+func timerRunnerFunc(id uint) {
+	timersLock.Lock()
+	delete(timers, id)
+	timersLock.Unlock()
+
+	timer, found, err := database.GetPowerTimerById(id)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to run power timer '%d': database failure whilst retrieving timer information: %s", id, err.Error()))
+		return
+	}
+	if !found {
+		// The timer has been cancelled in the meantime
+		log.Debug(fmt.Sprintf("Power timer '%d' was not executed because it no longer exists", id))
+		return
+	}
+	if err := database.DeletePowerTimerById(id); err != nil {
+		log.Error("Executing power timer failed: could not remove timer from database: ", err.Error())
+		return
+	}
+	log.Debug(fmt.Sprintf("Power timer '%d' is running", id))
+	if err := hardware.SetSwitchPowerAll(timer.SwitchId, timer.PowerOn, timer.Owner); err != nil {
+		log.Error(fmt.Sprintf("Executing power timer '%d' failed: %s", id, err.Error()))
+		if err := user.Notify(
+			timer.Owner,
+			"Timer Failed",
+			fmt.Sprintf("The timer for switch '%s' failed: %s", timer.SwitchId, err.Error()),
+			user.NotificationLevelError,
+		); err != nil {
+			log.Error("Failed to notify user: ", err.Error())
+			return
+		}
+		event.Error(
+			"Power Timer Failure",
+			fmt.Sprintf("Power timer '%d' of user '%s' failed. Error: %s", id, timer.Owner, err.Error()),
+		)
+		return
+	}
+	action := "deactivated"
+	if timer.PowerOn {
+		action = "activated"
+	}
+	event.Info(
+		"Power Timer Executed",
+		fmt.Sprintf("Power timer of user '%s' %s switch '%s'", timer.Owner, action, timer.SwitchId),
+	)
+}
@@ -0,0 +1,63 @@
+package timer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+// Contains the currently running timers, the key is the id of the timer in the database
+var timers = make(map[uint]*time.Timer)
+
+// Guards the timers map because timers are added and removed from different goroutines
+var timersLock sync.Mutex
+
+var log *logrus.Logger
+
+func InitLogger(logger *logrus.Logger) {
+	log = logger
+}
+
+// Retrieves the saved power timers from the database and starts them
+// Timers which have expired while the server was offline are executed immediately
+// Requires the hardware handler to be initialized first
+func Init() error {
+	savedTimers, err := database.GetPowerTimers()
+	if err != nil {
+		log.Error("Failed to start power timers: database failure: ", err.Error())
+		return err
+	}
+	for _, timer := range savedTimers {
+		startTimer(timer.Id, timer.ExecuteAt)
+		log.Trace(fmt.Sprintf("Successfully activated power timer '%d' of user '%s'", timer.Id, timer.Owner))
+	}
+	log.Debug("Successfully activated power timers")
+	return nil
+}
+
+// Registers a timer which executes the power timer with the given id at the specified time
+// If the time lies in the past, the timer is executed immediately
+func startTimer(id uint, executeAt time.Time) {
+	timersLock.Lock()
+	defer timersLock.Unlock()
+	timers[id] = time.AfterFunc(time.Until(executeAt), func() {
+		timerRunnerFunc(id)
+	})
+}
+
+// Stops the timer with the given id and removes it from the running timers
+// Returns false if the timer has already fired or did not exist
+func stopTimer(id uint) bool {
+	timersLock.Lock()
+	defer timersLock.Unlock()
+	timer, exists := timers[id]
+	if !exists {
+		return false
+	}
+	delete(timers, id)
+	return timer.Stop()
+}
@@ -0,0 +1,162 @@
+package timer
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+	"github.com/MikMuellerDev/smarthome/core/hardware"
+	"github.com/MikMuellerDev/smarthome/core/user"
+)
+
+// Sets up the tests dependencies
+func TestMain(m *testing.M) {
+	log := logrus.New()
+	log.Level = logrus.FatalLevel
+	InitLogger(log)
+	event.InitLogger(log)
+	hardware.InitLogger(log)
+	user.InitLogger(log)
+	if err := initDB(true); err != nil {
+		panic(err.Error())
+	}
+	if err := createMockData(); err != nil {
+		panic(err.Error())
+	}
+	hardware.Init()
+	code := m.Run()
+	os.Exit(code)
+}
+
+// Creates a room and the switches which are used by the timers
+func createMockData() error {
+	if err := database.CreateRoom(database.RoomData{Id: "timer_room"}); err != nil {
+		return err
+	}
+	for _, switchId := range []string{"timer_expired", "timer_pending", "timer_cancel"} {
+		if err := database.CreateSwitch(database.Switch{Id: switchId, RoomId: "timer_room"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func initDB(args ...bool) error {
+	database.InitLogger(logrus.New())
+	if err := database.Init(database.DatabaseConfig{
+		Username: "smarthome",
+		Password: "testing",
+		Hostname: "localhost",
+		Database: "smarthome",
+		Port:     3330,
+	}, "admin",
+	); err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		if err := database.DeleteTables(); err != nil {
+			return err
+		}
+		time.Sleep(time.Second)
+		return initDB()
+	}
+	return nil
+}
+
+// Checks the power state of a switch and whether its timer still exists in the database
+func checkTimer(t *testing.T, id uint, switchId string, wantPower bool, wantExists bool) bool {
+	power, err := hardware.GetPowerState(switchId)
+	if err != nil {
+		t.Error(err.Error())
+		return false
+	}
+	if power != wantPower {
+		t.Errorf("Unexpected power state of switch '%s': want: %t got: %t", switchId, wantPower, power)
+		return false
+	}
+	_, exists, err := database.GetPowerTimerById(id)
+	if err != nil {
+		t.Error(err.Error())
+		return false
+	}
+	if exists != wantExists {
+		t.Errorf("Unexpected existence of timer '%d': want: %t got: %t", id, wantExists, exists)
+		return false
+	}
+	return true
+}
+
+// Timers which have been saved before a restart are restored, expired timers are executed immediately
+func TestInit(t *testing.T) {
+	expiredId, err := database.CreatePowerTimer(database.PowerTimer{
+		Owner:     "admin",
+		SwitchId:  "timer_expired",
+		PowerOn:   true,
+		ExecuteAt: time.Now().Add(-time.Hour),
+	})
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	pendingId, err := database.CreatePowerTimer(database.PowerTimer{
+		Owner:     "admin",
+		SwitchId:  "timer_pending",
+		PowerOn:   true,
+		ExecuteAt: time.Now().Add(3 * time.Second),
+	})
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := Init(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	time.Sleep(time.Second)
+	if !checkTimer(t, expiredId, "timer_expired", true, false) {
+		return
+	}
+	if !checkTimer(t, pendingId, "timer_pending", false, true) {
+		return
+	}
+	time.Sleep(4 * time.Second)
+	checkTimer(t, pendingId, "timer_pending", true, false)
+}
+
+// A cancelled timer is stopped and removed from the database
+func TestCancelUserPowerTimer(t *testing.T) {
+	timer, err := CreatePowerTimer("admin", "timer_cancel", true, time.Now().Add(2*time.Second))
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	// Only the owner can cancel the timer
+	cancelled, err := CancelUserPowerTimer("other", timer.Id)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if cancelled {
+		t.Error("Timer was cancelled by a user who does not own it")
+		return
+	}
+	cancelled, err = CancelUserPowerTimer("admin", timer.Id)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !cancelled {
+		t.Error("Timer was not cancelled by its owner")
+		return
+	}
+	if stopTimer(timer.Id) {
+		t.Error("Cancelled timer was still running")
+		return
+	}
+	time.Sleep(3 * time.Second)
+	checkTimer(t, timer.Id, "timer_cancel", false, false)
+}
@@ -0,0 +1,49 @@
+package timer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+// Creates a new power timer which will set the power of the given switch at the specified time
+// The timer is saved in the database so that it survives restarts of the server
+// Does not validate the switch or the permissions of the user, this is done when the timer executes
+func CreatePowerTimer(owner string, switchId string, powerOn bool, executeAt time.Time) (database.PowerTimer, error) {
+	timer := database.PowerTimer{
+		Owner:     owner,
+		SwitchId:  switchId,
+		PowerOn:   powerOn,
+		ExecuteAt: executeAt,
+	}
+	id, err := database.CreatePowerTimer(timer)
+	if err != nil {
+		log.Error("Failed to create power timer: database failure: ", err.Error())
+		return database.PowerTimer{}, err
+	}
+	timer.Id = id
+	startTimer(id, executeAt)
+	log.Trace(fmt.Sprintf("Created power timer '%d' for switch '%s' of user '%s'", id, switchId, owner))
+	return timer, nil
+}
+
+// Cancels a pending power timer and removes it from the database
+// Returns false if the timer does not exist or does not belong to the given user
+func CancelUserPowerTimer(username string, id uint) (bool, error) {
+	timer, found, err := database.GetPowerTimerById(id)
+	if err != nil {
+		log.Error("Failed to cancel power timer: database failure: ", err.Error())
+		return false, err
+	}
+	if !found || timer.Owner != username {
+		return false, nil
+	}
+	stopTimer(id)
+	if err := database.DeletePowerTimerById(id); err != nil {
+		log.Error("Failed to cancel power timer: database failure: ", err.Error())
+		return false, err
+	}
+	log.Trace(fmt.Sprintf("Cancelled power timer '%d' of user '%s'", id, username))
+	return true, nil
+}
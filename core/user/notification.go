@@ -2,6 +2,7 @@ package user
 
 import (
 	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
 )
 
 type NotificationLevel uint8
@@ -17,5 +18,11 @@ func Notify(username string, title string, description string, level Notificatio
 		log.Error("Failed to notify user: database failure: ", err.Error())
 		return err
 	}
+	event.Publish(event.Notification{
+		Username:    username,
+		Title:       title,
+		Description: description,
+		Level:       uint8(level),
+	})
 	return nil
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/MikMuellerDev/smarthome/core/homescript"
 	"github.com/MikMuellerDev/smarthome/core/scheduler/automation"
 	"github.com/MikMuellerDev/smarthome/core/scheduler/scheduler"
+	"github.com/MikMuellerDev/smarthome/core/scheduler/timer"
 	"github.com/MikMuellerDev/smarthome/core/user"
 	"github.com/MikMuellerDev/smarthome/core/utils"
 	"github.com/MikMuellerDev/smarthome/server/api"
@@ -25,7 +26,10 @@ import (
 	"github.com/MikMuellerDev/smarthome/server/routes"
 	"github.com/MikMuellerDev/smarthome/server/templates"
 	"github.com/MikMuellerDev/smarthome/services/camera"
+	"github.com/MikMuellerDev/smarthome/services/energy"
+	"github.com/MikMuellerDev/smarthome/services/radigo"
 	"github.com/MikMuellerDev/smarthome/services/reminder"
+	"github.com/MikMuellerDev/smarthome/services/weather"
 )
 
 var port = 8082 // Port used during development, can be overridden by config file or environment variables
@@ -73,7 +77,11 @@ func main() {
 	homescript.InitLogger(log)
 	automation.InitLogger(log)
 	scheduler.InitLogger(log)
+	timer.InitLogger(log)
 	reminder.InitLogger(log)
+	energy.InitLogger(log)
+	radigo.InitLogger(log)
+	weather.InitLogger(log)
 
 	// Read config file
 	if err := config.ReadConfigFile(); err != nil {
@@ -92,6 +100,7 @@ func main() {
 		`SMARTHOME_DB_HOSTNAME`   : Sets the database hostname
 		`SMARTHOME_DB_PASSWORD`   : Sets the database user's password
 		`SMARTHOME_DB_USER`       : Sets the database user
+		`SMARTHOME_WEATHER_API_KEY`: Sets the API key of the weather provider
 	*/
 
 	newAdminPassword := "admin"
@@ -124,6 +133,11 @@ func main() {
 		}
 	}
 
+	if weatherApiKey, weatherApiKeyOk := os.LookupEnv("SMARTHOME_WEATHER_API_KEY"); weatherApiKeyOk {
+		log.Debug("Selected SMARTHOME_WEATHER_API_KEY over value from config file")
+		configStruct.Weather.ApiKey = weatherApiKey
+	}
+
 	if dbPort, dbPortOk := os.LookupEnv("SMARTHOME_DB_PORT"); dbPortOk {
 		portInt, err := strconv.Atoi(dbPort)
 		if err != nil {
@@ -188,6 +202,28 @@ func main() {
 	// Init the hardware handler
 	hardware.Init() // Needed for initializing atomics
 
+	// Periodically update the power states of network devices
+	if err := hardware.InitNetworkDeviceCheck(); err != nil {
+		log.Fatal("Failed to activate network device check: ", err.Error())
+	}
+
+	// Record the energy usage and evaluate energy budgets, requires the hardware handler
+	if err := energy.InitSchedule(); err != nil {
+		log.Fatal("Failed to activate energy budget scheduler: ", err.Error())
+	}
+
+	// Fetch weather data for Homescript and the API if a provider has been configured
+	if configStruct.Weather.ApiKey != "" {
+		weather.Init(weather.NewOpenWeatherMap(configStruct.Weather.Url, configStruct.Weather.ApiKey))
+	} else {
+		log.Info("No weather API key configured, the weather service is disabled")
+	}
+
+	// Restore the pending power timers, requires the hardware handler
+	if err := timer.Init(); err != nil {
+		log.Fatal("Failed to activate power timers: ", err.Error())
+	}
+
 	r := routes.NewRouter()
 	middleware.Init(configStruct.Server.Production)
 	templates.LoadTemplates("./web/dist/html/*.html")
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/MikMuellerDev/smarthome/core/database"
 	"github.com/MikMuellerDev/smarthome/core/scheduler/automation"
@@ -19,6 +20,12 @@ type NewAutomationRequest struct {
 	HomescriptId string              `json:"homescriptId"`
 	Enabled      bool                `json:"enabled"`
 	TimingMode   database.TimingMode `json:"timingMode"`
+	// Are only used by the timing modes `sunrise` and `sunset`, the offset is specified in minutes
+	Twilight  database.Twilight `json:"twilight"`
+	SunOffset int               `json:"sunOffset"`
+	// Optional revision of the Homescript which is run instead of its current code
+	HomescriptRevision *uint `json:"homescriptRevision"`
+	AutomationTrigger
 }
 
 type ModifyAutomationRequest struct {
@@ -31,16 +38,250 @@ type ModifyAutomationRequest struct {
 	HomescriptId string              `json:"homescriptId"`
 	Enabled      bool                `json:"enabled"`
 	TimingMode   database.TimingMode `json:"timingMode"`
+	// Are only used by the timing modes `sunrise` and `sunset`, the offset is specified in minutes
+	Twilight  database.Twilight `json:"twilight"`
+	SunOffset int               `json:"sunOffset"`
+	// Optional revision of the Homescript which is run instead of its current code
+	HomescriptRevision *uint `json:"homescriptRevision"`
+	AutomationTrigger
+}
+
+// Describes what causes an automation to run, is shared by the creation and the modification request
+type AutomationTrigger struct {
+	// Defaults to `cron`, weather triggers require a condition
+	Trigger    database.TriggerType `json:"trigger"`
+	Condition  string               `json:"condition"`
+	Hysteresis float64              `json:"hysteresis"`
+	// Are only used by event triggers, for example the switch id and `on` for a switch trigger
+	TriggerSubject string `json:"triggerSubject"`
+	TriggerState   string `json:"triggerState"`
 }
 
 type DeleteAutomationRequest struct {
 	Id uint `json:"id"`
 }
 
+type RunAutomationRequest struct {
+	Id uint `json:"id"`
+}
+
 type AutomationActivationRequest struct {
 	Enabled bool `json:"enabled"`
 }
 
+// Validates that a Homescript may be used by automations, sends an error response if it is not enabled for the scheduler
+func validateAutomationHomescript(w http.ResponseWriter, homescriptItem database.Homescript, message string) bool {
+	if !homescriptItem.SchedulerEnabled {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: message, Error: "homescript is not enabled for automations: enable `schedulerEnabled` first"})
+		return false
+	}
+	return true
+}
+
+// Validates that a pinned revision belongs to the automation's Homescript, sends an error response if it is invalid
+func validateHomescriptRevisionPin(w http.ResponseWriter, homescriptId string, revisionId *uint, message string) bool {
+	if revisionId == nil {
+		return true
+	}
+	_, found, err := database.GetHomescriptRevision(homescriptId, *revisionId)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: message, Error: "database failure"})
+		return false
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("homescript '%s' has no revision with id %d", homescriptId, *revisionId)})
+		return false
+	}
+	return true
+}
+
+// Validates the timing mode of an automation together with its twilight variant and offset, sends an error response if they are invalid
+func validateSunTiming(w http.ResponseWriter, timingMode database.TimingMode, twilight database.Twilight, offset int, message string) bool {
+	if timingMode != database.TimingNormal && timingMode != database.TimingSunrise && timingMode != database.TimingSunset {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: message, Error: "invalid timing mode"})
+		return false
+	}
+	if !automation.IsValidTwilight(twilight) {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: message, Error: "invalid twilight: valid values are '', 'civil', 'nautical' and 'astronomical'"})
+		return false
+	}
+	if offset > automation.MaxSunOffset || offset < -automation.MaxSunOffset {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("invalid sun offset: offset must be between -%d and %d minutes", automation.MaxSunOffset, automation.MaxSunOffset)})
+		return false
+	}
+	if timingMode == database.TimingNormal && (twilight != database.TwilightNone || offset != 0) {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: message, Error: "twilight and sun offset can only be used with the timing modes 'sunrise' and 'sunset'"})
+		return false
+	}
+	return true
+}
+
+// Validates the trigger of an automation, sends an error response if it is invalid
+// Returns the trigger type which should be stored, an empty type is replaced with the default
+func validateAutomationTrigger(w http.ResponseWriter, username string, request AutomationTrigger, timingMode database.TimingMode, message string) (database.TriggerType, bool) {
+	trigger := request.Trigger
+	if trigger == "" {
+		trigger = database.TriggerCron
+	}
+	switch trigger {
+	case database.TriggerCron, database.TriggerWeather, database.TriggerSwitch, database.TriggerNode, database.TriggerLogin, database.TriggerReminder:
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: message, Error: "invalid trigger: valid triggers are 'cron', 'weather', 'switch', 'node', 'login' and 'reminder'"})
+		return "", false
+	}
+	if trigger != database.TriggerCron && timingMode != database.TimingNormal {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("%s triggers can only be used with the timing mode 'normal'", trigger)})
+		return "", false
+	}
+	if trigger == database.TriggerWeather && request.Condition == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: message, Error: "weather triggers require a condition"})
+		return "", false
+	}
+	if request.Condition != "" {
+		if _, err := automation.ParseWeatherCondition(request.Condition); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("invalid condition: %s", err.Error())})
+			return "", false
+		}
+	}
+	if request.Hysteresis < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: message, Error: "hysteresis must not be negative"})
+		return "", false
+	}
+	if !validateEventTrigger(w, username, trigger, request.TriggerSubject, request.TriggerState, message) {
+		return "", false
+	}
+	return trigger, true
+}
+
+// Validates the subject and the state of an event trigger, sends an error response if they are invalid
+// Triggers which do not react to events must not specify a subject or a state
+func validateEventTrigger(w http.ResponseWriter, username string, trigger database.TriggerType, subject string, state string, message string) bool {
+	var validStates []string
+	switch trigger {
+	case database.TriggerSwitch:
+		validStates = []string{"on", "off"}
+		if subject == "" {
+			break
+		}
+		_, switchExists, err := database.GetSwitchById(subject)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: message, Error: "database failure"})
+			return false
+		}
+		hasPermission, err := database.UserHasSwitchPermission(username, subject)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: message, Error: "database failure"})
+			return false
+		}
+		if !switchExists || !hasPermission {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("switch '%s' does not exist or you lack permission to use it", subject)})
+			return false
+		}
+	case database.TriggerNode:
+		validStates = []string{"online", "offline"}
+		if subject == "" {
+			break
+		}
+		_, found, err := database.GetHardwareNodeByUrl(subject)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: message, Error: "database failure"})
+			return false
+		}
+		if !found {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("node '%s' does not exist", subject)})
+			return false
+		}
+	case database.TriggerLogin:
+		// Reacting to logins of other users requires the permission to manage users
+		if subject == "" || subject == username {
+			break
+		}
+		hasPermission, err := database.UserHasPermission(username, database.PermissionManageUsers)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: message, Error: "database failure"})
+			return false
+		}
+		if !hasPermission {
+			w.WriteHeader(http.StatusForbidden)
+			Res(w, Response{Success: false, Message: message, Error: "reacting to logins of other users requires the permission to manage users"})
+			return false
+		}
+		_, found, err := database.GetUserByUsername(subject)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: message, Error: "database failure"})
+			return false
+		}
+		if !found {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("user '%s' does not exist", subject)})
+			return false
+		}
+	case database.TriggerReminder:
+		if subject == "" {
+			break
+		}
+		id, err := strconv.ParseUint(subject, 10, 0)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			Res(w, Response{Success: false, Message: message, Error: "the subject of reminder triggers must be the id of a reminder"})
+			return false
+		}
+		_, found, err := database.GetReminderById(uint(id), username)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: message, Error: "database failure"})
+			return false
+		}
+		if !found {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("reminder '%s' does not exist", subject)})
+			return false
+		}
+	default:
+		if subject != "" || state != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("%s triggers do not use a subject or a state", trigger)})
+			return false
+		}
+		return true
+	}
+	if (trigger == database.TriggerSwitch || trigger == database.TriggerNode) && subject == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("%s triggers require a subject", trigger)})
+		return false
+	}
+	if state == "" {
+		return true
+	}
+	for _, validState := range validStates {
+		if state == validState {
+			return true
+		}
+	}
+	w.WriteHeader(http.StatusBadRequest)
+	Res(w, Response{Success: false, Message: message, Error: fmt.Sprintf("invalid state '%s' for %s trigger", state, trigger)})
+	return false
+}
+
 // Returns a list of all automations set up by the current user
 func GetUserAutomations(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -75,7 +316,7 @@ func CreateNewAutomation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// Check if the provided HomescriptId is valid
-	_, homescriptValid, err := database.GetUserHomescriptById(request.HomescriptId, username)
+	homescriptItem, homescriptValid, err := database.GetUserHomescriptById(request.HomescriptId, username)
 	if err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		Res(w, Response{Success: false, Message: "failed to create new automation", Error: "database failure"})
@@ -86,6 +327,12 @@ func CreateNewAutomation(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to create new automation", Error: "homescript id is invalid or not found"})
 		return
 	}
+	if !validateAutomationHomescript(w, homescriptItem, "failed to create new automation") {
+		return
+	}
+	if !validateHomescriptRevisionPin(w, request.HomescriptId, request.HomescriptRevision, "failed to create new automation") {
+		return
+	}
 	// Check if the provided hour, minute and days are valid
 	if len(request.Days) > 7 || len(request.Days) == 0 { // Check if there are more than 7 days or 0
 		w.WriteHeader(http.StatusBadRequest)
@@ -113,9 +360,7 @@ func CreateNewAutomation(w http.ResponseWriter, r *http.Request) {
 		}
 		containsDays = append(containsDays, day) // If the day is not already present, add it
 	}
-	if request.TimingMode != database.TimingNormal && request.TimingMode != database.TimingSunrise && request.TimingMode != database.TimingSunset {
-		w.WriteHeader(http.StatusBadRequest)
-		Res(w, Response{Success: false, Message: "failed to create new automation", Error: "invalid timing mode"})
+	if !validateSunTiming(w, request.TimingMode, request.Twilight, request.SunOffset, "failed to create new automation") {
 		return
 	}
 	if request.Hour > 24 || request.Minute > 60 { // Checks the minute and hour, values below 0 are checked implicitly through `uint`
@@ -123,17 +368,37 @@ func CreateNewAutomation(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to create new automation", Error: "invalid hour and / or minute"})
 		return
 	}
-	id, err := automation.CreateNewAutomation(
-		request.Name,
-		request.Description,
+	trigger, triggerValid := validateAutomationTrigger(w, username, request.AutomationTrigger, request.TimingMode, "failed to create new automation")
+	if !triggerValid {
+		return
+	}
+	cronExpr, err := automation.GenerateCronExpression(
 		uint8(request.Hour),
 		uint8(request.Minute),
 		request.Days,
-		request.HomescriptId,
-		username,
-		request.Enabled,
-		request.TimingMode,
 	)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "failed to create new automation", Error: "could not create cron expression"})
+		return
+	}
+	id, err := automation.CreateNewAutomation(database.Automation{
+		Name:               request.Name,
+		Description:        request.Description,
+		CronExpression:     cronExpr,
+		HomescriptId:       request.HomescriptId,
+		HomescriptRevision: request.HomescriptRevision,
+		Owner:              username,
+		Enabled:            request.Enabled,
+		TimingMode:         request.TimingMode,
+		Trigger:            trigger,
+		Condition:          request.Condition,
+		Hysteresis:         request.Hysteresis,
+		TriggerSubject:     request.TriggerSubject,
+		TriggerState:       request.TriggerState,
+		Twilight:           request.Twilight,
+		SunOffset:          request.SunOffset,
+	})
 	if err != nil {
 		log.Error(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
@@ -177,6 +442,78 @@ func RemoveAutomation(w http.ResponseWriter, r *http.Request) {
 	Res(w, Response{Success: true, Message: "successfully deleted automation"})
 }
 
+// Runs an automation of the current user immediately, regardless of its trigger and whether it is enabled
+// The automation is started in the background, its result is added to the run history
+func RunAutomation(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request RunAutomationRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	_, exists, err := automation.GetUserAutomationById(username, request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "failed to run automation", Error: "backend failure"})
+		return
+	}
+	if !exists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to run automation", Error: "invalid id / not found"})
+		return
+	}
+	go automation.RunAutomationNow(request.Id)
+	Res(w, Response{Success: true, Message: "automation has been started"})
+}
+
+// Returns the past runs of an automation of the current user, newest first
+// The automation is selected using the query parameter `id`
+func ListAutomationRuns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	id, err := strconv.ParseUint(r.URL.Query().Get("id"), 10, 32)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "query parameter `id` must be a valid automation id"})
+		return
+	}
+	limit, ok := getRunHistoryLimit(w, r)
+	if !ok {
+		return
+	}
+	_, exists, err := automation.GetUserAutomationById(username, uint(id))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "failed to list automation runs", Error: "backend failure"})
+		return
+	}
+	if !exists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to list automation runs", Error: "invalid id / not found"})
+		return
+	}
+	runs, err := database.ListAutomationRuns(uint(id), limit)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list automation runs", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(runs); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list automation runs", Error: "could not encode response"})
+	}
+}
+
 // Modifies a existing automation, also restarts the schedule
 func ModifyAutomation(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -205,7 +542,7 @@ func ModifyAutomation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// Check if the provided HomescriptId is valid
-	_, homescriptValid, err := database.GetUserHomescriptById(request.HomescriptId, username)
+	homescriptItem, homescriptValid, err := database.GetUserHomescriptById(request.HomescriptId, username)
 	if err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		Res(w, Response{Success: false, Message: "failed to modify automation", Error: "database failure"})
@@ -216,6 +553,12 @@ func ModifyAutomation(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to modify automation", Error: "homescript id is invalid or not found"})
 		return
 	}
+	if !validateAutomationHomescript(w, homescriptItem, "failed to modify automation") {
+		return
+	}
+	if !validateHomescriptRevisionPin(w, request.HomescriptId, request.HomescriptRevision, "failed to modify automation") {
+		return
+	}
 	// Check if the provided hour, minute and days are valid
 	if len(request.Days) > 7 || len(request.Days) == 0 { // Check if there are more than 7 days or 0
 		w.WriteHeader(http.StatusBadRequest)
@@ -248,6 +591,13 @@ func ModifyAutomation(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to modify automation", Error: "invalid hour and / or minute"})
 		return
 	}
+	if !validateSunTiming(w, request.TimingMode, request.Twilight, request.SunOffset, "failed to modify automation") {
+		return
+	}
+	trigger, triggerValid := validateAutomationTrigger(w, username, request.AutomationTrigger, request.TimingMode, "failed to modify automation")
+	if !triggerValid {
+		return
+	}
 	cronExpr, err := automation.GenerateCronExpression(
 		uint8(request.Hour),
 		uint8(request.Minute),
@@ -259,12 +609,20 @@ func ModifyAutomation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	newAutomation := database.AutomationWithoutIdAndUsername{
-		Name:           request.Name,
-		Description:    request.Description,
-		CronExpression: cronExpr,
-		HomescriptId:   request.HomescriptId,
-		Enabled:        request.Enabled,
-		TimingMode:     request.TimingMode,
+		Name:               request.Name,
+		Description:        request.Description,
+		CronExpression:     cronExpr,
+		HomescriptId:       request.HomescriptId,
+		HomescriptRevision: request.HomescriptRevision,
+		Enabled:            request.Enabled,
+		TimingMode:         request.TimingMode,
+		Trigger:            trigger,
+		Condition:          request.Condition,
+		Hysteresis:         request.Hysteresis,
+		TriggerSubject:     request.TriggerSubject,
+		TriggerState:       request.TriggerState,
+		Twilight:           request.Twilight,
+		SunOffset:          request.SunOffset,
 	}
 	if err := automation.ModifyAutomationById(request.Id, newAutomation); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
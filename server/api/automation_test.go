@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestValidateAutomationHomescript(t *testing.T) {
+	// Homescripts which are not enabled for the scheduler can not be used by automations
+	recorder := httptest.NewRecorder()
+	if validateAutomationHomescript(recorder, database.Homescript{Id: "test", SchedulerEnabled: false}, "failed to create new automation") {
+		t.Error("Homescript without `schedulerEnabled` was accepted")
+		return
+	}
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Unexpected status code: want: %d got: %d", http.StatusUnprocessableEntity, recorder.Code)
+		return
+	}
+	recorder = httptest.NewRecorder()
+	if !validateAutomationHomescript(recorder, database.Homescript{Id: "test", SchedulerEnabled: true}, "failed to create new automation") {
+		t.Error("Homescript with `schedulerEnabled` was rejected")
+		return
+	}
+}
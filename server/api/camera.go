@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
 	"github.com/MikMuellerDev/smarthome/server/middleware"
 	"github.com/MikMuellerDev/smarthome/services/camera"
 	"github.com/gorilla/mux"
@@ -119,6 +120,7 @@ func CreateCamera(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to create camera", Error: "database failure"})
 		return
 	}
+	event.Publish(event.CameraChanged{CameraId: request.Id})
 	Res(w, Response{Success: true, Message: "successfully created camera"})
 }
 
@@ -158,6 +160,7 @@ func ModifyCamera(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to modify camera", Error: "database failure"})
 		return
 	}
+	event.Publish(event.CameraChanged{CameraId: request.Id})
 	Res(w, Response{Success: true, Message: "successfully modified camera"})
 }
 
@@ -187,6 +190,7 @@ func DeleteCamera(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to delete camera", Error: "database failure"})
 		return
 	}
+	event.Publish(event.CameraChanged{CameraId: request.Id, Deleted: true})
 	Res(w, Response{Success: true, Message: "succesfully deleted camera"})
 }
 
@@ -12,6 +12,10 @@ type UpdateLocationRequest struct {
 	Longitude float32 `json:"longitude"`
 }
 
+type UpdateHomescriptTimeoutRequest struct {
+	Timeout uint `json:"timeout"` // Maximum runtime of a Homescript in seconds, 0 disables the limit
+}
+
 // Admin endpoints for changing the servers global configuration
 func UpdateLocation(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -30,3 +34,22 @@ func UpdateLocation(w http.ResponseWriter, r *http.Request) {
 	}
 	Res(w, Response{Success: true, Message: "successfully updated location"})
 }
+
+// Changes the maximum runtime of Homescripts, already running scripts keep their previous deadline
+func UpdateHomescriptTimeout(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request UpdateHomescriptTimeoutRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if err := database.UpdateHomescriptTimeout(request.Timeout); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to update Homescript timeout", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully updated Homescript timeout"})
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+	"github.com/MikMuellerDev/smarthome/services/energy"
+)
+
+type AddEnergyBudgetRequest struct {
+	TargetType     database.EnergyBudgetTarget `json:"targetType"`
+	TargetId       string                      `json:"targetId"`
+	Period         database.EnergyBudgetPeriod `json:"period"`
+	LimitWattHours uint                        `json:"limitWattHours"`
+	CutPower       bool                        `json:"cutPower"`
+}
+
+type ModifyEnergyBudgetRequest struct {
+	Id             uint                        `json:"id"`
+	Period         database.EnergyBudgetPeriod `json:"period"`
+	LimitWattHours uint                        `json:"limitWattHours"`
+	CutPower       bool                        `json:"cutPower"`
+}
+
+type DeleteEnergyBudgetRequest struct {
+	Id uint `json:"id"`
+}
+
+type AddedEnergyBudgetResponse struct {
+	Id      uint   `json:"id"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// Returns the status of the energy budgets which affect switches of the current user, used by the dashboard
+func GetUserEnergyBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	statuses, err := energy.GetUserBudgetStatuses(username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to get energy budget status", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "could not encode response"})
+	}
+}
+
+// Returns the status of all energy budgets
+func GetAllEnergyBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	statuses, err := energy.GetBudgetStatuses()
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to get energy budget status", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "could not encode response"})
+	}
+}
+
+// Adds a new energy budget to a switch or a room
+func AddEnergyBudget(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request AddEnergyBudgetRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if !isValidEnergyBudget(w, request.Period, request.LimitWattHours) {
+		return
+	}
+	var targetExists bool
+	switch request.TargetType {
+	case database.EnergyBudgetSwitch:
+		_, targetExists, err = database.GetSwitchById(request.TargetId)
+	case database.EnergyBudgetRoom:
+		_, targetExists, err = database.GetRoomDataById(request.TargetId)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "invalid energy budget", Error: "target type must be either `switch` or `room`"})
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to add energy budget", Error: "database failure"})
+		return
+	}
+	if !targetExists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to add energy budget", Error: fmt.Sprintf("no %s with id '%s' exists", request.TargetType, request.TargetId)})
+		return
+	}
+	id, err := database.CreateEnergyBudget(database.EnergyBudget{
+		Owner:            username,
+		TargetType:       request.TargetType,
+		TargetId:         request.TargetId,
+		Period:           request.Period,
+		LimitWattHours:   request.LimitWattHours,
+		CutPower:         request.CutPower,
+		AlertPeriodStart: time.Now(),
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to add energy budget", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(AddedEnergyBudgetResponse{Id: id, Success: true, Message: "successfully added energy budget"}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "could not encode response"})
+	}
+}
+
+// Changes the period, limit and power cut behaviour of an energy budget
+func ModifyEnergyBudget(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request ModifyEnergyBudgetRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if !isValidEnergyBudget(w, request.Period, request.LimitWattHours) {
+		return
+	}
+	_, found, err := database.GetEnergyBudgetById(request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to modify energy budget", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to modify energy budget", Error: "invalid id: no energy budget with this id exists"})
+		return
+	}
+	if err := database.ModifyEnergyBudget(request.Id, request.Period, request.LimitWattHours, request.CutPower); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to modify energy budget", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully modified energy budget"})
+}
+
+// Deletes an energy budget given its id
+func DeleteEnergyBudget(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request DeleteEnergyBudgetRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	_, found, err := database.GetEnergyBudgetById(request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete energy budget", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to delete energy budget", Error: "invalid id: no energy budget with this id exists"})
+		return
+	}
+	if err := database.DeleteEnergyBudgetById(request.Id); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete energy budget", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully deleted energy budget"})
+}
+
+// Validates the period and limit of a budget, sends an error response if they are invalid
+func isValidEnergyBudget(w http.ResponseWriter, period database.EnergyBudgetPeriod, limitWattHours uint) bool {
+	if period != database.EnergyBudgetDaily && period != database.EnergyBudgetMonthly {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "invalid energy budget", Error: "period must be either `daily` or `monthly`"})
+		return false
+	}
+	if limitWattHours == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "invalid energy budget", Error: "limit must be greater than 0"})
+		return false
+	}
+	return true
+}
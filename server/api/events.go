@@ -0,0 +1,127 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+)
+
+// Amount of messages of each topic which are buffered for a slow client before further messages are dropped
+const pushQueueSize = 50
+
+// Interval at which a comment is sent so that proxies do not close idle connections
+const pushKeepAliveInterval = 30 * time.Second
+
+// Decides whether a user may receive a message
+type pushFilter func(username string, payload event.Payload) (bool, error)
+
+// Contains every topic which can be received by clients and the filter which is applied to its messages
+var pushTopics = map[event.Topic]pushFilter{
+	event.TopicSwitchChanged: func(username string, payload event.Payload) (bool, error) {
+		return database.UserHasSwitchPermission(username, payload.(event.SwitchChanged).SwitchId)
+	},
+	event.TopicNodeStatus: func(username string, payload event.Payload) (bool, error) {
+		// Offline nodes affect everyone who is allowed to change power states
+		return database.UserHasPermission(username, database.PermissionPower)
+	},
+	event.TopicCameraChanged: func(username string, payload event.Payload) (bool, error) {
+		hasPermission, err := database.UserHasPermission(username, database.PermissionViewCameras)
+		if err != nil || !hasPermission {
+			return false, err
+		}
+		camera := payload.(event.CameraChanged)
+		// The permissions of a deleted camera have already been removed
+		if camera.Deleted {
+			return true, nil
+		}
+		return database.UserHasCameraPermission(username, camera.CameraId)
+	},
+	event.TopicNotification: func(username string, payload event.Payload) (bool, error) {
+		return payload.(event.Notification).Username == username, nil
+	},
+	event.TopicReminderOverdue: func(username string, payload event.Payload) (bool, error) {
+		return payload.(event.ReminderOverdue).Owner == username, nil
+	},
+	event.TopicHomescriptJob: func(username string, payload event.Payload) (bool, error) {
+		return payload.(event.HomescriptJob).Owner == username, nil
+	},
+	event.TopicAutomationRun: func(username string, payload event.Payload) (bool, error) {
+		return payload.(event.JobStatus).Owner == username, nil
+	},
+	event.TopicScheduleRun: func(username string, payload event.Payload) (bool, error) {
+		return payload.(event.JobStatus).Owner == username, nil
+	},
+}
+
+// Parses the comma-separated `topics` query parameter, all topics are selected if it is empty
+// Returns false if an unknown topic was requested
+func parsePushTopics(query string) ([]event.Topic, bool) {
+	topics := make([]event.Topic, 0)
+	if query == "" {
+		for topic := range pushTopics {
+			topics = append(topics, topic)
+		}
+		return topics, true
+	}
+	for _, item := range strings.Split(query, ",") {
+		topic := event.Topic(strings.TrimSpace(item))
+		if _, valid := pushTopics[topic]; !valid {
+			return nil, false
+		}
+		topics = append(topics, topic)
+	}
+	return topics, true
+}
+
+// Pushes events to the client using server-sent events until the client disconnects
+// The name of each event is its topic, the data is its JSON-encoded payload
+// Clients only receive events about items they are allowed to access, for example switches they have permission to use
+// The topics can be selected using the `topics` query parameter, for example `?topics=switchChanged,notification`
+func SubscribeToEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	topics, valid := parsePushTopics(r.URL.Query().Get("topics"))
+	if !valid {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid topic in query parameter `topics`"})
+		return
+	}
+	stream, ok := newEventStream(w)
+	if !ok {
+		return
+	}
+	defer stream.close()
+	for _, topic := range topics {
+		filter := pushTopics[topic]
+		subscription := event.SubscribeAsync(topic, pushQueueSize, event.OverflowDrop, func(message event.Message) {
+			allowed, err := filter(username, message.Payload)
+			if err != nil {
+				log.Error(fmt.Sprintf("Failed to push event '%s' to user '%s': could not check permission: %s", message.Topic, username, err.Error()))
+				return
+			}
+			if allowed {
+				stream.send(string(message.Topic), message.Payload)
+			}
+		})
+		defer subscription.Unsubscribe()
+	}
+	stream.send("ready", topics)
+	keepAlive := time.NewTicker(pushKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			stream.keepAlive()
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package api
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+)
+
+// Is set once the database has been initialized for the tests which require it
+var databaseInitialized struct {
+	once sync.Once
+	err  error
+}
+
+// Initializes the test database on first use
+// Tests which require the database are skipped if it is unavailable, so that the other tests of this package run without it
+func requireDatabase(t *testing.T) {
+	databaseInitialized.once.Do(func() {
+		databaseInitialized.err = initDB(true)
+	})
+	if databaseInitialized.err != nil {
+		t.Skipf("Database is unavailable: %s", databaseInitialized.err.Error())
+	}
+}
+
+func initDB(args ...bool) error {
+	log := logrus.New()
+	log.Level = logrus.FatalLevel
+	database.InitLogger(log)
+	if err := database.Init(database.DatabaseConfig{
+		Username: "smarthome",
+		Password: "testing",
+		Hostname: "localhost",
+		Database: "smarthome",
+		Port:     3330,
+	}, "admin",
+	); err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		if err := database.DeleteTables(); err != nil {
+			return err
+		}
+		time.Sleep(time.Second)
+		return initDB()
+	}
+	return nil
+}
+
+// Creates a switch and a camera and users with different permissions to access them
+// `events_allowed` may use both, `events_denied` may view cameras but not this one, `events_none` has no permissions
+func createPushFilterData() error {
+	if err := database.CreateRoom(database.RoomData{Id: "events_test"}); err != nil {
+		return err
+	}
+	if err := database.CreateSwitch(database.Switch{Id: "events_test", RoomId: "events_test"}); err != nil {
+		return err
+	}
+	if err := database.CreateCamera(database.Camera{Id: "events_test", RoomId: "events_test"}); err != nil {
+		return err
+	}
+	for _, username := range []string{"events_allowed", "events_denied", "events_none"} {
+		if err := database.AddUser(database.FullUser{Username: username}); err != nil {
+			return err
+		}
+	}
+	for _, username := range []string{"events_allowed", "events_denied"} {
+		if err := database.AddUserPermission(username, database.PermissionViewCameras); err != nil {
+			return err
+		}
+	}
+	if _, err := database.AddUserSwitchPermission("events_allowed", "events_test"); err != nil {
+		return err
+	}
+	return database.AddUserCameraPermission("events_allowed", "events_test")
+}
+
+// Messages about switches, nodes and cameras are filtered using the permissions of the user
+func TestPushTopicPermissionFilters(t *testing.T) {
+	requireDatabase(t)
+	if err := createPushFilterData(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	table := []struct {
+		Name     string
+		Topic    event.Topic
+		Payload  event.Payload
+		Username string
+		Allowed  bool
+	}{
+		{
+			Name:     "switch with permission",
+			Topic:    event.TopicSwitchChanged,
+			Payload:  event.SwitchChanged{SwitchId: "events_test", PowerOn: true},
+			Username: "events_allowed",
+			Allowed:  true,
+		},
+		{
+			Name:     "switch without permission",
+			Topic:    event.TopicSwitchChanged,
+			Payload:  event.SwitchChanged{SwitchId: "events_test", PowerOn: true},
+			Username: "events_denied",
+			Allowed:  false,
+		},
+		{
+			Name:     "node status with power permission",
+			Topic:    event.TopicNodeStatus,
+			Payload:  event.NodeStatus{},
+			Username: "admin",
+			Allowed:  true,
+		},
+		{
+			Name:     "node status without power permission",
+			Topic:    event.TopicNodeStatus,
+			Payload:  event.NodeStatus{},
+			Username: "events_none",
+			Allowed:  false,
+		},
+		{
+			Name:     "camera with permission",
+			Topic:    event.TopicCameraChanged,
+			Payload:  event.CameraChanged{CameraId: "events_test"},
+			Username: "events_allowed",
+			Allowed:  true,
+		},
+		{
+			Name:     "camera without camera permission",
+			Topic:    event.TopicCameraChanged,
+			Payload:  event.CameraChanged{CameraId: "events_test"},
+			Username: "events_denied",
+			Allowed:  false,
+		},
+		{
+			Name:     "camera without permission to view cameras",
+			Topic:    event.TopicCameraChanged,
+			Payload:  event.CameraChanged{CameraId: "events_test"},
+			Username: "events_none",
+			Allowed:  false,
+		},
+		{
+			Name:     "deleted camera with permission to view cameras",
+			Topic:    event.TopicCameraChanged,
+			Payload:  event.CameraChanged{CameraId: "events_deleted", Deleted: true},
+			Username: "events_denied",
+			Allowed:  true,
+		},
+		{
+			Name:     "deleted camera without permission to view cameras",
+			Topic:    event.TopicCameraChanged,
+			Payload:  event.CameraChanged{CameraId: "events_deleted", Deleted: true},
+			Username: "events_none",
+			Allowed:  false,
+		},
+	}
+	for _, test := range table {
+		allowed, err := pushTopics[test.Topic](test.Username, test.Payload)
+		if err != nil {
+			t.Errorf("Filter failed for %s: %s", test.Name, err.Error())
+			return
+		}
+		if allowed != test.Allowed {
+			t.Errorf("Unexpected result for %s: want: %t got: %t", test.Name, test.Allowed, allowed)
+		}
+	}
+}
+
+// Messages about personal items are only pushed to their owner
+func TestPushTopicOwnerFilters(t *testing.T) {
+	table := []struct {
+		Name     string
+		Topic    event.Topic
+		Payload  event.Payload
+		Username string
+		Allowed  bool
+	}{
+		{
+			Name:     "own notification",
+			Topic:    event.TopicNotification,
+			Payload:  event.Notification{Username: "events_none"},
+			Username: "events_none",
+			Allowed:  true,
+		},
+		{
+			Name:     "notification of another user",
+			Topic:    event.TopicNotification,
+			Payload:  event.Notification{Username: "events_allowed"},
+			Username: "events_none",
+			Allowed:  false,
+		},
+		{
+			Name:     "own overdue reminder",
+			Topic:    event.TopicReminderOverdue,
+			Payload:  event.ReminderOverdue{Owner: "events_none"},
+			Username: "events_none",
+			Allowed:  true,
+		},
+		{
+			Name:     "overdue reminder of another user",
+			Topic:    event.TopicReminderOverdue,
+			Payload:  event.ReminderOverdue{Owner: "admin"},
+			Username: "events_none",
+			Allowed:  false,
+		},
+		{
+			Name:     "own Homescript job",
+			Topic:    event.TopicHomescriptJob,
+			Payload:  event.HomescriptJob{Owner: "events_none"},
+			Username: "events_none",
+			Allowed:  true,
+		},
+		{
+			Name:     "Homescript job of another user",
+			Topic:    event.TopicHomescriptJob,
+			Payload:  event.HomescriptJob{Owner: "admin"},
+			Username: "events_none",
+			Allowed:  false,
+		},
+		{
+			Name:     "own automation run",
+			Topic:    event.TopicAutomationRun,
+			Payload:  event.JobStatus{Kind: event.TopicAutomationRun, Owner: "events_none"},
+			Username: "events_none",
+			Allowed:  true,
+		},
+		{
+			Name:     "automation run of another user",
+			Topic:    event.TopicAutomationRun,
+			Payload:  event.JobStatus{Kind: event.TopicAutomationRun, Owner: "admin"},
+			Username: "events_none",
+			Allowed:  false,
+		},
+		{
+			Name:     "own schedule run",
+			Topic:    event.TopicScheduleRun,
+			Payload:  event.JobStatus{Kind: event.TopicScheduleRun, Owner: "events_none"},
+			Username: "events_none",
+			Allowed:  true,
+		},
+		{
+			Name:     "schedule run of another user",
+			Topic:    event.TopicScheduleRun,
+			Payload:  event.JobStatus{Kind: event.TopicScheduleRun, Owner: "admin"},
+			Username: "events_none",
+			Allowed:  false,
+		},
+	}
+	for _, test := range table {
+		allowed, err := pushTopics[test.Topic](test.Username, test.Payload)
+		if err != nil {
+			t.Errorf("Filter failed for %s: %s", test.Name, err.Error())
+			return
+		}
+		if allowed != test.Allowed {
+			t.Errorf("Unexpected result for %s: want: %t got: %t", test.Name, test.Allowed, allowed)
+		}
+	}
+}
+
+func TestParsePushTopics(t *testing.T) {
+	table := []struct {
+		Query  string
+		Topics []event.Topic
+		Valid  bool
+	}{
+		{Query: "switchChanged", Topics: []event.Topic{event.TopicSwitchChanged}, Valid: true},
+		{Query: "switchChanged, notification", Topics: []event.Topic{event.TopicSwitchChanged, event.TopicNotification}, Valid: true},
+		{Query: "unknown", Valid: false},
+		{Query: "switchChanged,unknown", Valid: false},
+		{Query: "switchChanged,", Valid: false},
+		// Login events are not pushed to clients
+		{Query: "userLogin", Valid: false},
+	}
+	for _, test := range table {
+		topics, valid := parsePushTopics(test.Query)
+		if valid != test.Valid {
+			t.Errorf("Unexpected validity of query '%s': want: %t got: %t", test.Query, test.Valid, valid)
+			return
+		}
+		if valid && !reflect.DeepEqual(topics, test.Topics) {
+			t.Errorf("Unexpected topics of query '%s': want: %v got: %v", test.Query, test.Topics, topics)
+			return
+		}
+	}
+	// An empty query selects every topic
+	topics, valid := parsePushTopics("")
+	if !valid || len(topics) != len(pushTopics) {
+		t.Errorf("Empty query did not select every topic: got: %v", topics)
+	}
+}
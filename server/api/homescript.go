@@ -25,16 +25,137 @@ type CreateHomescriptRequest struct {
 	QuickActionsEnabled bool   `json:"quickActionsEnabled"`
 	SchedulerEnabled    bool   `json:"schedulerEnabled"`
 	Code                string `json:"code"`
+	RunRetention        uint   `json:"runRetention"` // 0 uses the default retention
+	QuickActionIcon     string `json:"quickActionIcon"`
+	QuickActionColor    string `json:"quickActionColor"`
+}
+
+// Is sent as the last event of a streamed run
+type HomescriptStreamResult struct {
+	Success  bool                         `json:"success"`
+	Exitcode int                          `json:"exitCode"`
+	Errors   []homescript.HomescriptError `json:"error"`
 }
 
 type HomescriptLiveRunRequest struct {
 	Code string `json:"code"`
 }
 
+type HomescriptRunRequest struct {
+	Id   string            `json:"id"`
+	Args map[string]string `json:"args"` // Can be read by the script using `arg` and `hasArg`
+}
+
+// Either `id` or `code` must be set
+type HomescriptDryRunRequest struct {
+	Id   string            `json:"id"`
+	Code string            `json:"code"`
+	Args map[string]string `json:"args"`
+}
+
+type HomescriptDryRunResponse struct {
+	Success  bool                         `json:"success"`
+	Exitcode int                          `json:"exitCode"`
+	Message  string                       `json:"message"`
+	Output   string                       `json:"output"`
+	Errors   []homescript.HomescriptError `json:"error"`
+	Actions  []homescript.DryRunAction    `json:"actions"`
+}
+
 type HomescriptIdRequest struct {
 	Id string `json:"id"`
 }
 
+type PersonalHomescriptList struct {
+	Personal []database.Homescript       `json:"personal"`
+	Shared   []database.SharedHomescript `json:"shared"`
+}
+
+// Validates the upper bound of the run retention, sends an error response if it is invalid
+func validateRunRetention(w http.ResponseWriter, retention uint) bool {
+	if retention > maxRunRetention {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: fmt.Sprintf("run retention must not exceed %d runs", maxRunRetention)})
+		return false
+	}
+	return true
+}
+
+// Limits the arguments which can be passed to a saved Homescript
+const (
+	maxHomescriptArgs        = 50
+	maxHomescriptArgKeyLen   = 50
+	maxHomescriptArgValueLen = 1000
+)
+
+// Writes the result of a Homescript run using the same shape for every run endpoint
+func sendHomescriptResult(w http.ResponseWriter, output string, exitCode int, hmsErrors []homescript.HomescriptError) {
+	response := HomescriptResponse{
+		Success:  true,
+		Message:  "Homescript ran successfully",
+		Output:   output,
+		Exitcode: exitCode,
+		Errors:   hmsErrors,
+	}
+	if len(hmsErrors) > 0 {
+		response.Success = false
+		response.Message = "Homescript terminated abnormally"
+	} else if exitCode != 0 {
+		response.Success = false
+		response.Message = "Homescript exited with a non-0 status code"
+	}
+	if !response.Success {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "could not encode response", Error: "could not encode response"})
+	}
+}
+
+// Runs a saved Homescript of the current user by its id and passes the given arguments to it
+// Homescripts which have been shared with the user can be run as well, the script always uses the permissions of the current user
+func RunHomescriptById(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request HomescriptRunRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if len(request.Args) > maxHomescriptArgs {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: fmt.Sprintf("at most %d arguments can be passed", maxHomescriptArgs)})
+		return
+	}
+	for key, value := range request.Args {
+		if key == "" || len(key) > maxHomescriptArgKeyLen || len(value) > maxHomescriptArgValueLen {
+			w.WriteHeader(http.StatusBadRequest)
+			Res(w, Response{Success: false, Message: "bad request", Error: fmt.Sprintf("argument keys must not be empty, maximum lengths for keys and values are %d and %d", maxHomescriptArgKeyLen, maxHomescriptArgValueLen)})
+			return
+		}
+	}
+	homescriptItem, found, err := database.GetAccessibleHomescriptById(request.Id, username, database.HomescriptAccessRun)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to run Homescript", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to run Homescript", Error: "not found / permission denied: no data is associated to this id"})
+		return
+	}
+	output, exitCode, hmsErrors := homescript.RunSaved(username, homescriptItem, homescript.TriggerApi, request.Args)
+	sendHomescriptResult(w, output, exitCode, hmsErrors)
+}
+
 // Runs any given Homescript as a string
 func RunHomescriptString(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -50,51 +171,108 @@ func RunHomescriptString(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
 		return
 	}
-	output, exitCode, hmsErrors := homescript.Run(username, "live", request.Code)
-	if len(hmsErrors) > 0 {
-		w.WriteHeader(http.StatusInternalServerError)
-		if err := json.NewEncoder(w).Encode(
-			HomescriptResponse{
-				Success:  false,
-				Exitcode: exitCode,
-				Message:  "Homescript terminated abnormally",
-				Output:   output,
-				Errors:   hmsErrors,
-			}); err != nil {
-			log.Error(err.Error())
-			Res(w, Response{Success: false, Message: "could not encode response", Error: "could not encode response"})
-		}
+	output, exitCode, hmsErrors := homescript.Run(username, "live", request.Code, homescript.TriggerLive)
+	sendHomescriptResult(w, output, exitCode, hmsErrors)
+}
+
+// Runs a saved Homescript or the given code without side effects
+// Returns the ordered list of actions which the script would have performed alongside its output
+func DryRunHomescript(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
 		return
 	}
-	if exitCode != 0 {
-		w.WriteHeader(http.StatusInternalServerError)
-		if err := json.NewEncoder(w).Encode(
-			HomescriptResponse{
-				Success:  false,
-				Exitcode: exitCode,
-				Message:  "Homescript exited with a non-0 status code",
-				Output:   output,
-				Errors:   hmsErrors,
-			}); err != nil {
-			log.Error(err.Error())
-			Res(w, Response{Success: false, Message: "could not encode response", Error: "could not encode response"})
-		}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request HomescriptDryRunRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if (request.Id == "") == (request.Code == "") {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "either `id` or `code` is required"})
 		return
 	}
-	if err := json.NewEncoder(w).Encode(
-		HomescriptResponse{
-			Success:  true,
-			Message:  "Homescript ran successfully",
-			Output:   output,
-			Exitcode: exitCode,
-			Errors:   hmsErrors,
-		}); err != nil {
+	var output string
+	var exitCode int
+	var hmsErrors []homescript.HomescriptError
+	var actions []homescript.DryRunAction
+	if request.Id != "" {
+		homescriptItem, found, err := database.GetAccessibleHomescriptById(request.Id, username, database.HomescriptAccessRun)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: "failed to analyze Homescript", Error: "database failure"})
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			Res(w, Response{Success: false, Message: "failed to analyze Homescript", Error: "not found / permission denied: no data is associated to this id"})
+			return
+		}
+		output, exitCode, hmsErrors, actions = homescript.DryRunSaved(username, homescriptItem, request.Args)
+	} else {
+		output, exitCode, hmsErrors, actions = homescript.DryRun(username, "live", request.Code, request.Args)
+	}
+	response := HomescriptDryRunResponse{
+		Success:  len(hmsErrors) == 0 && exitCode == 0,
+		Exitcode: exitCode,
+		Message:  "Homescript was analyzed successfully",
+		Output:   output,
+		Errors:   hmsErrors,
+		Actions:  actions,
+	}
+	if !response.Success {
+		response.Message = "Homescript would terminate abnormally"
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
 		log.Error(err.Error())
 		Res(w, Response{Success: false, Message: "could not encode response", Error: "could not encode response"})
 	}
 }
 
-// Returns a list of homescripts which are owned by the current user
+// Runs any given Homescript as a string and streams its progress using server-sent events
+// Print output, switch actions and errors are sent as they happen, the last event is `exit` which contains the exit code and errors
+// If the client disconnects, the script is terminated
+func RunHomescriptStringStream(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request HomescriptLiveRunRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	stream, ok := newEventStream(w)
+	if !ok {
+		return
+	}
+	defer stream.close()
+	_, exitCode, hmsErrors := homescript.RunStreaming(
+		r.Context(),
+		username,
+		"live",
+		request.Code,
+		homescript.TriggerLive,
+		func(event homescript.StreamEvent) {
+			stream.send(string(event.Type), event)
+		},
+	)
+	stream.send("exit", HomescriptStreamResult{
+		Success:  len(hmsErrors) == 0 && exitCode == 0,
+		Exitcode: exitCode,
+		Errors:   hmsErrors,
+	})
+}
+
+// Returns a list of homescripts which are owned by the current user and a separate list of homescripts which have been shared with the user
 func ListPersonalHomescripts(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	username, err := middleware.GetUserFromCurrentSession(w, r)
@@ -107,7 +285,16 @@ func ListPersonalHomescripts(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to list personal homescript", Error: "database failure"})
 		return
 	}
-	if err := json.NewEncoder(w).Encode(homescriptList); err != nil {
+	sharedList, err := database.ListSharedHomescripts(username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list personal homescript", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(PersonalHomescriptList{
+		Personal: homescriptList,
+		Shared:   sharedList,
+	}); err != nil {
 		log.Error(err.Error())
 		Res(w, Response{Success: false, Message: "failed to list personal homescript", Error: "could not encode response"})
 	}
@@ -139,6 +326,12 @@ func CreateNewHomescript(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to add homescript", Error: fmt.Sprintf("the id: '%s' is already present in the database, use another one", request.Id)})
 		return
 	}
+	if !validateRunRetention(w, request.RunRetention) {
+		return
+	}
+	if !validateQuickActionMetadata(w, request.QuickActionIcon, request.QuickActionColor) {
+		return
+	}
 	homescriptToAdd := database.Homescript{
 		Id:                  request.Id,
 		Owner:               username,
@@ -147,12 +340,20 @@ func CreateNewHomescript(w http.ResponseWriter, r *http.Request) {
 		QuickActionsEnabled: request.QuickActionsEnabled,
 		SchedulerEnabled:    request.SchedulerEnabled,
 		Code:                request.Code,
+		RunRetention:        request.RunRetention,
+		QuickActionIcon:     request.QuickActionIcon,
+		QuickActionColor:    request.QuickActionColor,
 	}
 	if err := database.CreateNewHomescript(homescriptToAdd); err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		Res(w, Response{Success: false, Message: "failed to create new homescript", Error: "database failure"})
 		return
 	}
+	if err := addHomescriptRevision(homescriptToAdd, username, homescriptToAdd.Code); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to create new homescript: could not store initial revision", Error: "database failure"})
+		return
+	}
 	Res(w, Response{Success: true, Message: "successfully created new homescript"})
 }
 
@@ -190,7 +391,23 @@ func DeleteHomescriptById(w http.ResponseWriter, r *http.Request) {
 	Res(w, Response{Success: true, Message: "successfully deleted homescript"})
 }
 
+// Returns whether the modification only changes the code of the Homescript
+// Users who have been granted edit access are not allowed to change its metadata
+func onlyCodeModified(homescriptBefore database.Homescript, homescriptMetadata database.HomescriptFrontend) bool {
+	return homescriptMetadata == database.HomescriptFrontend{
+		Name:                homescriptBefore.Name,
+		Description:         homescriptBefore.Description,
+		QuickActionsEnabled: homescriptBefore.QuickActionsEnabled,
+		SchedulerEnabled:    homescriptBefore.SchedulerEnabled,
+		Code:                homescriptMetadata.Code,
+		RunRetention:        homescriptBefore.RunRetention,
+		QuickActionIcon:     homescriptBefore.QuickActionIcon,
+		QuickActionColor:    homescriptBefore.QuickActionColor,
+	}
+}
+
 // Modifies the metadata of a given homescript
+// Can also be used by users who have been granted edit access to the Homescript, they may only change its code
 func ModifyHomescript(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	username, err := middleware.GetUserFromCurrentSession(w, r)
@@ -205,7 +422,7 @@ func ModifyHomescript(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
 		return
 	}
-	_, exists, err := database.GetUserHomescriptById(request.Id, username)
+	homescriptBefore, exists, err := database.GetAccessibleHomescriptById(request.Id, username, database.HomescriptAccessEdit)
 	if err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		Res(w, Response{Success: false, Message: "failed to modify homescript: could not validate existence", Error: "database failure"})
@@ -216,12 +433,34 @@ func ModifyHomescript(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to modify homescript", Error: "not found / permission denied: no data is associated to this id"})
 		return
 	}
+	if !validateRunRetention(w, request.RunRetention) {
+		return
+	}
+	if !validateQuickActionMetadata(w, request.QuickActionIcon, request.QuickActionColor) {
+		return
+	}
 	homescriptMetadata := database.HomescriptFrontend{
 		Name:                request.Name,
 		Description:         request.Description,
 		QuickActionsEnabled: request.QuickActionsEnabled,
 		SchedulerEnabled:    request.SchedulerEnabled,
 		Code:                request.Code,
+		RunRetention:        request.RunRetention,
+		QuickActionIcon:     request.QuickActionIcon,
+		QuickActionColor:    request.QuickActionColor,
+	}
+	if homescriptBefore.Owner != username && !onlyCodeModified(homescriptBefore, homescriptMetadata) {
+		w.WriteHeader(http.StatusForbidden)
+		Res(w, Response{Success: false, Message: "failed to modify homescript", Error: "permission denied: only the owner may modify the metadata of this Homescript"})
+		return
+	}
+	// Every change of the code is stored as a revision before the code is overwritten
+	if homescriptBefore.Code != request.Code {
+		if err := addHomescriptRevision(homescriptBefore, username, request.Code); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: "failed to modify homescript: could not store revision", Error: "database failure"})
+			return
+		}
 	}
 	if err := database.ModifyHomescriptById(request.Id, homescriptMetadata); err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
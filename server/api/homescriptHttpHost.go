@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+)
+
+type AddHomescriptHttpHostRequest struct {
+	Host        string `json:"host"`
+	Description string `json:"description"`
+}
+
+type DeleteHomescriptHttpHostRequest struct {
+	Host string `json:"host"`
+}
+
+// Returns the hosts which Homescripts are allowed to send HTTP requests to
+func ListHomescriptHttpHosts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	hosts, err := database.ListHomescriptHttpHosts()
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list allowed hosts", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(hosts); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list allowed hosts", Error: "could not encode response"})
+	}
+}
+
+// Allows Homescripts to send HTTP requests to a host, admin auth required
+func AddHomescriptHttpHost(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request AddHomescriptHttpHostRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if request.Host == "" || len(request.Host) > 255 || strings.ContainsAny(request.Host, " /:") {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "host must be a hostname or IP address without scheme, port or path"})
+		return
+	}
+	if err := database.AddHomescriptHttpHost(database.HomescriptHttpHost{
+		Host:        request.Host,
+		Description: request.Description,
+	}); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to add allowed host", Error: "database failure"})
+		return
+	}
+	go event.Info("Homescript HTTP Host Allowed", fmt.Sprintf("Homescripts may now send HTTP requests to '%s'", request.Host))
+	Res(w, Response{Success: true, Message: "successfully added allowed host"})
+}
+
+// Prevents Homescripts from sending further HTTP requests to a host, admin auth required
+func DeleteHomescriptHttpHost(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request DeleteHomescriptHttpHostRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	allowed, err := database.IsHomescriptHttpHostAllowed(request.Host)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete allowed host", Error: "database failure"})
+		return
+	}
+	if !allowed {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to delete allowed host", Error: "invalid host: the host is not on the allowlist"})
+		return
+	}
+	if err := database.DeleteHomescriptHttpHost(request.Host); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete allowed host", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully deleted allowed host"})
+}
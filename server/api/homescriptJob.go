@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+	"github.com/MikMuellerDev/smarthome/core/homescript"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+)
+
+type KillHomescriptJobRequest struct {
+	Id uint64 `json:"id"`
+}
+
+// Returns the currently running Homescripts of the current user
+func ListPersonalHomescriptJobs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(homescript.GetUserJobs(username)); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "could not list personal Homescript jobs", Error: "could not encode content"})
+	}
+}
+
+// Returns the currently running Homescripts of all users
+func ListAllHomescriptJobs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(homescript.GetJobs()); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "could not list all Homescript jobs", Error: "could not encode content"})
+	}
+}
+
+// Terminates a running Homescript
+// Users can only kill their own jobs unless they have the permission to manage all running Homescripts
+func KillHomescriptJob(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request KillHomescriptJobRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	job, found, err := getKillableJob(username, request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to kill Homescript job", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to kill Homescript job", Error: "invalid id: no running job with this id exists or you do not have access to it"})
+		return
+	}
+	if !homescript.KillJob(job.Id) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to kill Homescript job", Error: "job has already terminated"})
+		return
+	}
+	go event.Info("Homescript Killed", fmt.Sprintf("User '%s' killed Homescript '%s' (job %d) of user '%s'", username, job.ScriptLabel, job.Id, job.Owner))
+	Res(w, Response{Success: true, Message: "successfully killed Homescript job"})
+}
+
+// Returns a running job if the user is allowed to terminate it
+func getKillableJob(username string, id uint64) (homescript.Job, bool, error) {
+	job, found := homescript.GetJobById(id)
+	if !found {
+		return homescript.Job{}, false, nil
+	}
+	if job.Owner == username {
+		return job, true, nil
+	}
+	hasPermission, err := database.UserHasPermission(username, database.PermissionManageHomescripts)
+	if err != nil {
+		return homescript.Job{}, false, err
+	}
+	if !hasPermission {
+		return homescript.Job{}, false, nil
+	}
+	return job, true, nil
+}
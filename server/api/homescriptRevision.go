@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/homescript"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+)
+
+// A revision id of 0 refers to the current code of the Homescript
+type HomescriptRevisionDiffRequest struct {
+	Id   string `json:"id"`
+	From uint   `json:"from"`
+	To   uint   `json:"to"`
+}
+
+type HomescriptRevisionRestoreRequest struct {
+	Id       string `json:"id"`
+	Revision uint   `json:"revision"`
+}
+
+// Stores the given code as a new revision of a Homescript
+// If the Homescript has no revisions yet, its previous code is stored first so that it can be restored later
+func addHomescriptRevision(homescriptItem database.Homescript, author string, code string) error {
+	revisions, err := database.ListHomescriptRevisions(homescriptItem.Id)
+	if err != nil {
+		return err
+	}
+	if len(revisions) == 0 && homescriptItem.Code != code {
+		if _, err := database.AddHomescriptRevision(database.HomescriptRevision{
+			HomescriptId: homescriptItem.Id,
+			Author:       homescriptItem.Owner,
+			CreatedAt:    time.Now(),
+			Code:         homescriptItem.Code,
+		}); err != nil {
+			return err
+		}
+	}
+	_, err = database.AddHomescriptRevision(database.HomescriptRevision{
+		HomescriptId: homescriptItem.Id,
+		Author:       author,
+		CreatedAt:    time.Now(),
+		Code:         code,
+	})
+	return err
+}
+
+// Returns the code of a revision of a Homescript, the revision id 0 returns the current code
+func getRevisionCode(homescriptItem database.Homescript, revisionId uint) (string, bool, error) {
+	if revisionId == 0 {
+		return homescriptItem.Code, true, nil
+	}
+	revision, found, err := database.GetHomescriptRevision(homescriptItem.Id, revisionId)
+	if err != nil || !found {
+		return "", found, err
+	}
+	return revision.Code, true, nil
+}
+
+// Returns the revisions of a Homescript of the current user, newest first
+// The Homescript is selected using the `id` query parameter
+func ListHomescriptRevisions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	homescriptId := r.URL.Query().Get("id")
+	_, found, err := database.GetAccessibleHomescriptById(homescriptId, username, database.HomescriptAccessView)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list Homescript revisions", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to list Homescript revisions", Error: "not found / permission denied: no data is associated to this id"})
+		return
+	}
+	revisions, err := database.ListHomescriptRevisions(homescriptId)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list Homescript revisions", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(revisions); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list Homescript revisions", Error: "could not encode response"})
+	}
+}
+
+// Returns a line-based diff between two revisions of a Homescript of the current user
+func DiffHomescriptRevisions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request HomescriptRevisionDiffRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	homescriptItem, found, err := database.GetAccessibleHomescriptById(request.Id, username, database.HomescriptAccessView)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to diff Homescript revisions", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to diff Homescript revisions", Error: "not found / permission denied: no data is associated to this id"})
+		return
+	}
+	codes := make([]string, 0)
+	for _, revisionId := range []uint{request.From, request.To} {
+		code, found, err := getRevisionCode(homescriptItem, revisionId)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: "failed to diff Homescript revisions", Error: "database failure"})
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			Res(w, Response{Success: false, Message: "failed to diff Homescript revisions", Error: fmt.Sprintf("invalid revision: %d", revisionId)})
+			return
+		}
+		codes = append(codes, code)
+	}
+	if err := json.NewEncoder(w).Encode(homescript.DiffCode(codes[0], codes[1])); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to diff Homescript revisions", Error: "could not encode response"})
+	}
+}
+
+// Replaces the code of a Homescript of the current user with the code of a previous revision
+// The restored code is stored as a new revision so that the restore itself can be undone
+func RestoreHomescriptRevision(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request HomescriptRevisionRestoreRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	homescriptItem, found, err := database.GetAccessibleHomescriptById(request.Id, username, database.HomescriptAccessEdit)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to restore Homescript revision", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to restore Homescript revision", Error: "not found / permission denied: no data is associated to this id"})
+		return
+	}
+	revision, found, err := database.GetHomescriptRevision(request.Id, request.Revision)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to restore Homescript revision", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to restore Homescript revision", Error: fmt.Sprintf("invalid revision: %d", request.Revision)})
+		return
+	}
+	if revision.Code == homescriptItem.Code {
+		Res(w, Response{Success: true, Message: "code unchanged"})
+		return
+	}
+	if err := addHomescriptRevision(homescriptItem, username, revision.Code); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to restore Homescript revision", Error: "database failure"})
+		return
+	}
+	if err := database.ModifyHomescriptById(request.Id, database.HomescriptFrontend{
+		Name:                homescriptItem.Name,
+		Description:         homescriptItem.Description,
+		QuickActionsEnabled: homescriptItem.QuickActionsEnabled,
+		SchedulerEnabled:    homescriptItem.SchedulerEnabled,
+		Code:                revision.Code,
+		RunRetention:        homescriptItem.RunRetention,
+		QuickActionIcon:     homescriptItem.QuickActionIcon,
+		QuickActionColor:    homescriptItem.QuickActionColor,
+	}); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to restore Homescript revision", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: fmt.Sprintf("successfully restored revision %d", request.Revision)})
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+)
+
+// Limits the amount of runs which are returned by a single request
+const (
+	defaultRunHistoryLimit = 50
+	maxRunHistoryLimit     = 500
+)
+
+// Maximum amount of past runs which can be retained per Homescript
+const maxRunRetention = 1000
+
+// Reads the optional `limit` query parameter, sends an error response if it is invalid
+func getRunHistoryLimit(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	limitQuery := r.URL.Query().Get("limit")
+	if limitQuery == "" {
+		return defaultRunHistoryLimit, true
+	}
+	limit, err := strconv.ParseUint(limitQuery, 10, 32)
+	if err != nil || limit == 0 || limit > maxRunHistoryLimit {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "limit must be a number between 1 and 500"})
+		return 0, false
+	}
+	return uint(limit), true
+}
+
+// Returns the past runs of the current user, newest first
+// The optional query parameter `id` only returns the runs of the given Homescript
+func ListPersonalHomescriptRuns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	limit, ok := getRunHistoryLimit(w, r)
+	if !ok {
+		return
+	}
+	runs, err := database.ListUserHomescriptRuns(username, r.URL.Query().Get("id"), limit)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list personal Homescript runs", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(runs); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list personal Homescript runs", Error: "could not encode response"})
+	}
+}
+
+// Returns the past runs of all users, newest first
+func ListAllHomescriptRuns(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	limit, ok := getRunHistoryLimit(w, r)
+	if !ok {
+		return
+	}
+	runs, err := database.ListAllHomescriptRuns(limit)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list all Homescript runs", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(runs); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list all Homescript runs", Error: "could not encode response"})
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+)
+
+// Either `username` or `groupId` must be set
+type AddHomescriptShareRequest struct {
+	HomescriptId string                    `json:"homescriptId"`
+	Username     string                    `json:"username"`
+	GroupId      string                    `json:"groupId"`
+	Access       database.HomescriptAccess `json:"access"`
+}
+
+type DeleteHomescriptShareRequest struct {
+	Id uint `json:"id"`
+}
+
+// Returns the grants of a Homescript which is owned by the current user
+// The Homescript is selected using the `id` query parameter
+func ListHomescriptShares(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	homescriptId := r.URL.Query().Get("id")
+	_, found, err := database.GetUserHomescriptById(homescriptId, username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list Homescript shares", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to list Homescript shares", Error: "not found / permission denied: no data is associated to this id"})
+		return
+	}
+	shares, err := database.ListHomescriptShares(homescriptId)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list Homescript shares", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(shares); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list Homescript shares", Error: "could not encode response"})
+	}
+}
+
+// Shares a Homescript of the current user with another user or with a group
+func AddHomescriptShare(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request AddHomescriptShareRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if (request.Username == "") == (request.GroupId == "") {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "either `username` or `groupId` is required"})
+		return
+	}
+	if !database.IsValidHomescriptAccess(request.Access) {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: fmt.Sprintf("invalid access '%s': valid values are 'view', 'run' and 'edit'", request.Access)})
+		return
+	}
+	_, found, err := database.GetUserHomescriptById(request.HomescriptId, username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to share Homescript", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to share Homescript", Error: "not found / permission denied: no data is associated to this id"})
+		return
+	}
+	share := database.HomescriptShare{
+		HomescriptId: request.HomescriptId,
+		Access:       request.Access,
+	}
+	if request.Username != "" {
+		if request.Username == username {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			Res(w, Response{Success: false, Message: "failed to share Homescript", Error: "a Homescript cannot be shared with its owner"})
+			return
+		}
+		_, userExists, err := database.GetUserByUsername(request.Username)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: "failed to share Homescript", Error: "database failure"})
+			return
+		}
+		if !userExists {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			Res(w, Response{Success: false, Message: "failed to share Homescript", Error: "invalid username"})
+			return
+		}
+		share.Username = &request.Username
+	} else {
+		_, groupExists, err := database.GetUserGroupById(request.GroupId)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: "failed to share Homescript", Error: "database failure"})
+			return
+		}
+		if !groupExists {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			Res(w, Response{Success: false, Message: "failed to share Homescript", Error: "invalid group id"})
+			return
+		}
+		share.GroupId = &request.GroupId
+	}
+	id, err := database.AddHomescriptShare(share)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to share Homescript", Error: "database failure"})
+		return
+	}
+	go event.Info("Homescript Shared", fmt.Sprintf("User '%s' shared Homescript '%s' with %s access", username, request.HomescriptId, request.Access))
+	Res(w, Response{Success: true, Message: fmt.Sprintf("successfully shared Homescript: share id: %d", id)})
+}
+
+// Revokes a grant of a Homescript which is owned by the current user
+func DeleteHomescriptShare(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request DeleteHomescriptShareRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	share, found, err := database.GetHomescriptShareById(request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete Homescript share", Error: "database failure"})
+		return
+	}
+	if found {
+		_, found, err = database.GetUserHomescriptById(share.HomescriptId, username)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: "failed to delete Homescript share", Error: "database failure"})
+			return
+		}
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to delete Homescript share", Error: "not found / permission denied: no data is associated to this id"})
+		return
+	}
+	if err := database.DeleteHomescriptShareById(request.Id); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete Homescript share", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully deleted Homescript share"})
+}
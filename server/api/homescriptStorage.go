@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/homescript"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+)
+
+type SetStorageEntryRequest struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Global bool   `json:"global"`
+}
+
+type DeleteStorageEntryRequest struct {
+	Key    string `json:"key"`
+	Global bool   `json:"global"`
+}
+
+// Sends the error response of a failed modification of the storage
+func writeStorageError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, homescript.ErrStorageForbidden):
+		w.WriteHeader(http.StatusForbidden)
+		Res(w, Response{Success: false, Message: message, Error: err.Error()})
+	case errors.Is(err, homescript.ErrStorageDatabase):
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: message, Error: "database failure"})
+	default:
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: message, Error: err.Error()})
+	}
+}
+
+// Returns the personal storage entries of the current user
+func ListPersonalStorageEntries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	entries, err := database.ListStorageEntries(username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list storage entries", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list storage entries", Error: "could not encode response"})
+	}
+}
+
+// Returns the storage entries which are shared by all users
+func ListGlobalStorageEntries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	entries, err := database.ListStorageEntries(database.GlobalStorageOwner)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list storage entries", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list storage entries", Error: "could not encode response"})
+	}
+}
+
+// Creates or overwrites a storage entry of the current user
+// Modifying the global storage requires the permission to manage Homescripts
+func SetStorageEntry(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request SetStorageEntryRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if err := homescript.CheckStorageModification(username, request.Global); err != nil {
+		writeStorageError(w, err, "failed to set storage entry")
+		return
+	}
+	if err := homescript.SetStorageValue(username, request.Global, request.Key, request.Value); err != nil {
+		writeStorageError(w, err, "failed to set storage entry")
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully set storage entry"})
+}
+
+// Deletes a storage entry of the current user
+// Modifying the global storage requires the permission to manage Homescripts
+func DeleteStorageEntry(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request DeleteStorageEntryRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if err := homescript.CheckStorageModification(username, request.Global); err != nil {
+		writeStorageError(w, err, "failed to delete storage entry")
+		return
+	}
+	_, found, err := homescript.GetStorageValue(username, request.Global, request.Key)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete storage entry", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to delete storage entry", Error: "invalid key: no entry is associated to this key"})
+		return
+	}
+	if err := homescript.DeleteStorageValue(username, request.Global, request.Key); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete storage entry", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully deleted storage entry"})
+}
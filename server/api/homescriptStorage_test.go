@@ -0,0 +1,29 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/core/homescript"
+)
+
+func TestWriteStorageError(t *testing.T) {
+	table := []struct {
+		Err    error
+		Status int
+	}{
+		{Err: homescript.ErrStorageForbidden, Status: http.StatusForbidden},
+		{Err: fmt.Errorf("Failed to write storage: %w: connection refused", homescript.ErrStorageDatabase), Status: http.StatusServiceUnavailable},
+		{Err: errors.New("Storage values must not exceed 4096 characters"), Status: http.StatusUnprocessableEntity},
+	}
+	for _, test := range table {
+		recorder := httptest.NewRecorder()
+		writeStorageError(recorder, test.Err, "failed to set storage entry")
+		if recorder.Code != test.Status {
+			t.Errorf("Unexpected status for error '%s': want: %d got: %d", test.Err.Error(), test.Status, recorder.Code)
+		}
+	}
+}
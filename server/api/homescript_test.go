@@ -0,0 +1,55 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestOnlyCodeModified(t *testing.T) {
+	homescriptBefore := database.Homescript{
+		Id:                  "lights",
+		Owner:               "admin",
+		Name:                "Lights",
+		Description:         "All lights",
+		QuickActionsEnabled: true,
+		SchedulerEnabled:    false,
+		Code:                "switch('s1', on)",
+		RunRetention:        10,
+		QuickActionIcon:     "lamp",
+		QuickActionColor:    "#88FF70",
+	}
+	unchanged := database.HomescriptFrontend{
+		Name:                "Lights",
+		Description:         "All lights",
+		QuickActionsEnabled: true,
+		SchedulerEnabled:    false,
+		Code:                "switch('s1', off)",
+		RunRetention:        10,
+		QuickActionIcon:     "lamp",
+		QuickActionColor:    "#88FF70",
+	}
+	schedulerEnabled := unchanged
+	schedulerEnabled.SchedulerEnabled = true
+	quickActionDisabled := unchanged
+	quickActionDisabled.QuickActionsEnabled = false
+	retentionChanged := unchanged
+	retentionChanged.RunRetention = 0
+	renamed := unchanged
+	renamed.Name = "Other"
+	table := []struct {
+		Metadata database.HomescriptFrontend
+		Want     bool
+	}{
+		{Metadata: unchanged, Want: true},
+		{Metadata: schedulerEnabled, Want: false},
+		{Metadata: quickActionDisabled, Want: false},
+		{Metadata: retentionChanged, Want: false},
+		{Metadata: renamed, Want: false},
+	}
+	for _, test := range table {
+		if got := onlyCodeModified(homescriptBefore, test.Metadata); got != test.Want {
+			t.Errorf("Unexpected result for %v: want: %t got: %t", test.Metadata, test.Want, got)
+		}
+	}
+}
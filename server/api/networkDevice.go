@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/hardware"
+)
+
+type DeleteNetworkDeviceRequest struct {
+	SwitchId string `json:"switchId"`
+}
+
+// Returns a list of all switches which are configured as network devices
+func ListNetworkDevices(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	devices, err := database.ListNetworkDevices()
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list network devices", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(devices); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "could not encode response"})
+	}
+}
+
+// Configures an existing switch as a network device or updates its configuration
+func SetNetworkDevice(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request database.NetworkDevice
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if err := hardware.ValidateNetworkDevice(request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "invalid network device", Error: err.Error()})
+		return
+	}
+	_, switchExists, err := database.GetSwitchById(request.SwitchId)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to check existence of this switch", Error: "database error"})
+		return
+	}
+	if !switchExists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to set network device: invalid switch id", Error: "switch not found"})
+		return
+	}
+	if err := database.SetNetworkDevice(request); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to set network device", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: fmt.Sprintf("switch '%s' is now a network device", request.SwitchId)})
+}
+
+// Turns a network device back into a regular switch
+func DeleteNetworkDevice(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request DeleteNetworkDeviceRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	_, found, err := database.GetNetworkDeviceBySwitchId(request.SwitchId)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete network device", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to delete network device", Error: "switch is not a network device"})
+		return
+	}
+	if err := database.DeleteNetworkDevice(request.SwitchId); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete network device", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully deleted network device"})
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -16,6 +17,16 @@ type PowerRequest struct {
 	PowerOn bool   `json:"powerOn"`
 }
 
+type RoomPowerOffRequest struct {
+	RoomId string `json:"roomId"`
+}
+
+type PowerActionResponse struct {
+	Success bool                         `json:"success"`
+	Message string                       `json:"message"`
+	Results []hardware.PowerActionResult `json:"results"`
+}
+
 // API endpoint for manipulating power states and (de) activating sockets, authentication required
 // Permission and switch permission is needed to interact with this endpoint
 func PowerPostHandler(w http.ResponseWriter, r *http.Request) {
@@ -54,6 +65,9 @@ func PowerPostHandler(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "permission denied", Error: "missing permission to interact with this switch, contact your administrator"})
 		return
 	}
+	if request.PowerOn && !checkPowerOn(w, request.Switch) {
+		return
+	}
 	if err := hardware.SetPower(request.Switch, request.PowerOn); err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		Res(w, Response{Success: false, Message: "hardware error", Error: "failed to communicate with hardware"})
@@ -68,6 +82,91 @@ func PowerPostHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Checks if the switch may be turned on, for example if it is not limited by an exceeded energy budget
+// Sends an error response if the switch must not be turned on
+func checkPowerOn(w http.ResponseWriter, switchId string) bool {
+	if err := hardware.CheckPowerOn(switchId); err != nil {
+		if errors.Is(err, hardware.ErrPowerOnRejected) {
+			w.WriteHeader(http.StatusConflict)
+			Res(w, Response{Success: false, Message: "failed to set power", Error: err.Error()})
+			return false
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to check if the switch may be turned on", Error: "database error"})
+		return false
+	}
+	return true
+}
+
+// Turns off every switch of a room which the user is allowed to use, switches marked as `keepOn` are skipped
+// Responds with a result for each switch of the room
+func PowerOffRoomHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request RoomPowerOffRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	results, roomExists, err := hardware.PowerOffRoom(request.RoomId, username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to turn off room", Error: "database error"})
+		return
+	}
+	if !roomExists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to turn off room: invalid room id", Error: "room not found"})
+		return
+	}
+	go event.Info("User Deactivated Room", fmt.Sprintf("%s turned off room %s", username, request.RoomId))
+	sendPowerActionResults(w, results)
+}
+
+// Turns off every switch in the house which the user is allowed to use, switches marked as `keepOn` are skipped
+// Responds with a result for each switch
+func PowerOffAllHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	results, err := hardware.PowerOffAll(username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to turn off all switches", Error: "database error"})
+		return
+	}
+	go event.Info("User Deactivated All Switches", fmt.Sprintf("%s turned off all switches", username))
+	sendPowerActionResults(w, results)
+}
+
+// Sends the per-switch report of a power action which addressed multiple switches
+// If at least one switch could not be addressed, the response indicates a hardware error
+func sendPowerActionResults(w http.ResponseWriter, results []hardware.PowerActionResult) {
+	response := PowerActionResponse{Success: true, Message: "power action successful", Results: results}
+	for _, result := range results {
+		if !result.Success {
+			response.Success = false
+			response.Message = "hardware error: some switches could not be turned off"
+			go event.Warn("Hardware Error", fmt.Sprintf("The hardware failed while turning off switch %s: %s", result.Switch, result.Message))
+		}
+	}
+	if !response.Success {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to send power action results", Error: "could not encode content"})
+	}
+}
+
 // Returns a list of power states, no authentication required
 // Request: empty | Response: `[{"switchId": "x", power: false}, {...}]`
 func GetPowerStates(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+	"github.com/MikMuellerDev/smarthome/core/hardware"
+	"github.com/MikMuellerDev/smarthome/core/scheduler/timer"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+)
+
+// Timers which lie further in the future are rejected
+const maxTimerSeconds = 7 * 24 * 60 * 60
+
+type TimedPowerRequest struct {
+	Switch   string `json:"switch"`
+	PowerOn  bool   `json:"powerOn"`
+	Delay    uint   `json:"delay"`    // Seconds until the power action is performed, 0 performs it immediately
+	Duration uint   `json:"duration"` // Seconds after which the power action is reversed, 0 does not reverse it
+}
+
+type TimedPowerResponse struct {
+	Success bool                  `json:"success"`
+	Message string                `json:"message"`
+	Timers  []database.PowerTimer `json:"timers"`
+}
+
+type CancelPowerTimerRequest struct {
+	Id uint `json:"id"`
+}
+
+// Performs a power action after a delay and / or reverses it after a given duration
+// For example: turn the fan on now and turn it off again after 20 minutes
+// Permission and switch permission is needed to interact with this endpoint
+func TimedPowerHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request TimedPowerRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	if request.Delay == 0 && request.Duration == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "invalid timer", Error: "either a delay or a duration is required, use the regular power endpoint otherwise"})
+		return
+	}
+	// Both values are checked on their own first so that their sum cannot overflow
+	if request.Delay > maxTimerSeconds || request.Duration > maxTimerSeconds || request.Delay+request.Duration > maxTimerSeconds {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "invalid timer", Error: "delay and duration must not exceed one week"})
+		return
+	}
+	_, switchExists, err := database.GetSwitchById(request.Switch)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to check existence of this switch", Error: "database error"})
+		return
+	}
+	if !switchExists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to set power: invalid switch id", Error: "switch not found"})
+		return
+	}
+	userHasPermission, err := database.UserHasSwitchPermission(username, request.Switch)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to check permission for this switch", Error: "database error"})
+		return
+	}
+	if !userHasPermission {
+		w.WriteHeader(http.StatusForbidden)
+		Res(w, Response{Success: false, Message: "permission denied", Error: "missing permission to interact with this switch, contact your administrator"})
+		return
+	}
+	now := time.Now()
+	timers := make([]database.PowerTimer, 0)
+	if request.Delay == 0 {
+		if request.PowerOn && !checkPowerOn(w, request.Switch) {
+			return
+		}
+		if err := hardware.SetPower(request.Switch, request.PowerOn); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: "hardware error", Error: "failed to communicate with hardware"})
+			go event.Warn("Hardware Error", fmt.Sprintf("The hardware failed while %s tried to interact with switch %s.", username, request.Switch))
+			return
+		}
+	} else {
+		delayedTimer, err := timer.CreatePowerTimer(username, request.Switch, request.PowerOn, now.Add(time.Duration(request.Delay)*time.Second))
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: "failed to create timer", Error: "database failure"})
+			return
+		}
+		timers = append(timers, delayedTimer)
+	}
+	if request.Duration > 0 {
+		reverseTimer, err := timer.CreatePowerTimer(username, request.Switch, !request.PowerOn, now.Add(time.Duration(request.Delay+request.Duration)*time.Second))
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			Res(w, Response{Success: false, Message: "failed to create timer", Error: "database failure"})
+			return
+		}
+		timers = append(timers, reverseTimer)
+	}
+	if err := json.NewEncoder(w).Encode(TimedPowerResponse{Success: true, Message: "timed power action successful", Timers: timers}); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "could not encode response"})
+	}
+	go event.Info("User Created Power Timer", fmt.Sprintf("%s created %d timer(s) for switch %s", username, len(timers), request.Switch))
+}
+
+// Returns a list of the pending power timers of the current user
+func GetUserPowerTimers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	timers, err := database.GetUserPowerTimers(username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list power timers", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(timers); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "could not encode response"})
+	}
+}
+
+// Cancels a pending power timer of the current user
+func CancelPowerTimer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request CancelPowerTimerRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	found, err := timer.CancelUserPowerTimer(username, request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to cancel power timer", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to cancel power timer", Error: "invalid id: no power timer with this id exists"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully cancelled power timer"})
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/homescript"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+)
+
+// Quick action colors are stored as hex codes, for example `#88FF70`
+var quickActionColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// A Homescript which is shown on the quick actions dashboard, the code is omitted
+type QuickAction struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Icon        string `json:"icon"`
+	Color       string `json:"color"`
+}
+
+// Validates the optional quick action metadata of a Homescript, sends an error response if it is invalid
+func validateQuickActionMetadata(w http.ResponseWriter, icon string, color string) bool {
+	if len(icon) > 50 {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "maximum quick action icon length of 50 chars. was exceeded"})
+		return false
+	}
+	if color != "" && !quickActionColorPattern.MatchString(color) {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "quick action color must be a hex color like `#88FF70`"})
+		return false
+	}
+	return true
+}
+
+// Converts the Homescripts which are enabled as quick actions, the others are skipped
+func listQuickActions(homescriptList []database.Homescript) []QuickAction {
+	quickActions := make([]QuickAction, 0)
+	for _, homescriptItem := range homescriptList {
+		if !homescriptItem.QuickActionsEnabled {
+			continue
+		}
+		quickActions = append(quickActions, QuickAction{
+			Id:          homescriptItem.Id,
+			Name:        homescriptItem.Name,
+			Description: homescriptItem.Description,
+			Icon:        homescriptItem.QuickActionIcon,
+			Color:       homescriptItem.QuickActionColor,
+		})
+	}
+	return quickActions
+}
+
+// Returns the Homescripts of the current user which are enabled as quick actions
+func ListPersonalQuickActions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	homescriptList, err := database.ListHomescriptOfUser(username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list personal quick actions", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(listQuickActions(homescriptList)); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list personal quick actions", Error: "could not encode response"})
+	}
+}
+
+// Runs a Homescript of the current user which is enabled as a quick action
+func RunQuickAction(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request HomescriptIdRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	homescriptItem, found, err := database.GetUserHomescriptById(request.Id, username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to run quick action", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to run quick action", Error: "not found / permission denied: no data is associated to this id"})
+		return
+	}
+	if !homescriptItem.QuickActionsEnabled {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to run quick action", Error: "homescript is not enabled as a quick action"})
+		return
+	}
+	output, exitCode, hmsErrors := homescript.RunSaved(username, homescriptItem, homescript.TriggerQuickAction, nil)
+	sendHomescriptResult(w, output, exitCode, hmsErrors)
+}
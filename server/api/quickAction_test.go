@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestListQuickActions(t *testing.T) {
+	quickActions := listQuickActions([]database.Homescript{
+		{Id: "lights", Name: "Lights", Description: "All lights", QuickActionsEnabled: true, QuickActionIcon: "lamp", QuickActionColor: "#88FF70", Code: "switch('s1', on)"},
+		{Id: "backup", Name: "Backup", QuickActionsEnabled: false},
+	})
+	// Scripts which are not enabled as quick actions are omitted
+	if len(quickActions) != 1 {
+		t.Errorf("Unexpected amount of quick actions: want: 1 got: %d", len(quickActions))
+		return
+	}
+	want := QuickAction{Id: "lights", Name: "Lights", Description: "All lights", Icon: "lamp", Color: "#88FF70"}
+	if quickActions[0] != want {
+		t.Errorf("Unexpected quick action: want: %v got: %v", want, quickActions[0])
+	}
+}
+
+func TestValidateQuickActionMetadata(t *testing.T) {
+	table := []struct {
+		Icon  string
+		Color string
+		Valid bool
+	}{
+		{Icon: "", Color: "", Valid: true},
+		{Icon: "lamp", Color: "#88FF70", Valid: true},
+		{Icon: "lamp", Color: "#88ff70", Valid: true},
+		{Icon: strings.Repeat("a", 50), Color: "", Valid: true},
+		{Icon: strings.Repeat("a", 51), Color: "", Valid: false},
+		{Icon: "", Color: "88FF70", Valid: false},
+		{Icon: "", Color: "#88FF7", Valid: false},
+		{Icon: "", Color: "#88FF70 ", Valid: false},
+		{Icon: "", Color: "red", Valid: false},
+	}
+	for _, test := range table {
+		recorder := httptest.NewRecorder()
+		valid := validateQuickActionMetadata(recorder, test.Icon, test.Color)
+		if valid != test.Valid {
+			t.Errorf("Icon '%s' and color '%s': want valid: %t got: %t", test.Icon, test.Color, test.Valid, valid)
+			continue
+		}
+		if !valid && recorder.Code != http.StatusBadRequest {
+			t.Errorf("Icon '%s' and color '%s': unexpected status code: want: %d got: %d", test.Icon, test.Color, http.StatusBadRequest, recorder.Code)
+		}
+	}
+}
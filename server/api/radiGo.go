@@ -0,0 +1,272 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/exp/utf8string"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+	"github.com/MikMuellerDev/smarthome/services/radigo"
+)
+
+type AddRadiGoServerRequest struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Url   string `json:"url"`
+	Token string `json:"token"`
+}
+
+type DeleteRadiGoServerRequest struct {
+	Id string `json:"id"`
+}
+
+type PlayRadiGoRequest struct {
+	Server string `json:"server"`
+	Mode   string `json:"mode"`
+}
+
+type UserRadiGoPermissionRequest struct {
+	Username string `json:"username"`
+	Server   string `json:"server"`
+}
+
+// Returns all radiGo servers, admin authentication is required
+func ListAllRadiGoServers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	servers, err := database.ListRadiGoServers()
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list radiGo servers", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(servers); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list radiGo servers", Error: "could not encode response"})
+	}
+}
+
+// Only returns the radiGo servers which the current user is allowed to use
+func ListPersonalRadiGoServers(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	servers, err := database.ListUserRadiGoServers(username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list personal radiGo servers", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(servers); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list personal radiGo servers", Error: "could not encode response"})
+	}
+}
+
+// Creates a new radiGo server or updates an existing one, admin authentication is required
+func AddRadiGoServer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request AddRadiGoServerRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if request.Id == "" || strings.Contains(request.Id, " ") || !utf8string.NewString(request.Id).IsASCII() {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "id should only include ASCII characters and must not have whitespaces or be blank"})
+		return
+	}
+	if len(request.Id) > 50 || len(request.Name) > 50 {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "maximum lengths for id and name are 50"})
+		return
+	}
+	serverUrl, err := url.Parse(request.Url)
+	if err != nil || (serverUrl.Scheme != "http" && serverUrl.Scheme != "https") || serverUrl.Host == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "url must be an absolute http or https url"})
+		return
+	}
+	if err := database.CreateRadiGoServer(database.RadiGoServer{
+		Id:    request.Id,
+		Name:  request.Name,
+		Url:   strings.TrimSuffix(request.Url, "/"),
+		Token: request.Token,
+	}); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to add radiGo server", Error: "database failure"})
+		return
+	}
+	go event.Info("Added radiGo Server", fmt.Sprintf("Added radiGo server '%s'", request.Id))
+	Res(w, Response{Success: true, Message: "successfully added radiGo server"})
+}
+
+// Deletes a radiGo server and all permissions which refer to it, admin authentication is required
+func DeleteRadiGoServer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request DeleteRadiGoServerRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	_, found, err := database.GetRadiGoServerById(request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete radiGo server", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to delete radiGo server", Error: "invalid id: no server is associated to this id"})
+		return
+	}
+	if err := database.DeleteRadiGoServer(request.Id); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete radiGo server", Error: "database failure"})
+		return
+	}
+	go event.Info("Deleted radiGo Server", fmt.Sprintf("Deleted radiGo server '%s'", request.Id))
+	Res(w, Response{Success: true, Message: "successfully deleted radiGo server"})
+}
+
+// Starts a mode on a radiGo server, the user must be allowed to use the server
+func PlayRadiGoMode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request PlayRadiGoRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if _, err := radigo.GetUserServer(username, request.Server); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		Res(w, Response{Success: false, Message: "failed to play mode", Error: err.Error()})
+		return
+	}
+	if err := radigo.Play(username, request.Server, request.Mode); err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		Res(w, Response{Success: false, Message: "failed to play mode", Error: err.Error()})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully started mode"})
+}
+
+// Returns the current status of a radiGo server, the user must be allowed to use the server
+// The server is selected using the `id` query parameter
+func GetRadiGoStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	serverId := r.URL.Query().Get("id")
+	if _, err := radigo.GetUserServer(username, serverId); err != nil {
+		w.WriteHeader(http.StatusForbidden)
+		Res(w, Response{Success: false, Message: "failed to get radiGo status", Error: err.Error()})
+		return
+	}
+	status, err := radigo.GetStatus(username, serverId)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		Res(w, Response{Success: false, Message: "failed to get radiGo status", Error: err.Error()})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to get radiGo status", Error: "could not encode response"})
+	}
+}
+
+// Validates that both the server and the user of a permission request exist, sends an error response if they do not
+func validateRadiGoPermissionRequest(w http.ResponseWriter, request UserRadiGoPermissionRequest, message string) bool {
+	_, serverExists, err := database.GetRadiGoServerById(request.Server)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: message, Error: "database failure"})
+		return false
+	}
+	if !serverExists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: message, Error: "invalid server id"})
+		return false
+	}
+	_, userExists, err := database.GetUserByUsername(request.Username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: message, Error: "database failure"})
+		return false
+	}
+	if !userExists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: message, Error: "invalid user"})
+		return false
+	}
+	return true
+}
+
+// Allows a user to use a radiGo server, admin authentication required
+// Request: `{"username": "x", "server": "y"}` | Response: Response
+func AddRadiGoPermission(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request UserRadiGoPermissionRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if !validateRadiGoPermissionRequest(w, request, "failed to add radiGo permission") {
+		return
+	}
+	if err := database.AddUserRadiGoPermission(request.Username, request.Server); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to add radiGo permission", Error: "database failure"})
+		return
+	}
+	go event.Info("Added radiGo Permission", fmt.Sprintf("Added radiGo permission %s to user %s.", request.Server, request.Username))
+	Res(w, Response{Success: true, Message: "successfully added radiGo permission to user"})
+}
+
+// Removes a radiGo permission from a user, admin authentication required
+// Request: `{"username": "x", "server": "y"}` | Response: Response
+func RemoveRadiGoPermission(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request UserRadiGoPermissionRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if !validateRadiGoPermissionRequest(w, request, "failed to remove radiGo permission") {
+		return
+	}
+	if err := database.RemoveUserRadiGoPermission(request.Username, request.Server); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to remove radiGo permission", Error: "database failure"})
+		return
+	}
+	go event.Info("Removed radiGo Permission", fmt.Sprintf("Removed radiGo permission %s from user %s.", request.Server, request.Username))
+	Res(w, Response{Success: true, Message: "successfully removed radiGo permission from user"})
+}
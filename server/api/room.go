@@ -34,6 +34,10 @@ func ListUserRoomsWithSwitches(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "could not list personal rooms", Error: "database failure"})
 		return
 	}
+	// Apply the optional category filter to the switches of each room
+	for index := range rooms {
+		rooms[index].Switches = filterSwitchesByCategory(rooms[index].Switches, r)
+	}
 	if err := json.NewEncoder(w).Encode(rooms); err != nil {
 		log.Error(err.Error())
 		Res(w, Response{Success: false, Message: "could not list personal rooms", Error: "could not encode content"})
@@ -49,6 +53,10 @@ func ListAllRoomsWithSwitches(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "could not list all rooms", Error: "database failure"})
 		return
 	}
+	// Apply the optional category filter to the switches of each room
+	for index := range rooms {
+		rooms[index].Switches = filterSwitchesByCategory(rooms[index].Switches, r)
+	}
 	if err := json.NewEncoder(w).Encode(rooms); err != nil {
 		log.Error(err.Error())
 		Res(w, Response{Success: false, Message: "could not list all rooms", Error: "could not encode content"})
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Writes server-sent events to a client
+// Sending is safe from multiple goroutines and becomes a no-op once the stream has been closed
+type eventStream struct {
+	m       sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+	closed  bool
+}
+
+// Prepares the response for server-sent events, sends an error response if the connection does not support streaming
+func newEventStream(w http.ResponseWriter) (*eventStream, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "streaming is not supported", Error: "the connection does not support streaming"})
+		return nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &eventStream{w: w, flusher: flusher}, true
+}
+
+// Sends an event with the given name and JSON-encoded data to the client
+func (stream *eventStream) send(event string, data interface{}) {
+	stream.m.Lock()
+	defer stream.m.Unlock()
+	if stream.closed {
+		return
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		log.Error("Failed to send server-sent event: could not encode data: ", err.Error())
+		return
+	}
+	if _, err := fmt.Fprintf(stream.w, "event: %s\ndata: %s\n\n", event, encoded); err != nil {
+		log.Debug("Failed to send server-sent event: ", err.Error())
+		return
+	}
+	stream.flusher.Flush()
+}
+
+// Sends a comment which is ignored by the client but keeps the connection open
+func (stream *eventStream) keepAlive() {
+	stream.m.Lock()
+	defer stream.m.Unlock()
+	if stream.closed {
+		return
+	}
+	if _, err := fmt.Fprint(stream.w, ": keep-alive\n\n"); err != nil {
+		log.Debug("Failed to send keep-alive comment: ", err.Error())
+		return
+	}
+	stream.flusher.Flush()
+}
+
+// Prevents further events from being written, must be called before the handler returns
+func (stream *eventStream) close() {
+	stream.m.Lock()
+	defer stream.m.Unlock()
+	stream.closed = true
+}
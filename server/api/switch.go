@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -12,22 +13,70 @@ import (
 )
 
 type AddSwitchRequest struct {
-	Id     string `json:"id"`
-	Name   string `json:"name"`
-	RoomId string `json:"roomId"`
-	Watts  uint16 `json:"watts"`
+	Id        string                  `json:"id"`
+	Name      string                  `json:"name"`
+	RoomId    string                  `json:"roomId"`
+	Watts     uint16                  `json:"watts"`
+	KeepOn    bool                    `json:"keepOn"`
+	Category  database.SwitchCategory `json:"category"`
+	Icon      string                  `json:"icon"`
+	SortIndex int                     `json:"sortIndex"`
+	Hidden    bool                    `json:"hidden"`
 }
 
 type ModifySwitchRequest struct {
-	Id    string `json:"id"`
-	Name  string `json:"name"`
-	Watts uint16 `json:"watts"`
+	Id        string                  `json:"id"`
+	Name      string                  `json:"name"`
+	Watts     uint16                  `json:"watts"`
+	KeepOn    bool                    `json:"keepOn"`
+	Category  database.SwitchCategory `json:"category"`
+	Icon      string                  `json:"icon"`
+	SortIndex int                     `json:"sortIndex"`
+	Hidden    bool                    `json:"hidden"`
 }
 
 type DeleteSwitchRequest struct {
 	Id string `json:"id"`
 }
 
+// Only keeps the switches which match the optional `category` query parameter
+// Multiple categories can be separated by commas, for example `?category=light,fan`
+func filterSwitchesByCategory(switches []database.Switch, r *http.Request) []database.Switch {
+	categoryQuery := r.URL.Query().Get("category")
+	if categoryQuery == "" {
+		return switches
+	}
+	categories := strings.Split(categoryQuery, ",")
+	filtered := make([]database.Switch, 0)
+	for _, switchItem := range switches {
+		for _, category := range categories {
+			if string(switchItem.Category) == category {
+				filtered = append(filtered, switchItem)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// Validates the display metadata of a switch and applies the default category, sends an error response if it is invalid
+func validateSwitchMetadata(w http.ResponseWriter, category *database.SwitchCategory, icon string) bool {
+	if *category == "" {
+		*category = database.SwitchCategoryOther
+	}
+	if !database.IsValidSwitchCategory(*category) {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: fmt.Sprintf("invalid category '%s'", *category)})
+		return false
+	}
+	if len(icon) > 50 {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "maximum icon length of 50 chars. was exceeded"})
+		return false
+	}
+	return true
+}
+
 // Returns a list of available switches as JSON to the user, no authentication required
 func GetAllSwitches(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -37,7 +86,7 @@ func GetAllSwitches(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "databas", Error: "database failure"})
 		return
 	}
-	if err := json.NewEncoder(w).Encode(switches); err != nil {
+	if err := json.NewEncoder(w).Encode(filterSwitchesByCategory(switches, r)); err != nil {
 		log.Error(err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
 		Res(w, Response{Success: false, Message: "failed to get switches", Error: "could not encode content"})
@@ -58,7 +107,7 @@ func GetUserSwitches(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "database error", Error: "database error"})
 		return
 	}
-	if err := json.NewEncoder(w).Encode(switches); err != nil {
+	if err := json.NewEncoder(w).Encode(filterSwitchesByCategory(switches, r)); err != nil {
 		log.Error(err.Error())
 		Res(w, Response{Success: false, Message: "failed to get personal switches", Error: "could not encode content"})
 	}
@@ -86,6 +135,9 @@ func CreateSwitch(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "bad request", Error: "maximum lengths for id and name are 20 and 30"})
 		return
 	}
+	if !validateSwitchMetadata(w, &request.Category, request.Icon) {
+		return
+	}
 	// Validate that no conflicts are present
 	_, alreadyExists, err := database.GetSwitchById(request.Id)
 	if err != nil {
@@ -110,12 +162,17 @@ func CreateSwitch(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to create switch", Error: "invalid room id"})
 		return
 	}
-	if err := database.CreateSwitch(
-		request.Id,
-		request.Name,
-		request.RoomId,
-		request.Watts,
-	); err != nil {
+	if err := database.CreateSwitch(database.Switch{
+		Id:        request.Id,
+		Name:      request.Name,
+		RoomId:    request.RoomId,
+		Watts:     request.Watts,
+		KeepOn:    request.KeepOn,
+		Category:  request.Category,
+		Icon:      request.Icon,
+		SortIndex: request.SortIndex,
+		Hidden:    request.Hidden,
+	}); err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		Res(w, Response{Success: false, Message: "failed to create switch", Error: "database failure"})
 		return
@@ -144,17 +201,35 @@ func ModifySwitch(w http.ResponseWriter, r *http.Request) {
 		Res(w, Response{Success: false, Message: "failed to modify switch", Error: "no switch with id exists"})
 		return
 	}
-	if switchItem.Name == request.Name && switchItem.Watts == request.Watts {
-		Res(w, Response{Success: true, Message: "properties unchanged"})
-		return
-	}
 	// Validate length
 	if len(request.Name) > 30 {
 		w.WriteHeader(http.StatusBadRequest)
 		Res(w, Response{Success: false, Message: "bad request", Error: "maximum name length of 30 chars. was exceeded"})
 		return
 	}
-	if err := database.ModifySwitch(request.Id, request.Name, request.Watts); err != nil {
+	if !validateSwitchMetadata(w, &request.Category, request.Icon) {
+		return
+	}
+	newItem := database.Switch{
+		Name:      request.Name,
+		Watts:     request.Watts,
+		KeepOn:    request.KeepOn,
+		Category:  request.Category,
+		Icon:      request.Icon,
+		SortIndex: request.SortIndex,
+		Hidden:    request.Hidden,
+	}
+	if switchItem.Name == newItem.Name &&
+		switchItem.Watts == newItem.Watts &&
+		switchItem.KeepOn == newItem.KeepOn &&
+		switchItem.Category == newItem.Category &&
+		switchItem.Icon == newItem.Icon &&
+		switchItem.SortIndex == newItem.SortIndex &&
+		switchItem.Hidden == newItem.Hidden {
+		Res(w, Response{Success: true, Message: "properties unchanged"})
+		return
+	}
+	if err := database.ModifySwitch(request.Id, newItem); err != nil {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		Res(w, Response{Success: false, Message: "failed to modify switch", Error: "database failure"})
 		return
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestFilterSwitchesByCategory(t *testing.T) {
+	switches := []database.Switch{
+		{Id: "s1", Category: database.SwitchCategoryLight},
+		{Id: "s2", Category: database.SwitchCategoryOther},
+		{Id: "s3", Category: database.SwitchCategoryLight},
+	}
+	table := []struct {
+		Query    string
+		Expected []string
+	}{
+		// Without a category, every switch is returned
+		{Query: "", Expected: []string{"s1", "s2", "s3"}},
+		{Query: "?category=light", Expected: []string{"s1", "s3"}},
+		{Query: "?category=light,other", Expected: []string{"s1", "s2", "s3"}},
+		{Query: "?category=fan", Expected: []string{}},
+	}
+	for _, test := range table {
+		request := httptest.NewRequest("GET", "/api/switch/list/personal"+test.Query, nil)
+		filtered := filterSwitchesByCategory(switches, request)
+		if len(filtered) != len(test.Expected) {
+			t.Errorf("Query '%s': unexpected amount of switches: want: %d got: %d", test.Query, len(test.Expected), len(filtered))
+			continue
+		}
+		for index, switchItem := range filtered {
+			if switchItem.Id != test.Expected[index] {
+				t.Errorf("Query '%s': unexpected switch at index %d: want: %s got: %s", test.Query, index, test.Expected[index], switchItem.Id)
+			}
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"golang.org/x/exp/utf8string"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+type AddUserGroupRequest struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type DeleteUserGroupRequest struct {
+	Id string `json:"id"`
+}
+
+type UserGroupMemberRequest struct {
+	GroupId  string `json:"groupId"`
+	Username string `json:"username"`
+}
+
+// Returns all user groups and their members, is used to select a group when sharing resources
+func ListUserGroups(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	groups, err := database.ListUserGroups()
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list user groups", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(groups); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list user groups", Error: "could not encode response"})
+	}
+}
+
+// Creates a new user group without members, admin auth required
+func AddUserGroup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request AddUserGroupRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if request.Id == "" || strings.Contains(request.Id, " ") || !utf8string.NewString(request.Id).IsASCII() {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "id should only include ASCII characters and must not have whitespaces or be blank"})
+		return
+	}
+	if len(request.Id) > 30 || len(request.Name) > 50 {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "maximum lengths for id and name are 30 and 50"})
+		return
+	}
+	_, alreadyExists, err := database.GetUserGroupById(request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to create user group", Error: "database failure"})
+		return
+	}
+	if alreadyExists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to create user group", Error: "a group with the same id already exists"})
+		return
+	}
+	if err := database.CreateUserGroup(database.UserGroup{
+		Id:          request.Id,
+		Name:        request.Name,
+		Description: request.Description,
+	}); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to create user group", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully created user group"})
+}
+
+// Deletes a user group and revokes everything which has been shared with it, admin auth required
+func DeleteUserGroup(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request DeleteUserGroupRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	_, exists, err := database.GetUserGroupById(request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete user group", Error: "database failure"})
+		return
+	}
+	if !exists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to delete user group", Error: "invalid group id"})
+		return
+	}
+	if err := database.DeleteUserGroup(request.Id); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete user group", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully deleted user group"})
+}
+
+// Validates that both the group and the user of a membership request exist, sends an error response if they do not
+func validateUserGroupMemberRequest(w http.ResponseWriter, request UserGroupMemberRequest, message string) bool {
+	_, groupExists, err := database.GetUserGroupById(request.GroupId)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: message, Error: "database failure"})
+		return false
+	}
+	if !groupExists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: message, Error: "invalid group id"})
+		return false
+	}
+	_, userExists, err := database.GetUserByUsername(request.Username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: message, Error: "database failure"})
+		return false
+	}
+	if !userExists {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: message, Error: "invalid username"})
+		return false
+	}
+	return true
+}
+
+// Adds a user to a group, admin auth required
+func AddUserGroupMember(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request UserGroupMemberRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if !validateUserGroupMemberRequest(w, request, "failed to add user to group") {
+		return
+	}
+	if err := database.AddUserGroupMember(request.GroupId, request.Username); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to add user to group", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully added user to group"})
+}
+
+// Removes a user from a group, admin auth required
+func RemoveUserGroupMember(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request UserGroupMemberRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if !validateUserGroupMemberRequest(w, request, "failed to remove user from group") {
+		return
+	}
+	if err := database.RemoveUserGroupMember(request.GroupId, request.Username); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to remove user from group", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully removed user from group"})
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/MikMuellerDev/smarthome/services/weather"
+)
+
+// Returns the current weather and a short-term forecast at the location of the server
+func GetWeather(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	data, err := weather.GetWeather()
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to get weather", Error: err.Error()})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to get weather", Error: "could not encode response"})
+	}
+}
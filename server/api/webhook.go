@@ -0,0 +1,259 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+	"github.com/MikMuellerDev/smarthome/core/homescript"
+	"github.com/MikMuellerDev/smarthome/server/middleware"
+)
+
+// Limits of webhooks
+const (
+	defaultWebhookRateLimit = 10
+	maxWebhookRateLimit     = 600
+	maxWebhookBodySize      = 64 * 1024
+)
+
+// Header which can contain the token of a webhook instead of the url path
+const webhookTokenHeader = "X-Webhook-Token"
+
+type AddWebhookRequest struct {
+	Name         string `json:"name"`
+	HomescriptId string `json:"homescriptId"`
+	RateLimit    uint   `json:"rateLimit"` // Calls per minute, 0 uses the default
+}
+
+type ModifyWebhookRequest struct {
+	Id        uint   `json:"id"`
+	Name      string `json:"name"`
+	Enabled   bool   `json:"enabled"`
+	RateLimit uint   `json:"rateLimit"`
+}
+
+type DeleteWebhookRequest struct {
+	Id uint `json:"id"`
+}
+
+// The token is only returned once, after the webhook has been created
+type AddWebhookResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	Id      uint   `json:"id"`
+	Token   string `json:"token"`
+	Url     string `json:"url"`
+}
+
+// Validates the name and rate limit of a webhook and applies the default rate limit, sends an error response if they are invalid
+func validateWebhook(w http.ResponseWriter, name string, rateLimit *uint) bool {
+	if len(name) > 30 {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "maximum name length of 30 chars. was exceeded"})
+		return false
+	}
+	if *rateLimit == 0 {
+		*rateLimit = defaultWebhookRateLimit
+	}
+	if *rateLimit > maxWebhookRateLimit {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: fmt.Sprintf("rate limit must not exceed %d calls per minute", maxWebhookRateLimit)})
+		return false
+	}
+	return true
+}
+
+// Returns the webhooks of the current user, tokens are not included
+func ListPersonalWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	webhooks, err := database.GetUserWebhooks(username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to list personal webhooks", Error: "database failure"})
+		return
+	}
+	if err := json.NewEncoder(w).Encode(webhooks); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to list personal webhooks", Error: "could not encode response"})
+	}
+}
+
+// Creates a new webhook for a Homescript of the current user and returns its secret token
+func AddWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request AddWebhookRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if !validateWebhook(w, request.Name, &request.RateLimit) {
+		return
+	}
+	_, found, err := database.GetUserHomescriptById(request.HomescriptId, username)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to add webhook", Error: "database failure"})
+		return
+	}
+	if !found {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to add webhook", Error: "homescript id is invalid or not found"})
+		return
+	}
+	token, tokenHash, err := homescript.GenerateWebhookToken()
+	if err != nil {
+		log.Error("Failed to generate webhook token: ", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		Res(w, Response{Success: false, Message: "failed to add webhook", Error: "could not generate token"})
+		return
+	}
+	id, err := database.CreateWebhook(database.Webhook{
+		Name:         request.Name,
+		Owner:        username,
+		HomescriptId: request.HomescriptId,
+		TokenHash:    tokenHash,
+		Enabled:      true,
+		RateLimit:    request.RateLimit,
+	})
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to add webhook", Error: "database failure"})
+		return
+	}
+	go event.Info("Webhook Created", fmt.Sprintf("User '%s' created webhook '%s' for Homescript '%s'", username, request.Name, request.HomescriptId))
+	if err := json.NewEncoder(w).Encode(AddWebhookResponse{
+		Success: true,
+		Message: "successfully added webhook, the token will not be shown again",
+		Id:      id,
+		Token:   token,
+		Url:     fmt.Sprintf("/api/webhook/trigger/%s", token),
+	}); err != nil {
+		log.Error(err.Error())
+		Res(w, Response{Success: false, Message: "failed to add webhook", Error: "could not encode response"})
+	}
+}
+
+// Changes the name, activation and rate limit of a webhook of the current user
+func ModifyWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request ModifyWebhookRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	if !validateWebhook(w, request.Name, &request.RateLimit) {
+		return
+	}
+	webhook, found, err := database.GetWebhookById(request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to modify webhook", Error: "database failure"})
+		return
+	}
+	if !found || webhook.Owner != username {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to modify webhook", Error: "invalid id / not found"})
+		return
+	}
+	if err := database.ModifyWebhook(request.Id, request.Name, request.Enabled, request.RateLimit); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to modify webhook", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully modified webhook"})
+}
+
+// Deletes a webhook of the current user, its token becomes invalid immediately
+func DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	username, err := middleware.GetUserFromCurrentSession(w, r)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var request DeleteWebhookRequest
+	if err := decoder.Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		Res(w, Response{Success: false, Message: "bad request", Error: "invalid request body"})
+		return
+	}
+	webhook, found, err := database.GetWebhookById(request.Id)
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete webhook", Error: "database failure"})
+		return
+	}
+	if !found || webhook.Owner != username {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		Res(w, Response{Success: false, Message: "failed to delete webhook", Error: "invalid id / not found"})
+		return
+	}
+	if err := homescript.DeleteWebhook(request.Id); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to delete webhook", Error: "database failure"})
+		return
+	}
+	Res(w, Response{Success: true, Message: "successfully deleted webhook"})
+}
+
+// Runs the Homescript of a webhook, no session is required because the token authenticates the request
+// The token is read from the `X-Webhook-Token` header, which keeps it out of access logs, or from the url path
+// The request method, body and query parameters are passed to the script as arguments
+func TriggerWebhook(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	token := r.Header.Get(webhookTokenHeader)
+	if token == "" {
+		token = mux.Vars(r)["token"]
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		Res(w, Response{Success: false, Message: "failed to trigger webhook", Error: fmt.Sprintf("request body must not exceed %d bytes", maxWebhookBodySize)})
+		return
+	}
+	output, exitCode, hmsErrors, err := homescript.RunWebhook(token, r.Method, r.URL.Query(), string(body))
+	switch {
+	case err == nil:
+		sendHomescriptResult(w, output, exitCode, hmsErrors)
+	case errors.Is(err, homescript.ErrWebhookNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		Res(w, Response{Success: false, Message: "failed to trigger webhook", Error: "invalid token"})
+	case errors.Is(err, homescript.ErrWebhookDisabled):
+		w.WriteHeader(http.StatusForbidden)
+		Res(w, Response{Success: false, Message: "failed to trigger webhook", Error: err.Error()})
+	case errors.Is(err, homescript.ErrWebhookForbidden):
+		w.WriteHeader(http.StatusForbidden)
+		Res(w, Response{Success: false, Message: "failed to trigger webhook", Error: err.Error()})
+	case errors.Is(err, homescript.ErrWebhookRateLimited):
+		w.WriteHeader(http.StatusTooManyRequests)
+		Res(w, Response{Success: false, Message: "failed to trigger webhook", Error: err.Error()})
+	default:
+		w.WriteHeader(http.StatusServiceUnavailable)
+		Res(w, Response{Success: false, Message: "failed to trigger webhook", Error: "database failure"})
+	}
+}
@@ -53,9 +53,22 @@ func NewRouter() *mux.Router {
 	r.HandleFunc("/api/login", loginPostHandler).Methods("POST")
 
 	//// API ////
+	// Real-time events (switch states, node status, notifications and job status)
+	r.HandleFunc("/api/events", mdl.ApiAuth(api.SubscribeToEvents)).Methods("GET")
+
 	// Power
 	r.HandleFunc("/api/power/states", api.GetPowerStates).Methods("GET")
 	r.HandleFunc("/api/power/set", mdl.ApiAuth(mdl.Perm(api.PowerPostHandler, database.PermissionPower))).Methods("POST")
+	r.HandleFunc("/api/power/off/room", mdl.ApiAuth(mdl.Perm(api.PowerOffRoomHandler, database.PermissionPower))).Methods("POST")
+	r.HandleFunc("/api/power/off/all", mdl.ApiAuth(mdl.Perm(api.PowerOffAllHandler, database.PermissionPower))).Methods("POST")
+	r.HandleFunc("/api/energy/budget/status/personal", mdl.ApiAuth(mdl.Perm(api.GetUserEnergyBudgetStatus, database.PermissionPower))).Methods("GET")
+	r.HandleFunc("/api/energy/budget/status/all", mdl.ApiAuth(mdl.Perm(api.GetAllEnergyBudgetStatus, database.PermissionManageEnergy))).Methods("GET")
+	r.HandleFunc("/api/energy/budget/add", mdl.ApiAuth(mdl.Perm(api.AddEnergyBudget, database.PermissionManageEnergy))).Methods("POST")
+	r.HandleFunc("/api/energy/budget/modify", mdl.ApiAuth(mdl.Perm(api.ModifyEnergyBudget, database.PermissionManageEnergy))).Methods("PUT")
+	r.HandleFunc("/api/energy/budget/delete", mdl.ApiAuth(mdl.Perm(api.DeleteEnergyBudget, database.PermissionManageEnergy))).Methods("DELETE")
+	r.HandleFunc("/api/power/timed", mdl.ApiAuth(mdl.Perm(api.TimedPowerHandler, database.PermissionPower))).Methods("POST")
+	r.HandleFunc("/api/power/timer/list/personal", mdl.ApiAuth(mdl.Perm(api.GetUserPowerTimers, database.PermissionPower))).Methods("GET")
+	r.HandleFunc("/api/power/timer/delete", mdl.ApiAuth(mdl.Perm(api.CancelPowerTimer, database.PermissionPower))).Methods("DELETE")
 
 	// Rooms
 	r.HandleFunc("/api/room/list/all", api.ListAllRoomsWithSwitches).Methods("GET")
@@ -70,6 +83,9 @@ func NewRouter() *mux.Router {
 	r.HandleFunc("/api/switch/add", mdl.ApiAuth(mdl.Perm(api.CreateSwitch, database.PermissionModifyRooms))).Methods("POST")
 	r.HandleFunc("/api/switch/modify", mdl.ApiAuth(mdl.Perm(api.ModifySwitch, database.PermissionModifyRooms))).Methods("PUT")
 	r.HandleFunc("/api/switch/delete", mdl.ApiAuth(mdl.Perm(api.DeleteSwitch, database.PermissionModifyRooms))).Methods("DELETE")
+	r.HandleFunc("/api/switch/network/list", mdl.ApiAuth(mdl.Perm(api.ListNetworkDevices, database.PermissionModifyRooms))).Methods("GET")
+	r.HandleFunc("/api/switch/network/set", mdl.ApiAuth(mdl.Perm(api.SetNetworkDevice, database.PermissionModifyRooms))).Methods("PUT")
+	r.HandleFunc("/api/switch/network/delete", mdl.ApiAuth(mdl.Perm(api.DeleteNetworkDevice, database.PermissionModifyRooms))).Methods("DELETE")
 
 	// Cameras
 	r.HandleFunc("/api/camera/list/all", mdl.ApiAuth(mdl.Perm(api.GetAllCameras, database.PermissionModifyRooms))).Methods("GET")
@@ -78,6 +94,17 @@ func NewRouter() *mux.Router {
 	r.HandleFunc("/api/camera/modify", mdl.ApiAuth(mdl.Perm(api.ModifyCamera, database.PermissionModifyRooms))).Methods("PUT")
 	r.HandleFunc("/api/camera/delete", mdl.ApiAuth(mdl.Perm(api.DeleteCamera, database.PermissionModifyRooms))).Methods("DELETE")
 
+	// radiGo
+	r.HandleFunc("/api/radigo/list/all", mdl.ApiAuth(mdl.Perm(api.ListAllRadiGoServers, database.PermissionModifyRooms))).Methods("GET")
+	r.HandleFunc("/api/radigo/list/personal", mdl.ApiAuth(api.ListPersonalRadiGoServers)).Methods("GET")
+	r.HandleFunc("/api/radigo/add", mdl.ApiAuth(mdl.Perm(api.AddRadiGoServer, database.PermissionModifyRooms))).Methods("POST")
+	r.HandleFunc("/api/radigo/delete", mdl.ApiAuth(mdl.Perm(api.DeleteRadiGoServer, database.PermissionModifyRooms))).Methods("DELETE")
+	r.HandleFunc("/api/radigo/play", mdl.ApiAuth(api.PlayRadiGoMode)).Methods("POST")
+	r.HandleFunc("/api/radigo/status", mdl.ApiAuth(api.GetRadiGoStatus)).Methods("GET")
+
+	// Weather
+	r.HandleFunc("/api/weather", mdl.ApiAuth(api.GetWeather)).Methods("GET")
+
 	// Logs for the admin user
 	r.HandleFunc("/api/logs/delete/old", mdl.ApiAuth(mdl.Perm(api.FlushOldLogs, database.PermissionLogs))).Methods("DELETE")
 	r.HandleFunc("/api/logs/delete/all", mdl.ApiAuth(mdl.Perm(api.FlushAllLogs, database.PermissionLogs))).Methods("DELETE")
@@ -101,6 +128,8 @@ func NewRouter() *mux.Router {
 	r.HandleFunc("/api/user/permissions/switch/add", mdl.ApiAuth(mdl.Perm(api.AddSwitchPermission, database.PermissionManageUsers))).Methods("POST")
 	r.HandleFunc("/api/user/permissions/switch/delete", mdl.ApiAuth(mdl.Perm(api.RemoveSwitchPermission, database.PermissionManageUsers))).Methods("DELETE")
 	r.HandleFunc("/api/user/permissions/switch/list/user/{username}", mdl.ApiAuth(mdl.Perm(api.GetForeignUserSwitchPermissions, database.PermissionManageUsers))).Methods("GET")
+	r.HandleFunc("/api/user/permissions/radigo/add", mdl.ApiAuth(mdl.Perm(api.AddRadiGoPermission, database.PermissionManageUsers))).Methods("POST")
+	r.HandleFunc("/api/user/permissions/radigo/delete", mdl.ApiAuth(mdl.Perm(api.RemoveRadiGoPermission, database.PermissionManageUsers))).Methods("DELETE")
 
 	// Creating and removing users
 	r.HandleFunc("/api/user/manage/list", mdl.ApiAuth(mdl.Perm(api.ListUsers, database.PermissionManageUsers))).Methods("GET")
@@ -109,6 +138,13 @@ func NewRouter() *mux.Router {
 	r.HandleFunc("/api/user/manage/delete", mdl.ApiAuth(mdl.Perm(api.DeleteUser, database.PermissionManageUsers))).Methods("DELETE")
 	r.HandleFunc("/api/user/manage/data/modify", mdl.ApiAuth(mdl.Perm(api.ModifyUserMetadata, database.PermissionManageUsers))).Methods("PUT")
 
+	// User groups
+	r.HandleFunc("/api/user/group/list", mdl.ApiAuth(api.ListUserGroups)).Methods("GET")
+	r.HandleFunc("/api/user/group/add", mdl.ApiAuth(mdl.Perm(api.AddUserGroup, database.PermissionManageUsers))).Methods("POST")
+	r.HandleFunc("/api/user/group/delete", mdl.ApiAuth(mdl.Perm(api.DeleteUserGroup, database.PermissionManageUsers))).Methods("DELETE")
+	r.HandleFunc("/api/user/group/member/add", mdl.ApiAuth(mdl.Perm(api.AddUserGroupMember, database.PermissionManageUsers))).Methods("POST")
+	r.HandleFunc("/api/user/group/member/delete", mdl.ApiAuth(mdl.Perm(api.RemoveUserGroupMember, database.PermissionManageUsers))).Methods("DELETE")
+
 	// Manage personal data
 	r.HandleFunc("/api/user/data", mdl.ApiAuth(api.GetUserDetails)).Methods("GET")
 	r.HandleFunc("/api/user/data/update", mdl.ApiAuth(api.ModifyCurrentUserMetadata)).Methods("PUT")
@@ -124,13 +160,46 @@ func NewRouter() *mux.Router {
 	r.HandleFunc("/api/homescript/modify", mdl.ApiAuth(mdl.Perm(api.ModifyHomescript, database.PermissionHomescript))).Methods("PUT")
 	r.HandleFunc("/api/homescript/delete", mdl.ApiAuth(mdl.Perm(api.DeleteHomescriptById, database.PermissionHomescript))).Methods("DELETE")
 	r.HandleFunc("/api/homescript/run/live", mdl.ApiAuth(mdl.Perm(api.RunHomescriptString, database.PermissionHomescript))).Methods("POST")
+	r.HandleFunc("/api/homescript/run", mdl.ApiAuth(mdl.Perm(api.RunHomescriptById, database.PermissionHomescript))).Methods("POST")
+	r.HandleFunc("/api/homescript/quickaction/list/personal", mdl.ApiAuth(mdl.Perm(api.ListPersonalQuickActions, database.PermissionHomescript))).Methods("GET")
+	r.HandleFunc("/api/homescript/quickaction/run", mdl.ApiAuth(mdl.Perm(api.RunQuickAction, database.PermissionHomescript))).Methods("POST")
+	r.HandleFunc("/api/homescript/run/dry", mdl.ApiAuth(mdl.Perm(api.DryRunHomescript, database.PermissionHomescript))).Methods("POST")
+	r.HandleFunc("/api/homescript/run/live/stream", mdl.ApiAuth(mdl.Perm(api.RunHomescriptStringStream, database.PermissionHomescript))).Methods("POST")
 	r.HandleFunc("/api/homescript/list/personal", mdl.ApiAuth(api.ListPersonalHomescripts)).Methods("GET")
+	r.HandleFunc("/api/homescript/revision/list", mdl.ApiAuth(mdl.Perm(api.ListHomescriptRevisions, database.PermissionHomescript))).Methods("GET")
+	r.HandleFunc("/api/homescript/revision/diff", mdl.ApiAuth(mdl.Perm(api.DiffHomescriptRevisions, database.PermissionHomescript))).Methods("POST")
+	r.HandleFunc("/api/homescript/revision/restore", mdl.ApiAuth(mdl.Perm(api.RestoreHomescriptRevision, database.PermissionHomescript))).Methods("POST")
+	r.HandleFunc("/api/homescript/share/list", mdl.ApiAuth(mdl.Perm(api.ListHomescriptShares, database.PermissionHomescript))).Methods("GET")
+	r.HandleFunc("/api/homescript/share/add", mdl.ApiAuth(mdl.Perm(api.AddHomescriptShare, database.PermissionHomescript))).Methods("POST")
+	r.HandleFunc("/api/homescript/share/delete", mdl.ApiAuth(mdl.Perm(api.DeleteHomescriptShare, database.PermissionHomescript))).Methods("DELETE")
+	r.HandleFunc("/api/homescript/storage/list/personal", mdl.ApiAuth(mdl.Perm(api.ListPersonalStorageEntries, database.PermissionHomescript))).Methods("GET")
+	r.HandleFunc("/api/homescript/storage/list/global", mdl.ApiAuth(mdl.Perm(api.ListGlobalStorageEntries, database.PermissionHomescript))).Methods("GET")
+	r.HandleFunc("/api/homescript/storage/set", mdl.ApiAuth(mdl.Perm(api.SetStorageEntry, database.PermissionHomescript))).Methods("PUT")
+	r.HandleFunc("/api/homescript/storage/delete", mdl.ApiAuth(mdl.Perm(api.DeleteStorageEntry, database.PermissionHomescript))).Methods("DELETE")
+	r.HandleFunc("/api/homescript/http/host/list", mdl.ApiAuth(mdl.Perm(api.ListHomescriptHttpHosts, database.PermissionHomescript))).Methods("GET")
+	r.HandleFunc("/api/homescript/http/host/add", mdl.ApiAuth(mdl.Perm(api.AddHomescriptHttpHost, database.PermissionModifyServerConfig))).Methods("POST")
+	r.HandleFunc("/api/homescript/http/host/delete", mdl.ApiAuth(mdl.Perm(api.DeleteHomescriptHttpHost, database.PermissionModifyServerConfig))).Methods("DELETE")
+	r.HandleFunc("/api/homescript/run/history/personal", mdl.ApiAuth(mdl.Perm(api.ListPersonalHomescriptRuns, database.PermissionHomescript))).Methods("GET")
+	r.HandleFunc("/api/homescript/run/history/all", mdl.ApiAuth(mdl.Perm(api.ListAllHomescriptRuns, database.PermissionManageHomescripts))).Methods("GET")
+	r.HandleFunc("/api/homescript/job/list/personal", mdl.ApiAuth(mdl.Perm(api.ListPersonalHomescriptJobs, database.PermissionHomescript))).Methods("GET")
+	r.HandleFunc("/api/homescript/job/list/all", mdl.ApiAuth(mdl.Perm(api.ListAllHomescriptJobs, database.PermissionManageHomescripts))).Methods("GET")
+	r.HandleFunc("/api/homescript/job/kill", mdl.ApiAuth(api.KillHomescriptJob)).Methods("DELETE")
+
+	// Webhook-related
+	r.HandleFunc("/api/webhook/list/personal", mdl.ApiAuth(mdl.Perm(api.ListPersonalWebhooks, database.PermissionHomescript))).Methods("GET")
+	r.HandleFunc("/api/webhook/add", mdl.ApiAuth(mdl.Perm(api.AddWebhook, database.PermissionHomescript))).Methods("POST")
+	r.HandleFunc("/api/webhook/modify", mdl.ApiAuth(mdl.Perm(api.ModifyWebhook, database.PermissionHomescript))).Methods("PUT")
+	r.HandleFunc("/api/webhook/delete", mdl.ApiAuth(mdl.Perm(api.DeleteWebhook, database.PermissionHomescript))).Methods("DELETE")
+	r.HandleFunc("/api/webhook/trigger", api.TriggerWebhook).Methods("GET", "POST")
+	r.HandleFunc("/api/webhook/trigger/{token}", api.TriggerWebhook).Methods("GET", "POST")
 
 	// Automations-related
 	r.HandleFunc("/api/automation/list/personal", mdl.ApiAuth(mdl.Perm(api.GetUserAutomations, database.PermissionAutomation))).Methods("GET")
 	r.HandleFunc("/api/automation/add", mdl.ApiAuth(mdl.Perm(api.CreateNewAutomation, database.PermissionAutomation))).Methods("POST")
 	r.HandleFunc("/api/automation/delete", mdl.ApiAuth(mdl.Perm(api.RemoveAutomation, database.PermissionAutomation))).Methods("DELETE")
 	r.HandleFunc("/api/automation/modify", mdl.ApiAuth(mdl.Perm(api.ModifyAutomation, database.PermissionAutomation))).Methods("PUT")
+	r.HandleFunc("/api/automation/run", mdl.ApiAuth(mdl.Perm(api.RunAutomation, database.PermissionAutomation))).Methods("POST")
+	r.HandleFunc("/api/automation/run/history", mdl.ApiAuth(mdl.Perm(api.ListAutomationRuns, database.PermissionAutomation))).Methods("GET")
 	r.HandleFunc("/api/automation/state/global", mdl.ApiAuth(mdl.Perm(api.ChangeActivationAutomation, database.PermissionModifyServerConfig))).Methods("PUT")
 
 	// Schedule-related
@@ -143,6 +212,7 @@ func NewRouter() *mux.Router {
 
 	// Admin-specific
 	r.HandleFunc("/api/config/location/modify", mdl.ApiAuth(mdl.Perm(api.UpdateLocation, database.PermissionModifyServerConfig))).Methods("PUT")
+	r.HandleFunc("/api/config/homescript/timeout", mdl.ApiAuth(mdl.Perm(api.UpdateHomescriptTimeout, database.PermissionModifyServerConfig))).Methods("PUT")
 
 	// Customization
 	r.HandleFunc("/api/user/settings/theme/personal", mdl.ApiAuth(api.SetCurrentUserColorTheme)).Methods("PUT")
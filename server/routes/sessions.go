@@ -43,6 +43,7 @@ func loginPostHandler(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNoContent)
 		log.Debug(fmt.Sprintf("User %s logged in successfully", loginRequest.Username))
 		go event.Info("Successful login", fmt.Sprintf("%s logged in", loginRequest.Username))
+		event.Publish(event.UserLogin{Username: loginRequest.Username})
 		return
 	}
 	log.Debug("Login failed: invalid credentials")
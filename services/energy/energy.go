@@ -0,0 +1,255 @@
+package energy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+	"github.com/MikMuellerDev/smarthome/core/hardware"
+	"github.com/MikMuellerDev/smarthome/core/user"
+)
+
+var log *logrus.Logger
+
+func InitLogger(logger *logrus.Logger) {
+	log = logger
+}
+
+// The percentages of a budget at which its owner is notified
+var alertThresholds = []uint{80, 100}
+
+// Describes how much of a budget has been used in the current period
+type BudgetStatus struct {
+	Budget        database.EnergyBudget `json:"budget"`
+	UsedWattHours float64               `json:"usedWattHours"`
+	Percentage    float64               `json:"percentage"`
+	Exceeded      bool                  `json:"exceeded"`
+	PeriodStart   time.Time             `json:"periodStart"`
+}
+
+// Returns the start of the period which contains the given time
+func periodStart(period database.EnergyBudgetPeriod, now time.Time) time.Time {
+	if period == database.EnergyBudgetMonthly {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// Returns whether a budget limits the given switch, either directly or through its room
+func budgetAffectsSwitch(budget database.EnergyBudget, switchItem database.Switch) bool {
+	if budget.TargetType == database.EnergyBudgetRoom {
+		return switchItem.RoomId == budget.TargetId
+	}
+	return switchItem.Id == budget.TargetId
+}
+
+// Returns the highest alert threshold which has been reached by the given percentage
+// Returns 0 if no threshold has been reached yet
+func reachedThreshold(percentage float64) uint {
+	var reached uint = 0
+	for _, threshold := range alertThresholds {
+		if percentage >= float64(threshold) {
+			reached = threshold
+		}
+	}
+	return reached
+}
+
+// Calculates the usage of a budget in its current period
+func GetBudgetStatus(budget database.EnergyBudget, now time.Time) (BudgetStatus, error) {
+	start := periodStart(budget.Period, now)
+	var used float64
+	var err error
+	if budget.TargetType == database.EnergyBudgetRoom {
+		used, err = database.GetRoomEnergyUsage(budget.TargetId, start)
+	} else {
+		used, err = database.GetSwitchEnergyUsage(budget.TargetId, start)
+	}
+	if err != nil {
+		return BudgetStatus{}, err
+	}
+	percentage := 0.0
+	if budget.LimitWattHours > 0 {
+		percentage = used / float64(budget.LimitWattHours) * 100
+	}
+	return BudgetStatus{
+		Budget:        budget,
+		UsedWattHours: used,
+		Percentage:    percentage,
+		Exceeded:      percentage >= 100,
+		PeriodStart:   start,
+	}, nil
+}
+
+// Returns the status of every budget
+func GetBudgetStatuses() ([]BudgetStatus, error) {
+	budgets, err := database.GetEnergyBudgets()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	statuses := make([]BudgetStatus, 0)
+	for _, budget := range budgets {
+		status, err := GetBudgetStatus(budget, now)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Returns the status of the budgets which affect switches the given user has access to
+func GetUserBudgetStatuses(username string) ([]BudgetStatus, error) {
+	statuses, err := GetBudgetStatuses()
+	if err != nil {
+		return nil, err
+	}
+	switches, err := database.ListUserSwitches(username)
+	if err != nil {
+		return nil, err
+	}
+	userStatuses := make([]BudgetStatus, 0)
+	for _, status := range statuses {
+		for _, switchItem := range switches {
+			if budgetAffectsSwitch(status.Budget, switchItem) {
+				userStatuses = append(userStatuses, status)
+				break
+			}
+		}
+	}
+	return userStatuses, nil
+}
+
+// Adds the energy every active switch has used during the elapsed time
+func recordUsage(now time.Time, elapsed time.Duration) error {
+	switches, err := database.ListSwitches()
+	if err != nil {
+		return err
+	}
+	for _, switchItem := range switches {
+		if !switchItem.PowerOn || switchItem.Watts == 0 {
+			continue
+		}
+		if err := database.AddSwitchEnergyUsage(switchItem.Id, now, float64(switchItem.Watts)*elapsed.Hours()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Checks every budget, notifies owners about reached thresholds and cuts power if required
+func evaluateBudgets(now time.Time) error {
+	budgets, err := database.GetEnergyBudgets()
+	if err != nil {
+		return err
+	}
+	for _, budget := range budgets {
+		status, err := GetBudgetStatus(budget, now)
+		if err != nil {
+			return err
+		}
+		alertLevel := budget.AlertLevel
+		// A new period has started, the owner should be notified again
+		if !budget.AlertPeriodStart.Equal(status.PeriodStart) {
+			alertLevel = 0
+		}
+		reached := reachedThreshold(status.Percentage)
+		if reached > alertLevel {
+			if err := notifyThreshold(status, reached); err != nil {
+				return err
+			}
+			alertLevel = reached
+		}
+		if alertLevel != budget.AlertLevel || !budget.AlertPeriodStart.Equal(status.PeriodStart) {
+			if err := database.SetEnergyBudgetAlert(budget.Id, alertLevel, status.PeriodStart); err != nil {
+				return err
+			}
+		}
+		if status.Exceeded && budget.CutPower {
+			if err := cutPower(budget); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Informs the owner of a budget that a threshold has been reached
+func notifyThreshold(status BudgetStatus, threshold uint) error {
+	level := user.NotificationLevelWarn
+	title := "Energy Budget Almost Used"
+	if threshold >= 100 {
+		level = user.NotificationLevelError
+		title = "Energy Budget Exceeded"
+	}
+	description := fmt.Sprintf("The %s energy budget of %s '%s' is %.0f%% used (%.0f of %d Wh)",
+		status.Budget.Period,
+		status.Budget.TargetType,
+		status.Budget.TargetId,
+		status.Percentage,
+		status.UsedWattHours,
+		status.Budget.LimitWattHours,
+	)
+	if threshold >= 100 && status.Budget.CutPower {
+		description += ". Its switches will be turned off until the budget is reset"
+	}
+	return user.Notify(status.Budget.Owner, title, description, level)
+}
+
+// Turns off every active switch affected by an exceeded budget
+// The `KeepOn` flag of switches is ignored because a budget acts as a hard cap
+func cutPower(budget database.EnergyBudget) error {
+	switches, err := database.ListSwitches()
+	if err != nil {
+		return err
+	}
+	for _, switchItem := range switches {
+		if !switchItem.PowerOn || !budgetAffectsSwitch(budget, switchItem) {
+			continue
+		}
+		err := hardware.SetPower(switchItem.Id, false)
+		if errors.Is(err, hardware.ErrShutdownNotSupported) {
+			// Network devices without a shutdown url cannot be turned off, turning them on is rejected instead
+			continue
+		}
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to cut power of switch '%s' after its energy budget was exceeded: %s", switchItem.Id, err.Error()))
+			continue
+		}
+		go event.Info("Energy Budget Cut Power", fmt.Sprintf("Switch '%s' was turned off because energy budget '%d' is exceeded", switchItem.Id, budget.Id))
+	}
+	return nil
+}
+
+// Rejects turning on a switch which is affected by an exceeded budget that cuts power
+// Is registered as a power-on check of the hardware package, so that the budget acts as a cap instead of only cutting power afterwards
+func checkPowerOn(switchId string) error {
+	switchItem, found, err := database.GetSwitchById(switchId)
+	if err != nil || !found {
+		// The existence of the switch is validated by the caller
+		return err
+	}
+	budgets, err := database.GetEnergyBudgets()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, budget := range budgets {
+		if !budget.CutPower || !budgetAffectsSwitch(budget, switchItem) {
+			continue
+		}
+		status, err := GetBudgetStatus(budget, now)
+		if err != nil {
+			return err
+		}
+		if status.Exceeded {
+			return fmt.Errorf("%w: the %s energy budget of %s '%s' is exceeded", hardware.ErrPowerOnRejected, budget.Period, budget.TargetType, budget.TargetId)
+		}
+	}
+	return nil
+}
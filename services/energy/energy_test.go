@@ -0,0 +1,242 @@
+package energy
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+	"github.com/MikMuellerDev/smarthome/core/user"
+)
+
+// Is set once the database has been initialized for the tests which require it
+var databaseInitialized struct {
+	once sync.Once
+	err  error
+}
+
+// Initializes the test database on first use
+// Tests which require the database are skipped if it is unavailable, so that the other tests of this package run without it
+func requireDatabase(t *testing.T) {
+	databaseInitialized.once.Do(func() {
+		log := logrus.New()
+		log.Level = logrus.FatalLevel
+		InitLogger(log)
+		event.InitLogger(log)
+		user.InitLogger(log)
+		databaseInitialized.err = initDB(true)
+	})
+	if databaseInitialized.err != nil {
+		t.Skipf("Database is unavailable: %s", databaseInitialized.err.Error())
+	}
+}
+
+func initDB(args ...bool) error {
+	log := logrus.New()
+	log.Level = logrus.FatalLevel
+	database.InitLogger(log)
+	if err := database.Init(database.DatabaseConfig{
+		Username: "smarthome",
+		Password: "testing",
+		Hostname: "localhost",
+		Database: "smarthome",
+		Port:     3330,
+	}, "admin",
+	); err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		if err := database.DeleteTables(); err != nil {
+			return err
+		}
+		time.Sleep(time.Second)
+		return initDB()
+	}
+	return nil
+}
+
+func TestPeriodStart(t *testing.T) {
+	now := time.Date(2022, time.May, 17, 13, 45, 10, 0, time.Local)
+	table := []struct {
+		Period   database.EnergyBudgetPeriod
+		Expected time.Time
+	}{
+		{Period: database.EnergyBudgetDaily, Expected: time.Date(2022, time.May, 17, 0, 0, 0, 0, time.Local)},
+		{Period: database.EnergyBudgetMonthly, Expected: time.Date(2022, time.May, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, test := range table {
+		if start := periodStart(test.Period, now); !start.Equal(test.Expected) {
+			t.Errorf("Unexpected start of %s period. Want: %v Got: %v", test.Period, test.Expected, start)
+		}
+	}
+}
+
+func TestReachedThreshold(t *testing.T) {
+	table := []struct {
+		Percentage float64
+		Expected   uint
+	}{
+		{Percentage: 0, Expected: 0},
+		{Percentage: 79.9, Expected: 0},
+		{Percentage: 80, Expected: 80},
+		{Percentage: 99, Expected: 80},
+		{Percentage: 100, Expected: 100},
+		{Percentage: 250, Expected: 100},
+	}
+	for _, test := range table {
+		if reached := reachedThreshold(test.Percentage); reached != test.Expected {
+			t.Errorf("Unexpected threshold for %.1f%%. Want: %d Got: %d", test.Percentage, test.Expected, reached)
+		}
+	}
+}
+
+func TestBudgetAffectsSwitch(t *testing.T) {
+	switchItem := database.Switch{Id: "s1", RoomId: "kitchen"}
+	table := []struct {
+		Budget   database.EnergyBudget
+		Expected bool
+	}{
+		{Budget: database.EnergyBudget{TargetType: database.EnergyBudgetSwitch, TargetId: "s1"}, Expected: true},
+		{Budget: database.EnergyBudget{TargetType: database.EnergyBudgetSwitch, TargetId: "s2"}, Expected: false},
+		{Budget: database.EnergyBudget{TargetType: database.EnergyBudgetRoom, TargetId: "kitchen"}, Expected: true},
+		// A room budget must not match a switch whose id equals the room id
+		{Budget: database.EnergyBudget{TargetType: database.EnergyBudgetRoom, TargetId: "s1"}, Expected: false},
+	}
+	for _, test := range table {
+		if affected := budgetAffectsSwitch(test.Budget, switchItem); affected != test.Expected {
+			t.Errorf("Unexpected result for budget of %s '%s'. Want: %t Got: %t", test.Budget.TargetType, test.Budget.TargetId, test.Expected, affected)
+		}
+	}
+}
+
+// Creates the owner of the budget, an active and an inactive switch
+func createBudgetData() error {
+	if err := database.AddUser(database.FullUser{Username: "energy_owner", Password: "test"}); err != nil {
+		return err
+	}
+	if err := database.CreateRoom(database.RoomData{Id: "energy_room"}); err != nil {
+		return err
+	}
+	for _, switchId := range []string{"energy_on", "energy_off"} {
+		if err := database.CreateSwitch(database.Switch{Id: switchId, RoomId: "energy_room", Watts: 1000}); err != nil {
+			return err
+		}
+	}
+	if _, err := database.SetPowerState("energy_on", true); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Checks the usage of a switch in the current day
+func checkUsage(t *testing.T, switchId string, now time.Time, want float64) {
+	used, err := database.GetSwitchEnergyUsage(switchId, periodStart(database.EnergyBudgetDaily, now))
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	// Allows for floating point errors of the summed usage
+	if used < want-0.1 || used > want+0.1 {
+		t.Errorf("Unexpected usage of switch '%s'. Want: %.2f Wh Got: %.2f Wh", switchId, want, used)
+	}
+}
+
+// Checks the alert level of the budget and the notifications its owner has received
+func checkAlert(t *testing.T, budgetId uint, now time.Time, wantLevel uint, wantNotifications int, wantPriority uint8) {
+	budget, found, err := database.GetEnergyBudgetById(budgetId)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if !found {
+		t.Errorf("Budget %d was not found", budgetId)
+		return
+	}
+	if budget.AlertLevel != wantLevel {
+		t.Errorf("Unexpected alert level. Want: %d Got: %d", wantLevel, budget.AlertLevel)
+	}
+	if start := periodStart(budget.Period, now); !budget.AlertPeriodStart.Equal(start) {
+		t.Errorf("Unexpected alert period start. Want: %v Got: %v", start, budget.AlertPeriodStart)
+	}
+	notifications, err := database.GetUserNotifications("energy_owner")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(notifications) != wantNotifications {
+		t.Errorf("Unexpected number of notifications. Want: %d Got: %d", wantNotifications, len(notifications))
+		return
+	}
+	latest := notifications[0]
+	for _, notification := range notifications {
+		if notification.Id > latest.Id {
+			latest = notification
+		}
+	}
+	if latest.Priority != wantPriority {
+		t.Errorf("Unexpected priority of the latest notification. Want: %d Got: %d", wantPriority, latest.Priority)
+	}
+}
+
+func TestRecordUsageAndEvaluateBudgets(t *testing.T) {
+	requireDatabase(t)
+	if err := createBudgetData(); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	now := time.Now()
+	budgetId, err := database.CreateEnergyBudget(database.EnergyBudget{
+		Owner:            "energy_owner",
+		TargetType:       database.EnergyBudgetSwitch,
+		TargetId:         "energy_on",
+		Period:           database.EnergyBudgetDaily,
+		LimitWattHours:   100,
+		AlertPeriodStart: periodStart(database.EnergyBudgetDaily, now),
+	})
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	// 1000 W for five minutes use 83 Wh, which reaches the 80% threshold
+	if err := recordUsage(now, 5*time.Minute); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	checkUsage(t, "energy_on", now, 1000.0/12)
+	checkUsage(t, "energy_off", now, 0)
+	if err := evaluateBudgets(now); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	checkAlert(t, budgetId, now, 80, 1, uint8(user.NotificationLevelWarn))
+	// The owner is only notified once per threshold
+	if err := evaluateBudgets(now); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	checkAlert(t, budgetId, now, 80, 1, uint8(user.NotificationLevelWarn))
+	// Two further minutes exceed the budget
+	if err := recordUsage(now, 2*time.Minute); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	checkUsage(t, "energy_on", now, 1000.0*7/60)
+	if err := evaluateBudgets(now); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	checkAlert(t, budgetId, now, 100, 2, uint8(user.NotificationLevelError))
+	// An alert level of a previous period is reset, so that the owner is notified again
+	if err := database.SetEnergyBudgetAlert(budgetId, 100, periodStart(database.EnergyBudgetDaily, now).AddDate(0, 0, -1)); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if err := evaluateBudgets(now); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	checkAlert(t, budgetId, now, 100, 3, uint8(user.NotificationLevelError))
+}
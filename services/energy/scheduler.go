@@ -0,0 +1,48 @@
+package energy
+
+import (
+	"time"
+
+	"github.com/go-co-op/gocron"
+
+	"github.com/MikMuellerDev/smarthome/core/hardware"
+)
+
+// Time between two measurements of the energy usage
+const measurementInterval = time.Minute
+
+var scheduler *gocron.Scheduler
+
+// The time of the last measurement, the usage in between is attributed to the currently active switches
+var lastMeasurement time.Time
+
+func energyRunner() {
+	now := time.Now()
+	elapsed := now.Sub(lastMeasurement)
+	lastMeasurement = now
+	// Gaps which are significantly longer than the interval (for example after a suspend) only count as a single interval
+	// The power states in between are unknown, so attributing the whole gap to the currently active switches would overestimate their usage
+	if elapsed > 5*measurementInterval {
+		elapsed = measurementInterval
+	}
+	if err := recordUsage(now, elapsed); err != nil {
+		log.Error("Failed to record energy usage: ", err.Error())
+		return
+	}
+	if err := evaluateBudgets(now); err != nil {
+		log.Error("Failed to evaluate energy budgets: ", err.Error())
+	}
+}
+
+func InitSchedule() error {
+	lastMeasurement = time.Now()
+	hardware.RegisterPowerOnCheck(checkPowerOn)
+	scheduler = gocron.NewScheduler(time.Local)
+	runner := scheduler.Every(measurementInterval)
+	if _, err := runner.Do(energyRunner); err != nil {
+		log.Error("Failed to setup energy runner: ", err.Error())
+		return err
+	}
+	scheduler.StartAsync()
+	return nil
+}
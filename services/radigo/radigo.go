@@ -0,0 +1,151 @@
+package radigo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
+)
+
+var log *logrus.Logger
+
+func InitLogger(logger *logrus.Logger) {
+	log = logger
+}
+
+// radiGo servers are expected to answer within this duration
+const requestTimeout = 5 * time.Second
+
+type ModeRequest struct {
+	Mode string `json:"mode"`
+}
+
+// The current state of a radiGo server
+type Status struct {
+	Mode    string `json:"mode"` // Is empty if nothing is playing
+	Playing bool   `json:"playing"`
+}
+
+// Is returned instead of transport errors because they contain the url of the server
+var ErrServerUnreachable = errors.New("radiGo server could not be reached")
+
+// Sends a request to an endpoint of a radiGo server
+// The token is sent in the `Authorization` header so that it is not part of the url
+func sendRequest(server database.RadiGoServer, method string, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, server.Url+path, body)
+	if err != nil {
+		log.Error(fmt.Sprintf("Request to radiGo server '%s' could not be created: %s", server.Id, err.Error()))
+		return nil, ErrServerUnreachable
+	}
+	req.Header.Set("Authorization", "Bearer "+server.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := http.Client{Timeout: requestTimeout}
+	res, err := client.Do(req)
+	if err != nil {
+		log.Error(fmt.Sprintf("Request to radiGo server '%s' failed: %s", server.Id, err.Error()))
+		return nil, ErrServerUnreachable
+	}
+	return res, nil
+}
+
+// Converts a non-200 response of a radiGo server into an error
+func responseError(res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	if len(body) == 0 {
+		return fmt.Errorf("radiGo server responded with status %d", res.StatusCode)
+	}
+	return fmt.Errorf("radiGo server responded with status %d: %s", res.StatusCode, string(body))
+}
+
+// Requests a radiGo server to play a mode
+func sendModeRequest(server database.RadiGoServer, mode string) error {
+	requestBody, err := json.Marshal(ModeRequest{Mode: mode})
+	if err != nil {
+		return err
+	}
+	res, err := sendRequest(server, http.MethodPost, "/api/mode", bytes.NewBuffer(requestBody))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return responseError(res)
+	}
+	return nil
+}
+
+// Reads the current status of a radiGo server
+func fetchStatus(server database.RadiGoServer) (Status, error) {
+	res, err := sendRequest(server, http.MethodGet, "/api/status", nil)
+	if err != nil {
+		return Status{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Status{}, responseError(res)
+	}
+	var status Status
+	if err := json.NewDecoder(res.Body).Decode(&status); err != nil {
+		return Status{}, fmt.Errorf("radiGo server sent an invalid status: %s", err.Error())
+	}
+	return status, nil
+}
+
+// Returns the requested server if it exists and the user is allowed to use it
+func GetUserServer(username string, serverId string) (database.RadiGoServer, error) {
+	server, found, err := database.GetRadiGoServerById(serverId)
+	if err != nil {
+		return database.RadiGoServer{}, fmt.Errorf("database failure: %s", err.Error())
+	}
+	if !found {
+		return database.RadiGoServer{}, fmt.Errorf("radiGo server '%s' does not exist", serverId)
+	}
+	hasPermission, err := database.UserHasRadiGoPermission(username, serverId)
+	if err != nil {
+		return database.RadiGoServer{}, fmt.Errorf("could not validate your permissions: %s", err.Error())
+	}
+	if !hasPermission {
+		return database.RadiGoServer{}, fmt.Errorf("you lack the permission to use radiGo server '%s'", serverId)
+	}
+	return server, nil
+}
+
+// Starts a mode on a radiGo server if the user is allowed to use the server
+func Play(username string, serverId string, mode string) error {
+	if mode == "" {
+		return fmt.Errorf("Failed to play: the mode must not be empty")
+	}
+	server, err := GetUserServer(username, serverId)
+	if err != nil {
+		return fmt.Errorf("Failed to play: %s", err.Error())
+	}
+	if err := sendModeRequest(server, mode); err != nil {
+		return fmt.Errorf("Failed to play mode '%s' on '%s': %s", mode, server.Name, err.Error())
+	}
+	log.Debug(fmt.Sprintf("User '%s' started mode '%s' on radiGo server '%s'", username, mode, server.Id))
+	go event.Info("radiGo Mode Started", fmt.Sprintf("User '%s' started mode '%s' on radiGo server '%s'", username, mode, server.Name))
+	return nil
+}
+
+// Returns the status of a radiGo server if the user is allowed to use the server
+func GetStatus(username string, serverId string) (Status, error) {
+	server, err := GetUserServer(username, serverId)
+	if err != nil {
+		return Status{}, fmt.Errorf("Failed to get status: %s", err.Error())
+	}
+	status, err := fetchStatus(server)
+	if err != nil {
+		return Status{}, fmt.Errorf("Failed to get status of '%s': %s", server.Name, err.Error())
+	}
+	return status, nil
+}
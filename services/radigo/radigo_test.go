@@ -0,0 +1,85 @@
+package radigo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+func TestMain(m *testing.M) {
+	log := logrus.New()
+	log.Level = logrus.FatalLevel
+	InitLogger(log)
+	os.Exit(m.Run())
+}
+
+// Emulates the API of a radiGo server which only accepts the token `secret`
+func newFakeServer(status *Status) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.URL.RawQuery != "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("invalid token"))
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/mode" && r.Method == http.MethodPost:
+			var request ModeRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Mode == "unknown" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			status.Mode = request.Mode
+			status.Playing = true
+		case r.URL.Path == "/api/status" && r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(status)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestClient(t *testing.T) {
+	var state Status
+	fake := newFakeServer(&state)
+	defer fake.Close()
+	server := database.RadiGoServer{Id: "test", Url: fake.URL, Token: "secret"}
+
+	if err := sendModeRequest(server, "jazz"); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	status, err := fetchStatus(server)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if status.Mode != "jazz" || !status.Playing {
+		t.Errorf("Unexpected status: want: jazz (playing) got: %v", status)
+		return
+	}
+	if err := sendModeRequest(server, "unknown"); err == nil {
+		t.Error("Request with an unknown mode succeeded")
+		return
+	}
+	server.Token = "invalid"
+	if err := sendModeRequest(server, "jazz"); err == nil || err.Error() != "radiGo server responded with status 401: invalid token" {
+		t.Errorf("Unexpected error for invalid token: %v", err)
+		return
+	}
+	if _, err := fetchStatus(server); err == nil {
+		t.Error("Status request with an invalid token succeeded")
+		return
+	}
+	// Transport errors must not expose the url or the token of the server
+	fake.Close()
+	if err := sendModeRequest(server, "jazz"); err != ErrServerUnreachable {
+		t.Errorf("Unexpected error for an unreachable server: want: %v got: %v", ErrServerUnreachable, err)
+		return
+	}
+}
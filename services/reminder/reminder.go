@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/MikMuellerDev/smarthome/core/database"
+	"github.com/MikMuellerDev/smarthome/core/event"
 	"github.com/MikMuellerDev/smarthome/core/user"
 )
 
@@ -80,6 +81,8 @@ func SendUrgencyNotifications(username string) error {
 			if err := database.SetReminderUserWasNotified(reminder.Id, true, time.Now().Local()); err != nil {
 				return err
 			}
+			// Like the notification, the event is published at most once a day
+			event.Publish(event.ReminderOverdue{ReminderId: reminder.Id, Owner: username})
 			continue // Continue to the next reminder
 		}
 
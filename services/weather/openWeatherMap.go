@@ -0,0 +1,126 @@
+package weather
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Is used if no url is configured
+const DefaultOpenWeatherMapUrl = "https://api.openweathermap.org"
+
+// The amount of 3-hour forecast intervals which are included in `Weather`
+const forecastIntervals = 4
+
+// Fetches weather data from OpenWeatherMap or from a service with a compatible API
+type OpenWeatherMap struct {
+	url    string
+	apiKey string
+	client http.Client
+}
+
+func NewOpenWeatherMap(baseUrl string, apiKey string) *OpenWeatherMap {
+	if baseUrl == "" {
+		baseUrl = DefaultOpenWeatherMapUrl
+	}
+	return &OpenWeatherMap{
+		url:    baseUrl,
+		apiKey: apiKey,
+		client: http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type owmCondition struct {
+	Main        string `json:"main"`
+	Description string `json:"description"`
+}
+
+type owmMain struct {
+	Temp      float64 `json:"temp"`
+	FeelsLike float64 `json:"feels_like"`
+	Humidity  uint    `json:"humidity"`
+}
+
+type owmCurrentResponse struct {
+	Weather []owmCondition `json:"weather"`
+	Main    owmMain        `json:"main"`
+	Wind    struct {
+		Speed float64 `json:"speed"`
+	} `json:"wind"`
+	Rain struct {
+		OneHour float64 `json:"1h"`
+	} `json:"rain"`
+}
+
+type owmForecastResponse struct {
+	List []struct {
+		Pop  float64 `json:"pop"`
+		Rain struct {
+			ThreeHours float64 `json:"3h"`
+		} `json:"rain"`
+	} `json:"list"`
+}
+
+// Sends a request to an endpoint of the API and decodes the JSON response
+func (self *OpenWeatherMap) get(path string, latitude float32, longitude float32, target interface{}) error {
+	query := url.Values{}
+	query.Set("lat", fmt.Sprintf("%f", latitude))
+	query.Set("lon", fmt.Sprintf("%f", longitude))
+	query.Set("units", "metric")
+	query.Set("appid", self.apiKey)
+	res, err := self.client.Get(fmt.Sprintf("%s%s?%s", self.url, path, query.Encode()))
+	if err != nil {
+		// The url of transport errors contains the API key, so only the underlying cause is returned
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("weather provider could not be reached: %s", urlErr.Err.Error())
+		}
+		return errors.New("weather provider could not be reached")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("weather provider responded with status %d: %s", res.StatusCode, string(body))
+	}
+	if err := json.NewDecoder(res.Body).Decode(target); err != nil {
+		return fmt.Errorf("weather provider sent an invalid response: %s", err.Error())
+	}
+	return nil
+}
+
+// Fetches the current weather and the forecast of the next hours
+func (self *OpenWeatherMap) Fetch(latitude float32, longitude float32) (Weather, error) {
+	var current owmCurrentResponse
+	if err := self.get("/data/2.5/weather", latitude, longitude, &current); err != nil {
+		return Weather{}, err
+	}
+	var forecast owmForecastResponse
+	if err := self.get("/data/2.5/forecast", latitude, longitude, &forecast); err != nil {
+		return Weather{}, err
+	}
+	weather := Weather{
+		Temperature: current.Main.Temp,
+		FeelsLike:   current.Main.FeelsLike,
+		Humidity:    current.Main.Humidity,
+		WindSpeed:   current.Wind.Speed,
+		Rain:        current.Rain.OneHour,
+	}
+	if len(current.Weather) > 0 {
+		weather.Condition = current.Weather[0].Main
+		weather.Description = current.Weather[0].Description
+	}
+	for index, interval := range forecast.List {
+		if index >= forecastIntervals {
+			break
+		}
+		weather.ForecastHours += 3
+		weather.ForecastRain += interval.Rain.ThreeHours
+		weather.ForecastRainProbability = math.Max(weather.ForecastRainProbability, interval.Pop)
+	}
+	return weather, nil
+}
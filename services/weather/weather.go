@@ -0,0 +1,120 @@
+package weather
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/MikMuellerDev/smarthome/core/database"
+)
+
+var log *logrus.Logger
+
+func InitLogger(logger *logrus.Logger) {
+	log = logger
+}
+
+const (
+	// Weather data which is younger than this duration is served from the cache
+	cacheDuration = 10 * time.Minute
+	// The provider is contacted at most once during this duration, stale data is served in between
+	minRequestInterval = time.Minute
+)
+
+var (
+	ErrNotConfigured = errors.New("no weather provider has been configured")
+	ErrRateLimited   = errors.New("weather provider has been contacted too recently, try again later")
+)
+
+// Describes the current weather and a short-term forecast at the location of the server
+type Weather struct {
+	Condition   string  `json:"condition"`   // Group of the weather, for example `Rain`, `Clear` or `Clouds`
+	Description string  `json:"description"` // Human-readable description, for example `light rain`
+	Temperature float64 `json:"temperature"` // In degrees Celsius
+	FeelsLike   float64 `json:"feelsLike"`   // In degrees Celsius
+	Humidity    uint    `json:"humidity"`    // In percent
+	WindSpeed   float64 `json:"windSpeed"`   // In meters per second
+	Rain        float64 `json:"rain"`        // Rain of the last hour in millimeters
+	// Short-term forecast
+	ForecastHours           uint      `json:"forecastHours"`           // The time span which is covered by the forecast values
+	ForecastRain            float64   `json:"forecastRain"`            // Expected rain in millimeters
+	ForecastRainProbability float64   `json:"forecastRainProbability"` // Highest probability of precipitation between 0 and 1
+	FetchedAt               time.Time `json:"fetchedAt"`
+}
+
+// Is implemented by every weather service which can be used by the server
+type Provider interface {
+	Fetch(latitude float32, longitude float32) (Weather, error)
+}
+
+// Contains the configured provider and the last response
+var state = struct {
+	m           sync.Mutex
+	provider    Provider
+	cached      Weather
+	hasCache    bool
+	latitude    float32 // The location of the cached data
+	longitude   float32
+	lastRequest time.Time
+}{}
+
+// Sets the provider which is used to fetch weather data and clears the cache
+// A nil provider disables the weather service
+func Init(provider Provider) {
+	state.m.Lock()
+	defer state.m.Unlock()
+	state.provider = provider
+	state.hasCache = false
+	state.lastRequest = time.Time{}
+}
+
+// Returns the weather at the given location
+// Serves cached data if it is recent enough or if the rate limit forbids contacting the provider
+func getWeather(latitude float32, longitude float32, now time.Time) (Weather, error) {
+	state.m.Lock()
+	defer state.m.Unlock()
+	if state.provider == nil {
+		return Weather{}, ErrNotConfigured
+	}
+	cacheValid := state.hasCache && state.latitude == latitude && state.longitude == longitude
+	if cacheValid && now.Sub(state.cached.FetchedAt) < cacheDuration {
+		return state.cached, nil
+	}
+	if !state.lastRequest.IsZero() && now.Sub(state.lastRequest) < minRequestInterval {
+		if cacheValid {
+			return state.cached, nil
+		}
+		return Weather{}, ErrRateLimited
+	}
+	state.lastRequest = now
+	weather, err := state.provider.Fetch(latitude, longitude)
+	if err != nil {
+		log.Error("Failed to fetch weather: ", err.Error())
+		if cacheValid {
+			log.Warn("Serving outdated weather data because the provider failed")
+			return state.cached, nil
+		}
+		return Weather{}, err
+	}
+	weather.FetchedAt = now
+	state.cached = weather
+	state.hasCache = true
+	state.latitude = latitude
+	state.longitude = longitude
+	return weather, nil
+}
+
+// Returns the weather at the location of the server which is stored in the server configuration
+func GetWeather() (Weather, error) {
+	config, found, err := database.GetServerConfiguration()
+	if err != nil {
+		return Weather{}, fmt.Errorf("could not retrieve the location of the server: %s", err.Error())
+	}
+	if !found {
+		return Weather{}, errors.New("could not retrieve the location of the server: no configuration found")
+	}
+	return getWeather(config.Latitude, config.Longitude, time.Now())
+}
@@ -0,0 +1,135 @@
+package weather
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestMain(m *testing.M) {
+	log := logrus.New()
+	log.Level = logrus.FatalLevel
+	InitLogger(log)
+	os.Exit(m.Run())
+}
+
+// Counts how often it has been asked for data
+type stubProvider struct {
+	calls int
+	fail  bool
+}
+
+func (self *stubProvider) Fetch(latitude float32, longitude float32) (Weather, error) {
+	self.calls++
+	if self.fail {
+		return Weather{}, errors.New("provider failed")
+	}
+	return Weather{Description: fmt.Sprintf("call %d", self.calls), Temperature: float64(latitude)}, nil
+}
+
+func TestCacheAndRateLimit(t *testing.T) {
+	provider := &stubProvider{}
+	Init(provider)
+	defer Init(nil)
+	start := time.Date(2022, time.May, 17, 13, 0, 0, 0, time.Local)
+
+	table := []struct {
+		Latitude    float32
+		Offset      time.Duration
+		Fail        bool
+		Description string
+		Error       error
+		Calls       int
+	}{
+		{Latitude: 1, Offset: 0, Description: "call 1", Calls: 1},
+		// Recent data is served from the cache
+		{Latitude: 1, Offset: 5 * time.Minute, Description: "call 1", Calls: 1},
+		// Outdated data is fetched again
+		{Latitude: 1, Offset: 11 * time.Minute, Description: "call 2", Calls: 2},
+		// A new location cannot be fetched within the rate limit
+		{Latitude: 2, Offset: 11*time.Minute + 30*time.Second, Error: ErrRateLimited, Calls: 2},
+		{Latitude: 2, Offset: 12 * time.Minute, Description: "call 3", Calls: 3},
+		// Outdated data is served if the provider fails
+		{Latitude: 2, Offset: 30 * time.Minute, Fail: true, Description: "call 3", Calls: 4},
+	}
+	for index, test := range table {
+		provider.fail = test.Fail
+		weather, err := getWeather(test.Latitude, 0, start.Add(test.Offset))
+		if err != test.Error {
+			t.Errorf("Unexpected error in step %d: want: %v got: %v", index, test.Error, err)
+			return
+		}
+		if weather.Description != test.Description || provider.calls != test.Calls {
+			t.Errorf("Unexpected result in step %d: want: '%s' after %d calls got: '%s' after %d calls", index, test.Description, test.Calls, weather.Description, provider.calls)
+			return
+		}
+	}
+}
+
+func TestNotConfigured(t *testing.T) {
+	Init(nil)
+	if _, err := getWeather(0, 0, time.Now()); err != ErrNotConfigured {
+		t.Errorf("Unexpected error: want: %v got: %v", ErrNotConfigured, err)
+	}
+}
+
+func TestOpenWeatherMap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("appid") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/data/2.5/weather":
+			w.Write([]byte(`{"weather":[{"main":"Rain","description":"light rain"}],"main":{"temp":1.6,"feels_like":-2.1,"humidity":93},"wind":{"speed":7.5},"rain":{"1h":0.4}}`))
+		case "/data/2.5/forecast":
+			w.Write([]byte(`{"list":[{"pop":0.2},{"pop":0.8,"rain":{"3h":1.5}},{"pop":0.1,"rain":{"3h":0.5}},{"pop":0},{"pop":1,"rain":{"3h":9}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	weather, err := NewOpenWeatherMap(server.URL, "key").Fetch(52.5, 13.4)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	expected := Weather{
+		Condition:               "Rain",
+		Description:             "light rain",
+		Temperature:             1.6,
+		FeelsLike:               -2.1,
+		Humidity:                93,
+		WindSpeed:               7.5,
+		Rain:                    0.4,
+		ForecastHours:           12,
+		ForecastRain:            2,
+		ForecastRainProbability: 0.8,
+	}
+	if weather != expected {
+		t.Errorf("Unexpected weather: want: %v got: %v", expected, weather)
+		return
+	}
+	if _, err := NewOpenWeatherMap(server.URL, "invalid").Fetch(52.5, 13.4); err == nil {
+		t.Error("Request with an invalid API key succeeded")
+		return
+	}
+	// Transport errors must not expose the API key
+	server.Close()
+	_, err = NewOpenWeatherMap(server.URL, "secret_key").Fetch(52.5, 13.4)
+	if err == nil {
+		t.Error("Request to a closed server succeeded")
+		return
+	}
+	if strings.Contains(err.Error(), "secret_key") {
+		t.Errorf("Error exposes the API key: %s", err.Error())
+	}
+}